@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"bookapi/internal/platform/isbn"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -53,12 +55,17 @@ func main() {
 
 		searchVector := fmt.Sprintf("'%s %s %s'", title, subtitle, desc)
 
+		bookISBN, err := isbn.Complete(fmt.Sprintf("979%09d", i+1))
+		if err != nil {
+			log.Fatalf("Failed to generate ISBN: %v", err)
+		}
+
 		if i > 0 {
 			sb.WriteString(", ")
 		}
 		sb.WriteString(fmt.Sprintf(
-			"(gen_random_uuid(), '978-%08d', '%s', '%s', '%s', '%s', '%s', '%d-01-01', %d, %d, '%s', NULL, to_tsvector('english', %s), '%s', '%s')",
-			i+1, title, subtitle, genre, pub, desc, year, year, pages, lang, searchVector, now.Format(time.RFC3339), now.Format(time.RFC3339),
+			"(gen_random_uuid(), '%s', '%s', '%s', '%s', '%s', '%s', '%d-01-01', %d, %d, '%s', NULL, to_tsvector('english', %s), '%s', '%s')",
+			bookISBN, title, subtitle, genre, pub, desc, year, year, pages, lang, searchVector, now.Format(time.RFC3339), now.Format(time.RFC3339),
 		))
 
 		if (i+1)%1000 == 0 {
@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- Word dictionary for "did you mean" suggestions.
+-- Built with the 'simple' config so entries are unstemmed words that can be
+-- offered back to the user verbatim. Refreshed after each ingestion run.
+CREATE MATERIALIZED VIEW IF NOT EXISTS book_lexicon AS
+SELECT word, ndoc
+FROM ts_stat($$
+  SELECT to_tsvector('simple',
+    coalesce(title, '') || ' ' || coalesce(subtitle, '') || ' ' || coalesce(publisher, ''))
+  FROM books
+$$)
+WHERE length(word) > 1;
+
+-- Unique index is required for REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX IF NOT EXISTS book_lexicon_word_idx ON book_lexicon(word);
+CREATE INDEX IF NOT EXISTS book_lexicon_word_trgm_idx ON book_lexicon USING GIN(word gin_trgm_ops);
+
+-- +goose Down
+
+DROP MATERIALIZED VIEW IF EXISTS book_lexicon;
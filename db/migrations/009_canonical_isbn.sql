@@ -0,0 +1,157 @@
+-- +goose Up
+
+-- Canonical ISBN handling: books are keyed by the 13-digit ISBN without
+-- separators. The original spelling and the ISBN-10 form are kept in
+-- isbn_alternates so references in either form can still be resolved.
+
+-- isbn13 returns the canonical ISBN-13 for an ISBN-10 or ISBN-13 in any
+-- spelling, or NULL if the input has the wrong length or check digit.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION isbn13(raw TEXT) RETURNS TEXT AS $$
+DECLARE
+  n TEXT := upper(regexp_replace(coalesce(raw, ''), '[- ]', '', 'g'));
+  body TEXT;
+  total INT := 0;
+  c INT;
+BEGIN
+  IF n ~ '^[0-9]{9}[0-9X]$' THEN
+    FOR i IN 1..9 LOOP
+      total := total + substr(n, i, 1)::INT * (11 - i);
+    END LOOP;
+    c := (11 - total % 11) % 11;
+    IF substr(n, 10, 1) <> CASE WHEN c = 10 THEN 'X' ELSE c::TEXT END THEN
+      RETURN NULL;
+    END IF;
+    body := '978' || substr(n, 1, 9);
+  ELSIF n ~ '^[0-9]{13}$' THEN
+    body := substr(n, 1, 12);
+  ELSE
+    RETURN NULL;
+  END IF;
+
+  total := 0;
+  FOR i IN 1..12 LOOP
+    total := total + substr(body, i, 1)::INT * CASE WHEN i % 2 = 0 THEN 3 ELSE 1 END;
+  END LOOP;
+  c := (10 - total % 10) % 10;
+
+  IF length(n) = 13 AND substr(n, 13, 1) <> c::TEXT THEN
+    RETURN NULL;
+  END IF;
+  RETURN body || c::TEXT;
+END
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+-- isbn10 returns the ISBN-10 form of a canonical 978-prefixed ISBN-13, or NULL.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION isbn10(canonical TEXT) RETURNS TEXT AS $$
+DECLARE
+  body TEXT;
+  total INT := 0;
+  c INT;
+BEGIN
+  IF canonical IS NULL OR canonical !~ '^978[0-9]{10}$' THEN
+    RETURN NULL;
+  END IF;
+  body := substr(canonical, 4, 9);
+  FOR i IN 1..9 LOOP
+    total := total + substr(body, i, 1)::INT * (11 - i);
+  END LOOP;
+  c := (11 - total % 11) % 11;
+  RETURN body || CASE WHEN c = 10 THEN 'X' ELSE c::TEXT END;
+END
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS isbn_alternates TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS books_isbn_alternates_idx ON books USING GIN(isbn_alternates);
+
+-- Keep isbn_alternates in sync: remember the previous spelling when isbn
+-- changes and always include the ISBN-10 form.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION books_isbn_alternates_trigger() RETURNS trigger AS $$
+DECLARE
+  alts TEXT[] := coalesce(new.isbn_alternates, '{}');
+BEGIN
+  IF TG_OP = 'UPDATE' AND old.isbn IS DISTINCT FROM new.isbn THEN
+    alts := alts || old.isbn;
+  END IF;
+  IF isbn10(new.isbn) IS NOT NULL THEN
+    alts := alts || isbn10(new.isbn);
+  END IF;
+  new.isbn_alternates := ARRAY(
+    SELECT DISTINCT a FROM unnest(alts) a WHERE a IS DISTINCT FROM new.isbn ORDER BY a
+  );
+  RETURN new;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS isbn_alternates_update ON books;
+CREATE TRIGGER isbn_alternates_update BEFORE INSERT OR UPDATE OF isbn, isbn_alternates
+ON books FOR EACH ROW EXECUTE FUNCTION books_isbn_alternates_trigger();
+
+-- Normalize existing rows. When several rows share a canonical ISBN only one
+-- is rewritten (preferring a row already in canonical form, then the oldest);
+-- the others keep their current value and must be merged by hand.
+WITH ranked AS (
+  SELECT id, isbn13(isbn) AS canonical,
+         row_number() OVER (
+           PARTITION BY isbn13(isbn)
+           ORDER BY (isbn = isbn13(isbn)) DESC, created_at, id
+         ) AS rn
+  FROM books
+  WHERE isbn13(isbn) IS NOT NULL
+)
+UPDATE books b
+SET isbn = r.canonical
+FROM ranked r
+WHERE b.id = r.id AND r.rn = 1 AND b.isbn <> r.canonical;
+
+-- Fill in ISBN-10 forms for rows that were already canonical.
+UPDATE books SET isbn_alternates = isbn_alternates WHERE isbn10(isbn) IS NOT NULL;
+
+-- catalog_books is keyed the same way; let run links follow the rewrite.
+ALTER TABLE ingest_run_books DROP CONSTRAINT IF EXISTS ingest_run_books_isbn13_fkey;
+ALTER TABLE ingest_run_books ADD CONSTRAINT ingest_run_books_isbn13_fkey
+  FOREIGN KEY (isbn13) REFERENCES catalog_books(isbn13) ON DELETE CASCADE ON UPDATE CASCADE;
+
+WITH ranked AS (
+  SELECT isbn13 AS current, isbn13(isbn13) AS canonical,
+         row_number() OVER (
+           PARTITION BY isbn13(isbn13)
+           ORDER BY (isbn13 = isbn13(isbn13)) DESC, updated_at DESC
+         ) AS rn
+  FROM catalog_books
+  WHERE isbn13(isbn13) IS NOT NULL
+)
+UPDATE catalog_books c
+SET isbn13 = r.canonical
+FROM ranked r
+WHERE c.isbn13 = r.current AND r.rn = 1 AND c.isbn13 <> r.canonical;
+
+UPDATE catalog_sources s
+SET entity_key = isbn13(s.entity_key)
+WHERE s.entity_type = 'BOOK'
+  AND isbn13(s.entity_key) IS NOT NULL
+  AND s.entity_key <> isbn13(s.entity_key)
+  AND NOT EXISTS (
+    SELECT 1 FROM catalog_sources o
+    WHERE o.entity_type = 'BOOK' AND o.provider = s.provider AND o.entity_key = isbn13(s.entity_key)
+  );
+
+-- +goose Down
+
+-- Rewritten ISBNs are not reverted; the previous spelling remains in
+-- isbn_alternates until the column is dropped.
+ALTER TABLE ingest_run_books DROP CONSTRAINT IF EXISTS ingest_run_books_isbn13_fkey;
+ALTER TABLE ingest_run_books ADD CONSTRAINT ingest_run_books_isbn13_fkey
+  FOREIGN KEY (isbn13) REFERENCES catalog_books(isbn13) ON DELETE CASCADE;
+
+DROP TRIGGER IF EXISTS isbn_alternates_update ON books;
+DROP FUNCTION IF EXISTS books_isbn_alternates_trigger();
+DROP INDEX IF EXISTS books_isbn_alternates_idx;
+ALTER TABLE books DROP COLUMN IF EXISTS isbn_alternates;
+DROP FUNCTION IF EXISTS isbn10(TEXT);
+DROP FUNCTION IF EXISTS isbn13(TEXT);
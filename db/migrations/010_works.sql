@@ -0,0 +1,20 @@
+-- +goose Up
+
+-- Works group the editions (books rows) of the same title, so ratings and
+-- reading-list entries can be rolled up across ISBNs.
+CREATE TABLE IF NOT EXISTS works (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ol_key VARCHAR(50) UNIQUE, -- Open Library work key, e.g. /works/OL45804W
+    title TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS work_id UUID REFERENCES works(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS books_work_id_idx ON books(work_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS books_work_id_idx;
+ALTER TABLE books DROP COLUMN IF EXISTS work_id;
+DROP TABLE IF EXISTS works;
@@ -0,0 +1,27 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS series_name_lower_idx ON series (lower(name));
+
+-- Position is fractional so novellas can sit between numbered entries (e.g. 2.5).
+-- It is NULL when the source names the series without a number.
+CREATE TABLE IF NOT EXISTS book_series (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    series_id UUID NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+    position NUMERIC(6,2),
+    PRIMARY KEY (book_id, series_id)
+);
+
+CREATE INDEX IF NOT EXISTS book_series_series_position_idx ON book_series(series_id, position);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS book_series;
+DROP TABLE IF EXISTS series;
@@ -0,0 +1,93 @@
+-- +goose Up
+
+-- Link books to the people who wrote, translated or edited them. Authors are
+-- keyed by their Open Library key rather than referencing catalog_authors:
+-- ingest links a book before (and sometimes without) hydrating its authors,
+-- so the credited name is stored alongside the key.
+CREATE TABLE IF NOT EXISTS book_authors (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    author_key VARCHAR(50) NOT NULL,
+    name TEXT NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'author',
+    position INT NOT NULL,
+    PRIMARY KEY (book_id, author_key, role),
+    CONSTRAINT book_authors_role_valid CHECK (role IN ('author', 'translator', 'editor'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_authors_author_key ON book_authors(author_key);
+
+-- Author names are searchable alongside the title.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION books_search_trigger() RETURNS trigger AS $$
+BEGIN
+  new.search_vector :=
+    setweight(to_tsvector('english', coalesce(new.title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(
+      (SELECT string_agg(name, ' ' ORDER BY position) FROM book_authors WHERE book_id = new.id), '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(new.genre, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(new.publisher, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(new.description, '')), 'D');
+  RETURN new;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Touching the book re-runs books_search_trigger whenever its authors change.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION book_authors_search_trigger() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    UPDATE books SET search_vector = NULL WHERE id = old.book_id;
+  END IF;
+  IF TG_OP IN ('INSERT', 'UPDATE') THEN
+    UPDATE books SET search_vector = NULL WHERE id = new.book_id;
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS book_authors_search_update ON book_authors;
+CREATE TRIGGER book_authors_search_update AFTER INSERT OR UPDATE OR DELETE
+ON book_authors FOR EACH ROW EXECUTE FUNCTION book_authors_search_trigger();
+
+-- Backfill from the raw Open Library payloads kept for already ingested books.
+INSERT INTO book_authors (book_id, author_key, name, role, position)
+SELECT b.id, a.key, a.name, 'author', MIN(a.position)
+FROM catalog_sources cs
+JOIN books b ON b.isbn = cs.entity_key
+CROSS JOIN LATERAL (
+    SELECT substring(e.value->>'url' FROM '/authors/([^/]+)') AS key,
+           e.value->>'name' AS name,
+           e.ordinality AS position
+    FROM jsonb_array_elements(
+        CASE WHEN jsonb_typeof(cs.raw_json->'authors') = 'array' THEN cs.raw_json->'authors' ELSE '[]' END
+    ) WITH ORDINALITY e(value, ordinality)
+) a
+WHERE cs.entity_type = 'BOOK'
+  AND a.key IS NOT NULL
+  AND coalesce(a.name, '') <> ''
+GROUP BY b.id, a.key, a.name
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS book_authors_search_update ON book_authors;
+DROP FUNCTION IF EXISTS book_authors_search_trigger();
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION books_search_trigger() RETURNS trigger AS $$
+BEGIN
+  new.search_vector :=
+    setweight(to_tsvector('english', coalesce(new.title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(new.genre, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(new.publisher, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(new.description, '')), 'D');
+  RETURN new;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TABLE IF EXISTS book_authors;
+
+UPDATE books SET search_vector = NULL;
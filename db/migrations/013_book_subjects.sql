@@ -0,0 +1,116 @@
+-- +goose Up
+
+-- Curated genres. Open Library subjects are free text; a subject belongs to
+-- the genre listing it (lower-cased, underscores as spaces) among its aliases.
+CREATE TABLE IF NOT EXISTS genres (
+    slug VARCHAR(50) PRIMARY KEY,
+    name TEXT NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}'
+);
+
+INSERT INTO genres (slug, name, aliases) VALUES
+    ('fiction', 'Fiction', '{fiction,novels,general fiction,literary fiction,literature}'),
+    ('science-fiction', 'Science Fiction', '{science fiction,sci-fi,space opera,dystopias,cyberpunk}'),
+    ('fantasy', 'Fantasy', '{fantasy,fantasy fiction,epic fantasy,magic,dragons}'),
+    ('mystery', 'Mystery', '{mystery,mystery fiction,detective and mystery stories,detective fiction,crime fiction}'),
+    ('thriller', 'Thriller', '{thriller,thrillers,suspense,suspense fiction,espionage}'),
+    ('romance', 'Romance', '{romance,romance fiction,love stories}'),
+    ('horror', 'Horror', '{horror,horror fiction,horror tales,ghost stories}'),
+    ('historical-fiction', 'Historical Fiction', '{historical fiction}'),
+    ('young-adult', 'Young Adult', '{young adult,young adult fiction}'),
+    ('children', 'Children', '{children,juvenile fiction,juvenile literature,children''s fiction,children''s stories,picture books}'),
+    ('comics', 'Comics', '{comics,comic books,graphic novels}'),
+    ('poetry', 'Poetry', '{poetry,poems}'),
+    ('drama', 'Drama', '{drama,plays}'),
+    ('biography', 'Biography', '{biography,biographies,autobiography,memoir,memoirs}'),
+    ('history', 'History', '{history,world history,military history}'),
+    ('science', 'Science', '{science,physics,chemistry,biology,astronomy,mathematics}'),
+    ('philosophy', 'Philosophy', '{philosophy,ethics}'),
+    ('religion', 'Religion', '{religion,christianity,spirituality,theology}'),
+    ('psychology', 'Psychology', '{psychology}'),
+    ('business', 'Business', '{business,economics,management,finance}'),
+    ('self-help', 'Self-Help', '{self-help,personal development,self-improvement}'),
+    ('art', 'Art', '{art,music,photography,design}'),
+    ('travel', 'Travel', '{travel,description and travel}'),
+    ('cooking', 'Cooking', '{cooking,cookbooks,cookery}')
+ON CONFLICT (slug) DO NOTHING;
+
+-- genre_for_subject maps a subject to its genre. Compound subjects such as
+-- "Fiction, science fiction, general" are matched part by part and the
+-- longest (most specific) matching part wins.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION genre_for_subject(subject TEXT) RETURNS VARCHAR AS $$
+  SELECT g.slug
+  FROM genres g
+  CROSS JOIN LATERAL unnest(string_to_array(lower(replace(subject, '_', ' ')), ',')) AS part
+  WHERE btrim(part) = ANY(g.aliases)
+  ORDER BY length(btrim(part)) DESC, g.slug
+  LIMIT 1
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+CREATE TABLE IF NOT EXISTS subjects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    genre_slug VARCHAR(50) REFERENCES genres(slug) ON UPDATE CASCADE ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subjects_name ON subjects ((lower(name)));
+CREATE INDEX IF NOT EXISTS idx_subjects_genre_slug ON subjects(genre_slug);
+
+CREATE TABLE IF NOT EXISTS book_subjects (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    subject_id UUID NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    PRIMARY KEY (book_id, subject_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_subjects_subject_id ON book_subjects(subject_id);
+
+-- Backfill: the subject a book was ingested under (its old genre) comes
+-- first, followed by the subjects in its raw Open Library payload.
+CREATE TEMP TABLE backfill_subjects ON COMMIT DROP AS
+SELECT b.id AS book_id, b.genre AS name, 0::bigint AS position
+FROM books b
+WHERE b.genre NOT IN ('', 'Unknown')
+UNION ALL
+SELECT b.id, e.value->>'name', e.ordinality
+FROM catalog_sources cs
+JOIN books b ON b.isbn = cs.entity_key
+CROSS JOIN LATERAL jsonb_array_elements(
+    CASE WHEN jsonb_typeof(cs.raw_json->'subjects') = 'array' THEN cs.raw_json->'subjects' ELSE '[]' END
+) WITH ORDINALITY e(value, ordinality)
+WHERE cs.entity_type = 'BOOK'
+  AND coalesce(e.value->>'name', '') <> '';
+
+INSERT INTO subjects (name, genre_slug)
+SELECT DISTINCT ON (lower(name)) name, genre_for_subject(name)
+FROM backfill_subjects
+ORDER BY lower(name), name
+ON CONFLICT DO NOTHING;
+
+INSERT INTO book_subjects (book_id, subject_id, position)
+SELECT bf.book_id, s.id, MIN(bf.position)
+FROM backfill_subjects bf
+JOIN subjects s ON lower(s.name) = lower(bf.name)
+GROUP BY bf.book_id, s.id
+ON CONFLICT DO NOTHING;
+
+-- A book's display genre is the genre of its first mapped subject.
+UPDATE books b SET genre = g.name
+FROM (
+    SELECT DISTINCT ON (bs.book_id) bs.book_id, gn.name
+    FROM book_subjects bs
+    JOIN subjects s ON s.id = bs.subject_id
+    JOIN genres gn ON gn.slug = s.genre_slug
+    ORDER BY bs.book_id, bs.position
+) g
+WHERE b.id = g.book_id AND b.genre <> g.name;
+
+-- +goose Down
+
+DROP TABLE IF EXISTS book_subjects;
+DROP TABLE IF EXISTS subjects;
+DROP FUNCTION IF EXISTS genre_for_subject(TEXT);
+DROP TABLE IF EXISTS genres;
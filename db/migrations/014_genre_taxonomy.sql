@@ -0,0 +1,105 @@
+-- +goose Up
+
+-- Genres form a tree: Fiction > Speculative Fiction > Science Fiction.
+ALTER TABLE genres ADD COLUMN IF NOT EXISTS parent_slug VARCHAR(50)
+    REFERENCES genres(slug) ON UPDATE CASCADE ON DELETE SET NULL;
+ALTER TABLE genres ADD CONSTRAINT genres_parent_not_self CHECK (parent_slug <> slug);
+CREATE INDEX IF NOT EXISTS idx_genres_parent_slug ON genres(parent_slug);
+
+INSERT INTO genres (slug, name) VALUES
+    ('nonfiction', 'Nonfiction'),
+    ('speculative', 'Speculative Fiction')
+ON CONFLICT (slug) DO NOTHING;
+
+UPDATE genres SET parent_slug = 'fiction'
+WHERE slug IN ('speculative', 'mystery', 'thriller', 'romance', 'historical-fiction',
+               'young-adult', 'children', 'comics', 'poetry', 'drama');
+UPDATE genres SET parent_slug = 'speculative'
+WHERE slug IN ('science-fiction', 'fantasy', 'horror');
+UPDATE genres SET parent_slug = 'nonfiction'
+WHERE slug IN ('biography', 'history', 'science', 'philosophy', 'religion', 'psychology',
+               'business', 'self-help', 'art', 'travel', 'cooking');
+
+-- Aliases move to their own table so they can be edited one at a time.
+-- Aliases are lower-case with underscores as spaces, and unique across genres.
+CREATE TABLE IF NOT EXISTS genre_aliases (
+    alias TEXT PRIMARY KEY,
+    genre_slug VARCHAR(50) NOT NULL REFERENCES genres(slug) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_genre_aliases_genre_slug ON genre_aliases(genre_slug);
+
+INSERT INTO genre_aliases (alias, genre_slug)
+SELECT DISTINCT ON (a.alias) a.alias, g.slug
+FROM genres g
+CROSS JOIN LATERAL unnest(g.aliases || lower(g.name)) AS a(alias)
+ORDER BY a.alias, g.slug
+ON CONFLICT (alias) DO NOTHING;
+
+INSERT INTO genre_aliases (alias, genre_slug) VALUES
+    ('non-fiction', 'nonfiction'),
+    ('speculative', 'speculative')
+ON CONFLICT (alias) DO NOTHING;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION genre_for_subject(subject TEXT) RETURNS VARCHAR AS $$
+  SELECT ga.genre_slug
+  FROM unnest(string_to_array(lower(replace(subject, '_', ' ')), ',')) AS part
+  JOIN genre_aliases ga ON ga.alias = btrim(part)
+  ORDER BY length(btrim(part)) DESC, ga.genre_slug
+  LIMIT 1
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+ALTER TABLE genres DROP COLUMN IF EXISTS aliases;
+
+-- genre_closure pairs every genre with itself and each of its descendants.
+CREATE OR REPLACE VIEW genre_closure AS
+WITH RECURSIVE c(ancestor, descendant) AS (
+    SELECT slug, slug FROM genres
+    UNION
+    SELECT c.ancestor, g.slug FROM c JOIN genres g ON g.parent_slug = c.descendant
+)
+SELECT ancestor, descendant FROM c;
+
+-- book_genres lists the genres a book's subjects map to. Books entered by
+-- hand have no subjects, so their free-text genre is matched as an alias too.
+CREATE OR REPLACE VIEW book_genres AS
+SELECT bs.book_id, s.genre_slug
+FROM book_subjects bs
+JOIN subjects s ON s.id = bs.subject_id
+WHERE s.genre_slug IS NOT NULL
+UNION
+SELECT b.id, ga.genre_slug
+FROM books b
+JOIN genre_aliases ga ON ga.alias = lower(replace(b.genre, '_', ' '));
+
+-- +goose Down
+
+DROP VIEW IF EXISTS book_genres;
+DROP VIEW IF EXISTS genre_closure;
+
+ALTER TABLE genres ADD COLUMN IF NOT EXISTS aliases TEXT[] NOT NULL DEFAULT '{}';
+UPDATE genres g SET aliases = a.aliases
+FROM (SELECT genre_slug, array_agg(alias ORDER BY alias) AS aliases FROM genre_aliases GROUP BY genre_slug) a
+WHERE a.genre_slug = g.slug;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION genre_for_subject(subject TEXT) RETURNS VARCHAR AS $$
+  SELECT g.slug
+  FROM genres g
+  CROSS JOIN LATERAL unnest(string_to_array(lower(replace(subject, '_', ' ')), ',')) AS part
+  WHERE btrim(part) = ANY(g.aliases)
+  ORDER BY length(btrim(part)) DESC, g.slug
+  LIMIT 1
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+DROP TABLE IF EXISTS genre_aliases;
+
+UPDATE genres SET parent_slug = NULL;
+DELETE FROM genres WHERE slug IN ('nonfiction', 'speculative');
+ALTER TABLE genres DROP CONSTRAINT IF EXISTS genres_parent_not_self;
+DROP INDEX IF EXISTS idx_genres_parent_slug;
+ALTER TABLE genres DROP COLUMN IF EXISTS parent_slug;
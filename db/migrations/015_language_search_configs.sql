@@ -0,0 +1,160 @@
+-- +goose Up
+
+-- Search vectors are built with the text search config of each row's
+-- language instead of always 'english', and accents are stripped so that
+-- "Les Misérables" matches "les miserables".
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- One config per supported language: the stock config with unaccent applied
+-- before stemming. Languages without a stemmer fall back to simple_unaccent.
+-- The list is mirrored in internal/platform/textsearch/config.go.
+DROP TEXT SEARCH CONFIGURATION IF EXISTS simple_unaccent;
+CREATE TEXT SEARCH CONFIGURATION simple_unaccent (COPY = simple);
+ALTER TEXT SEARCH CONFIGURATION simple_unaccent
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS english_unaccent;
+CREATE TEXT SEARCH CONFIGURATION english_unaccent (COPY = english);
+ALTER TEXT SEARCH CONFIGURATION english_unaccent
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, english_stem;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS french_unaccent;
+CREATE TEXT SEARCH CONFIGURATION french_unaccent (COPY = french);
+ALTER TEXT SEARCH CONFIGURATION french_unaccent
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, french_stem;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS german_unaccent;
+CREATE TEXT SEARCH CONFIGURATION german_unaccent (COPY = german);
+ALTER TEXT SEARCH CONFIGURATION german_unaccent
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, german_stem;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS spanish_unaccent;
+CREATE TEXT SEARCH CONFIGURATION spanish_unaccent (COPY = spanish);
+ALTER TEXT SEARCH CONFIGURATION spanish_unaccent
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, spanish_stem;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS italian_unaccent;
+CREATE TEXT SEARCH CONFIGURATION italian_unaccent (COPY = italian);
+ALTER TEXT SEARCH CONFIGURATION italian_unaccent
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, italian_stem;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS portuguese_unaccent;
+CREATE TEXT SEARCH CONFIGURATION portuguese_unaccent (COPY = portuguese);
+ALTER TEXT SEARCH CONFIGURATION portuguese_unaccent
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, portuguese_stem;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS dutch_unaccent;
+CREATE TEXT SEARCH CONFIGURATION dutch_unaccent (COPY = dutch);
+ALTER TEXT SEARCH CONFIGURATION dutch_unaccent
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, dutch_stem;
+
+-- search_config maps an ISO 639-1 code, a regional tag ("pt-BR") or an Open
+-- Library MARC code ("ger") to its config. Rows without a language are
+-- treated as English, the default of books.language.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION search_config(lang TEXT) RETURNS regconfig AS $$
+  SELECT (CASE lower(split_part(replace(coalesce(nullif(btrim(lang), ''), 'en'), '_', '-'), '-', 1))
+    WHEN 'en' THEN 'english_unaccent'
+    WHEN 'eng' THEN 'english_unaccent'
+    WHEN 'fr' THEN 'french_unaccent'
+    WHEN 'fre' THEN 'french_unaccent'
+    WHEN 'fra' THEN 'french_unaccent'
+    WHEN 'de' THEN 'german_unaccent'
+    WHEN 'ger' THEN 'german_unaccent'
+    WHEN 'deu' THEN 'german_unaccent'
+    WHEN 'es' THEN 'spanish_unaccent'
+    WHEN 'spa' THEN 'spanish_unaccent'
+    WHEN 'it' THEN 'italian_unaccent'
+    WHEN 'ita' THEN 'italian_unaccent'
+    WHEN 'pt' THEN 'portuguese_unaccent'
+    WHEN 'por' THEN 'portuguese_unaccent'
+    WHEN 'nl' THEN 'dutch_unaccent'
+    WHEN 'dut' THEN 'dutch_unaccent'
+    WHEN 'nld' THEN 'dutch_unaccent'
+    ELSE 'simple_unaccent'
+  END)::regconfig
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION books_search_trigger() RETURNS trigger AS $$
+DECLARE
+  cfg regconfig := search_config(new.language);
+BEGIN
+  new.search_vector :=
+    setweight(to_tsvector(cfg, coalesce(new.title, '')), 'A') ||
+    setweight(to_tsvector(cfg, coalesce(
+      (SELECT string_agg(name, ' ' ORDER BY position) FROM book_authors WHERE book_id = new.id), '')), 'A') ||
+    setweight(to_tsvector(cfg, coalesce(new.genre, '')), 'B') ||
+    setweight(to_tsvector(cfg, coalesce(new.publisher, '')), 'C') ||
+    setweight(to_tsvector(cfg, coalesce(new.description, '')), 'D');
+  RETURN new;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION catalog_books_search_trigger() RETURNS trigger AS $$
+DECLARE
+  cfg regconfig := search_config(new.language);
+BEGIN
+  new.search_vector :=
+    setweight(to_tsvector(cfg, coalesce(new.title, '')), 'A') ||
+    setweight(to_tsvector(cfg, coalesce(new.subtitle, '')), 'B') ||
+    setweight(to_tsvector(cfg, coalesce(new.publisher, '')), 'C') ||
+    setweight(to_tsvector(cfg, coalesce(new.description, '')), 'D');
+  RETURN new;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Rebuild existing vectors; the triggers fill them in. Each statement
+-- rewrites every row inside the migration's transaction, holding row locks
+-- on the whole table until it commits: on a large catalog, run this
+-- migration in a maintenance window.
+UPDATE books SET search_vector = NULL;
+UPDATE catalog_books SET search_vector = NULL;
+
+-- +goose Down
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION catalog_books_search_trigger() RETURNS trigger AS $$
+BEGIN
+  new.search_vector :=
+    setweight(to_tsvector('english', coalesce(new.title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(new.subtitle, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(new.publisher, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(new.description, '')), 'D');
+  RETURN new;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION books_search_trigger() RETURNS trigger AS $$
+BEGIN
+  new.search_vector :=
+    setweight(to_tsvector('english', coalesce(new.title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(
+      (SELECT string_agg(name, ' ' ORDER BY position) FROM book_authors WHERE book_id = new.id), '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(new.genre, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(new.publisher, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(new.description, '')), 'D');
+  RETURN new;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+UPDATE books SET search_vector = NULL;
+UPDATE catalog_books SET search_vector = NULL;
+
+DROP FUNCTION IF EXISTS search_config(TEXT);
+DROP TEXT SEARCH CONFIGURATION IF EXISTS dutch_unaccent;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS portuguese_unaccent;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS italian_unaccent;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS spanish_unaccent;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS german_unaccent;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS french_unaccent;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS english_unaccent;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS simple_unaccent;
+DROP EXTENSION IF EXISTS unaccent;
@@ -0,0 +1,81 @@
+-- +goose Up
+
+-- Rating aggregates kept on books so that sorting and filtering by rating no
+-- longer aggregate the ratings table on every request. Only the histogram
+-- (the number of 1..5 star ratings) is maintained; the count and average
+-- are derived from it.
+ALTER TABLE books ADD COLUMN IF NOT EXISTS rating_histogram INT[] NOT NULL DEFAULT '{0,0,0,0,0}';
+ALTER TABLE books ADD CONSTRAINT books_rating_histogram_valid CHECK (
+    array_length(rating_histogram, 1) = 5 AND 0 <= ALL (rating_histogram)
+);
+ALTER TABLE books ADD COLUMN IF NOT EXISTS rating_count INT GENERATED ALWAYS AS (
+    rating_histogram[1] + rating_histogram[2] + rating_histogram[3] + rating_histogram[4] + rating_histogram[5]
+) STORED;
+ALTER TABLE books ADD COLUMN IF NOT EXISTS rating_avg DOUBLE PRECISION GENERATED ALWAYS AS (
+    CASE WHEN rating_histogram[1] + rating_histogram[2] + rating_histogram[3] + rating_histogram[4] + rating_histogram[5] = 0 THEN 0
+    ELSE (rating_histogram[1] + 2 * rating_histogram[2] + 3 * rating_histogram[3] + 4 * rating_histogram[4] + 5 * rating_histogram[5])::float8
+         / (rating_histogram[1] + rating_histogram[2] + rating_histogram[3] + rating_histogram[4] + rating_histogram[5])
+    END
+) STORED;
+
+CREATE INDEX IF NOT EXISTS books_rating_avg_idx ON books (rating_avg, id);
+
+-- The histogram is adjusted in the same transaction as the rating write.
+-- The row lock taken by the UPDATE serializes concurrent ratings of a book.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ratings_aggregate_trigger() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'UPDATE' AND old.book_id = new.book_id AND old.star = new.star THEN
+    RETURN NULL;
+  END IF;
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    UPDATE books SET rating_histogram[old.star] = rating_histogram[old.star] - 1 WHERE id = old.book_id;
+  END IF;
+  IF TG_OP IN ('INSERT', 'UPDATE') THEN
+    UPDATE books SET rating_histogram[new.star] = rating_histogram[new.star] + 1 WHERE id = new.book_id;
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS ratings_aggregate_update ON ratings;
+CREATE TRIGGER ratings_aggregate_update AFTER INSERT OR UPDATE OR DELETE
+ON ratings FOR EACH ROW EXECUTE FUNCTION ratings_aggregate_trigger();
+
+-- A new rating is not an edit of the book.
+DROP TRIGGER IF EXISTS books_set_updated_at ON books;
+CREATE TRIGGER books_set_updated_at
+BEFORE UPDATE ON books
+FOR EACH ROW WHEN (old.rating_histogram IS NOT DISTINCT FROM new.rating_histogram)
+EXECUTE FUNCTION set_updated_at();
+
+UPDATE books b SET rating_histogram = r.histogram
+FROM (
+    SELECT book_id, ARRAY[
+        COUNT(*) FILTER (WHERE star = 1),
+        COUNT(*) FILTER (WHERE star = 2),
+        COUNT(*) FILTER (WHERE star = 3),
+        COUNT(*) FILTER (WHERE star = 4),
+        COUNT(*) FILTER (WHERE star = 5)
+    ]::int[] AS histogram
+    FROM ratings
+    GROUP BY book_id
+) r
+WHERE r.book_id = b.id;
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS books_set_updated_at ON books;
+CREATE TRIGGER books_set_updated_at
+BEFORE UPDATE ON books
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+DROP TRIGGER IF EXISTS ratings_aggregate_update ON ratings;
+DROP FUNCTION IF EXISTS ratings_aggregate_trigger();
+
+DROP INDEX IF EXISTS books_rating_avg_idx;
+ALTER TABLE books DROP COLUMN IF EXISTS rating_avg;
+ALTER TABLE books DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE books DROP CONSTRAINT IF EXISTS books_rating_histogram_valid;
+ALTER TABLE books DROP COLUMN IF EXISTS rating_histogram;
@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- Similar books look up the readers of a book; user_books was only indexed
+-- by user.
+CREATE INDEX IF NOT EXISTS user_books_book_idx ON user_books (book_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS user_books_book_idx;
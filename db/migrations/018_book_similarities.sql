@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- Item-to-item similarities for personalized recommendations, rebuilt by
+-- the recommendations similarity job. Each book keeps its most similar
+-- books by the cosine of their reader vectors over ratings and reading
+-- lists.
+CREATE TABLE IF NOT EXISTS book_similarities (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    similar_book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (book_id, similar_book_id),
+    CONSTRAINT book_similarities_distinct CHECK (book_id <> similar_book_id)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS book_similarities;
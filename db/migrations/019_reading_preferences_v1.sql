@@ -0,0 +1,117 @@
+-- +goose Up
+
+-- reading_preferences was free-form JSON. Rewrite every stored value into
+-- version 1 of the schema in internal/user/preferences.go, keeping what maps
+-- onto it under the keys clients used and dropping everything else. Values
+-- that are not JSON objects are cleared.
+
+-- Lists are ordered by preference, so repeats are dropped keeping the first
+-- occurrence of each value where it was (jsonb_agg(DISTINCT ...) would sort
+-- them instead).
+
+-- reading_preferences_strings turns a JSON array of strings, or a single
+-- comma-separated string, into a JSON array of trimmed, lower-cased,
+-- distinct strings in their original order.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION reading_preferences_strings(v JSONB) RETURNS JSONB AS $$
+  SELECT COALESCE(jsonb_agg(e ORDER BY n), '[]'::jsonb)
+  FROM (
+    SELECT e, MIN(n) AS n
+    FROM (
+      SELECT lower(btrim(a.e)) AS e, a.n
+      FROM jsonb_array_elements_text(CASE WHEN jsonb_typeof(v) = 'array' THEN v ELSE '[]'::jsonb END) WITH ORDINALITY a(e, n)
+      UNION ALL
+      SELECT lower(btrim(s.e)), s.n
+      FROM regexp_split_to_table(CASE WHEN jsonb_typeof(v) = 'string' THEN v #>> '{}' ELSE '' END, ',') WITH ORDINALITY s(e, n)
+    ) x
+    WHERE e <> ''
+    GROUP BY e
+  ) y
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+-- reading_preferences_goal reads a yearly goal given as a number or numeric
+-- string, NULL when missing or outside 1..1000.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION reading_preferences_goal(v JSONB) RETURNS INT AS $$
+  SELECT CASE WHEN n BETWEEN 1 AND 1000 THEN round(n)::int END
+  FROM (
+    SELECT CASE
+      WHEN jsonb_typeof(v) = 'number' THEN (v #>> '{}')::numeric
+      WHEN jsonb_typeof(v) = 'string' AND btrim(v #>> '{}') ~ '^\d{1,4}$' THEN btrim(v #>> '{}')::numeric
+    END AS n
+  ) x
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+UPDATE users u SET reading_preferences = CASE
+  WHEN jsonb_typeof(u.reading_preferences) <> 'object' THEN NULL
+  ELSE jsonb_build_object(
+    'version', 1,
+    -- Genres are kept when they name a genre by slug, name or alias.
+    'favorite_genres', (
+      SELECT COALESCE(jsonb_agg(slug ORDER BY n), '[]'::jsonb)
+      FROM (
+        SELECT g.slug, MIN(e.n) AS n
+        FROM jsonb_array_elements_text(reading_preferences_strings(COALESCE(
+          u.reading_preferences->'favorite_genres', u.reading_preferences->'favourite_genres',
+          u.reading_preferences->'genres'))) WITH ORDINALITY e(value, n)
+        JOIN genres g ON g.slug = replace(e.value, ' ', '-') OR lower(g.name) = e.value
+          OR g.slug IN (SELECT genre_slug FROM genre_aliases WHERE alias = e.value)
+        GROUP BY g.slug
+      ) x
+    ),
+    -- Regional tags ("en-US") are cut down to their language.
+    'languages', (
+      SELECT COALESCE(jsonb_agg(l ORDER BY n), '[]'::jsonb)
+      FROM (
+        SELECT split_part(replace(e.value, '_', '-'), '-', 1) AS l, MIN(e.n) AS n
+        FROM jsonb_array_elements_text(reading_preferences_strings(COALESCE(
+          u.reading_preferences->'languages', u.reading_preferences->'language'))) WITH ORDINALITY e(value, n)
+        GROUP BY 1
+      ) x
+      WHERE l ~ '^[a-z]{2,3}$'
+    ),
+    'preferred_formats', (
+      SELECT COALESCE(jsonb_agg(f ORDER BY n), '[]'::jsonb)
+      FROM (
+        SELECT CASE replace(replace(e.value, '-', ''), ' ', '')
+          WHEN 'hardcover' THEN 'hardcover'
+          WHEN 'hardback' THEN 'hardcover'
+          WHEN 'paperback' THEN 'paperback'
+          WHEN 'softcover' THEN 'paperback'
+          WHEN 'ebook' THEN 'ebook'
+          WHEN 'kindle' THEN 'ebook'
+          WHEN 'audiobook' THEN 'audiobook'
+          WHEN 'audio' THEN 'audiobook'
+        END AS f, MIN(e.n) AS n
+        FROM jsonb_array_elements_text(reading_preferences_strings(COALESCE(
+          u.reading_preferences->'preferred_formats', u.reading_preferences->'formats'))) WITH ORDINALITY e(value, n)
+        GROUP BY 1
+      ) x
+      WHERE f IS NOT NULL
+    ),
+    'avoid_content', (
+      SELECT COALESCE(jsonb_agg(e ORDER BY n), '[]'::jsonb)
+      FROM (
+        SELECT left(e.value, 50) AS e, e.n
+        FROM jsonb_array_elements_text(reading_preferences_strings(COALESCE(
+          u.reading_preferences->'avoid_content', u.reading_preferences->'avoid'))) WITH ORDINALITY e(value, n)
+        ORDER BY e.n
+        LIMIT 20
+      ) x
+    ),
+    'yearly_goal', reading_preferences_goal(COALESCE(
+      u.reading_preferences->'yearly_goal', u.reading_preferences->'reading_goal',
+      u.reading_preferences->'goal'))
+  )
+END
+WHERE u.reading_preferences IS NOT NULL;
+
+DROP FUNCTION reading_preferences_goal(JSONB);
+DROP FUNCTION reading_preferences_strings(JSONB);
+
+-- +goose Down
+
+-- Version 1 values remain valid for the free-form column, and the original
+-- blobs cannot be restored.
@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- Runs started by the in-process scheduler record the schedule and the
+-- slot they fired for. The unique index lets only one replica claim a slot.
+ALTER TABLE ingest_runs
+    ADD COLUMN IF NOT EXISTS schedule TEXT,
+    ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ingest_runs_schedule_slot_idx
+    ON ingest_runs (schedule, scheduled_for)
+    WHERE schedule IS NOT NULL;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ingest_runs_schedule_slot_idx;
+
+ALTER TABLE ingest_runs
+    DROP COLUMN IF EXISTS scheduled_for,
+    DROP COLUMN IF EXISTS schedule;
@@ -0,0 +1,17 @@
+-- +goose Up
+
+-- How far ingestion has read into each subject's search results, so runs
+-- page deeper instead of re-reading the first page.
+CREATE TABLE IF NOT EXISTS ingest_subject_checkpoints (
+    subject TEXT PRIMARY KEY,
+    next_offset INT NOT NULL DEFAULT 0,
+    page INT NOT NULL DEFAULT 0,
+    num_found INT NOT NULL DEFAULT 0,
+    passes INT NOT NULL DEFAULT 0,
+    exhausted_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS ingest_subject_checkpoints;
@@ -0,0 +1,8 @@
+-- +goose Up
+
+-- Typeahead matches author names the same way as titles.
+CREATE INDEX IF NOT EXISTS book_authors_name_trgm_idx ON book_authors USING GIN(name gin_trgm_ops);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS book_authors_name_trgm_idx;
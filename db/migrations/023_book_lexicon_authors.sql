@@ -0,0 +1,34 @@
+-- +goose Up
+
+-- Add author names to the "did you mean" dictionary, so a misspelled author
+-- can be corrected as well as a misspelled title.
+DROP MATERIALIZED VIEW IF EXISTS book_lexicon;
+
+CREATE MATERIALIZED VIEW book_lexicon AS
+SELECT word, ndoc
+FROM ts_stat($$
+  SELECT to_tsvector('simple',
+    coalesce(b.title, '') || ' ' || coalesce(b.subtitle, '') || ' ' || coalesce(b.publisher, '') || ' ' ||
+    coalesce((SELECT string_agg(a.name, ' ') FROM book_authors a WHERE a.book_id = b.id), ''))
+  FROM books b
+$$)
+WHERE length(word) > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS book_lexicon_word_idx ON book_lexicon(word);
+CREATE INDEX IF NOT EXISTS book_lexicon_word_trgm_idx ON book_lexicon USING GIN(word gin_trgm_ops);
+
+-- +goose Down
+
+DROP MATERIALIZED VIEW IF EXISTS book_lexicon;
+
+CREATE MATERIALIZED VIEW book_lexicon AS
+SELECT word, ndoc
+FROM ts_stat($$
+  SELECT to_tsvector('simple',
+    coalesce(title, '') || ' ' || coalesce(subtitle, '') || ' ' || coalesce(publisher, ''))
+  FROM books
+$$)
+WHERE length(word) > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS book_lexicon_word_idx ON book_lexicon(word);
+CREATE INDEX IF NOT EXISTS book_lexicon_word_trgm_idx ON book_lexicon USING GIN(word gin_trgm_ops);
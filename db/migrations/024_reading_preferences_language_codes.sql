@@ -0,0 +1,27 @@
+-- +goose Up
+
+-- 019 kept the MARC codes Open Library uses ("ger", "eng") as they were,
+-- while profile updates store ISO 639-1 codes. Map them the same way as
+-- marcCodes in internal/platform/textsearch/config.go, keeping the order
+-- of preference and dropping codes that become duplicates.
+WITH marc(code, iso) AS (
+  VALUES ('eng', 'en'), ('fre', 'fr'), ('fra', 'fr'), ('ger', 'de'), ('deu', 'de'),
+         ('spa', 'es'), ('ita', 'it'), ('por', 'pt'), ('dut', 'nl'), ('nld', 'nl'),
+         ('chi', 'zh'), ('zho', 'zh'), ('jpn', 'ja'), ('rus', 'ru')
+)
+UPDATE users u
+SET reading_preferences = jsonb_set(u.reading_preferences, '{languages}', (
+  SELECT COALESCE(jsonb_agg(x.code ORDER BY x.first), '[]'::jsonb)
+  FROM (
+    SELECT COALESCE(m.iso, e.lang) AS code, MIN(e.n) AS first
+    FROM jsonb_array_elements_text(u.reading_preferences->'languages') WITH ORDINALITY e(lang, n)
+    LEFT JOIN marc m ON m.code = e.lang
+    GROUP BY COALESCE(m.iso, e.lang)
+  ) x
+))
+WHERE jsonb_typeof(u.reading_preferences->'languages') = 'array'
+  AND u.reading_preferences->'languages' ?| (SELECT array_agg(code) FROM marc);
+
+-- +goose Down
+
+-- The ISO codes are valid version 1 values; the MARC spelling is not kept.
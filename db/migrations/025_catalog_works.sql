@@ -0,0 +1,16 @@
+-- +goose Up
+
+-- Open Library works, loaded from bulk dumps so that editions without
+-- subjects or a description can take their work's.
+CREATE TABLE IF NOT EXISTS catalog_works (
+    key VARCHAR(50) PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    subjects TEXT[] NOT NULL DEFAULT '{}',
+    description TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+
+DELETE FROM catalog_sources WHERE entity_type = 'WORK';
+DROP TABLE IF EXISTS catalog_works;
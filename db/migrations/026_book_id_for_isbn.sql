@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- book_id_for_isbn returns the id of the book stored under an ISBN or,
+-- failing that, of one listing it among its alternate spellings. The two
+-- lookups are kept apart so each uses its own index (an OR of them scans
+-- every book); STABLE lets callers use the result as an index key.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION book_id_for_isbn(key TEXT) RETURNS UUID AS $$
+  SELECT id FROM (
+    SELECT id, 0 AS rank FROM books WHERE isbn = key
+    UNION ALL
+    SELECT id, 1 AS rank FROM books WHERE isbn_alternates @> ARRAY[key]
+  ) matches
+  ORDER BY rank
+  LIMIT 1
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+
+DROP FUNCTION IF EXISTS book_id_for_isbn(TEXT);
@@ -0,0 +1,10 @@
+-- +goose Up
+
+-- Alias edits remap only the subjects that contain the alias; this index
+-- finds them without reading every subject. The expression matches the one
+-- genre_for_subject splits into parts.
+CREATE INDEX IF NOT EXISTS subjects_name_trgm_idx ON subjects USING GIN ((lower(replace(name, '_', ' '))) gin_trgm_ops);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS subjects_name_trgm_idx;
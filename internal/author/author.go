@@ -0,0 +1,28 @@
+package author
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bookapi/internal/book"
+)
+
+// ErrNotFound is returned when no author is known by the given key.
+var ErrNotFound = errors.New("author not found")
+
+// Author is a person credited on books, keyed by Open Library author key.
+// Biographical fields are empty until ingest has hydrated the author.
+type Author struct {
+	Key       string     `json:"key"`
+	Name      string     `json:"name"`
+	BirthDate string     `json:"birth_date,omitempty"`
+	Bio       string     `json:"bio,omitempty"`
+	BookCount int        `json:"book_count"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type Repository interface {
+	GetByKey(ctx context.Context, key string) (Author, error)
+	ListBooks(ctx context.Context, key string, limit, offset int) ([]book.Book, int, error)
+}
@@ -0,0 +1,101 @@
+package author
+
+import (
+	"bookapi/internal/httpx"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+// keyPattern matches Open Library author keys such as "OL23919A".
+var keyPattern = regexp.MustCompile(`^OL\d+A$`)
+
+type HTTPHandler struct {
+	service *Service
+}
+
+func NewHTTPHandler(service *Service) *HTTPHandler {
+	return &HTTPHandler{service: service}
+}
+
+// GetByKey handles GET /authors/{key}
+// @Summary Get author
+// @Description Retrieve an author by Open Library key
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param key path string true "Author key (e.g. OL23919A)"
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /authors/{key} [get]
+func (h *HTTPHandler) GetByKey(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if !keyPattern.MatchString(key) {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid author key", nil)
+		return
+	}
+
+	a, err := h.service.Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Author not found", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, a, nil)
+}
+
+// ListBooks handles GET /authors/{key}/books
+// @Summary List author's books
+// @Description List the books an author is credited on as author, translator or editor, newest first
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param key path string true "Author key (e.g. OL23919A)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Items per page" default(20)
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /authors/{key}/books [get]
+func (h *HTTPHandler) ListBooks(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if !keyPattern.MatchString(key) {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid author key", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	books, total, err := h.service.ListBooks(r.Context(), key, pageSize, (page-1)*pageSize)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Author not found", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, books, map[string]any{
+		"page":        page,
+		"page_size":   pageSize,
+		"total":       total,
+		"total_pages": (total + pageSize - 1) / pageSize,
+	})
+}
@@ -0,0 +1,92 @@
+package author
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bookapi/internal/book"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHandler_GetByKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo)
+	handler := NewHTTPHandler(service)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().GetByKey(gomock.Any(), "OL79034A").Return(Author{Key: "OL79034A", Name: "Frank Herbert", BookCount: 3}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/authors/OL79034A", nil)
+		r.SetPathValue("key", "OL79034A")
+
+		handler.GetByKey(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"book_count":3`)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetByKey(gomock.Any(), "OL1A").Return(Author{}, ErrNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/authors/OL1A", nil)
+		r.SetPathValue("key", "OL1A")
+
+		handler.GetByKey(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/authors/herbert", nil)
+		r.SetPathValue("key", "herbert")
+
+		handler.GetByKey(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHTTPHandler_ListBooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo)
+	handler := NewHTTPHandler(service)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().GetByKey(gomock.Any(), "OL79034A").Return(Author{Key: "OL79034A"}, nil)
+		mockRepo.EXPECT().ListBooks(gomock.Any(), "OL79034A", 10, 10).Return([]book.Book{
+			{ISBN: "9780441172719", Title: "Dune", Authors: []book.AuthorRef{{Key: "OL79034A", Name: "Frank Herbert", Role: book.RoleAuthor}}},
+		}, 11, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/authors/OL79034A/books?page=2&page_size=10", nil)
+		r.SetPathValue("key", "OL79034A")
+
+		handler.ListBooks(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total_pages":2`)
+		assert.Contains(t, w.Body.String(), `"role":"author"`)
+	})
+
+	t.Run("unknown author", func(t *testing.T) {
+		mockRepo.EXPECT().GetByKey(gomock.Any(), "OL1A").Return(Author{}, ErrNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/authors/OL1A/books", nil)
+		r.SetPathValue("key", "OL1A")
+
+		handler.ListBooks(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,102 @@
+package author
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bookapi/internal/book"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+}
+
+func NewPostgresRepo(db *pgxpool.Pool, timeout time.Duration) *PostgresRepo {
+	return &PostgresRepo{db: db, timeout: timeout}
+}
+
+func (r *PostgresRepo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+// GetByKey prefers the hydrated catalog_authors row and falls back to the
+// name a book credits when ingest has linked the author but not fetched it.
+func (r *PostgresRepo) GetByKey(ctx context.Context, key string) (Author, error) {
+	const query = `
+		SELECT $1::text, COALESCE(ca.name, ba.name), COALESCE(ca.birth_date, ''), COALESCE(ca.bio, ''),
+		       (SELECT COUNT(DISTINCT book_id) FROM book_authors WHERE author_key = $1),
+		       ca.updated_at
+		FROM (SELECT 1) one
+		LEFT JOIN catalog_authors ca ON ca.key = $1
+		LEFT JOIN LATERAL (
+			SELECT name FROM book_authors WHERE author_key = $1 LIMIT 1
+		) ba ON true
+		WHERE ca.key IS NOT NULL OR ba.name IS NOT NULL
+	`
+	var a Author
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	err := r.db.QueryRow(timeoutCtx, query, key).Scan(
+		&a.Key, &a.Name, &a.BirthDate, &a.Bio, &a.BookCount, &a.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Author{}, ErrNotFound
+		}
+		return Author{}, err
+	}
+	return a, nil
+}
+
+func (r *PostgresRepo) ListBooks(ctx context.Context, key string, limit, offset int) ([]book.Book, int, error) {
+	const countQuery = `SELECT COUNT(DISTINCT book_id) FROM book_authors WHERE author_key = $1`
+	const query = `
+		SELECT b.id, b.isbn, b.title, b.subtitle, b.genre, b.publisher, b.description,
+		       b.published_date, b.publication_year, b.page_count, b.language, b.cover_url,
+		       b.work_id, bauth.authors, b.rating_avg, b.rating_count, b.rating_histogram,
+		       b.created_at, b.updated_at
+		FROM books b
+		LEFT JOIN LATERAL (
+			SELECT json_agg(json_build_object('key', ba.author_key, 'name', ba.name, 'role', ba.role)
+			                ORDER BY ba.position, ba.role) AS authors
+			FROM book_authors ba
+			WHERE ba.book_id = b.id
+		) bauth ON true
+		WHERE EXISTS (SELECT 1 FROM book_authors ba WHERE ba.book_id = b.id AND ba.author_key = $1)
+		ORDER BY b.publication_year DESC NULLS LAST, b.title, b.id
+		LIMIT $2 OFFSET $3
+	`
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRow(timeoutCtx, countQuery, key).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(timeoutCtx, query, key, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	books := []book.Book{}
+	for rows.Next() {
+		var b book.Book
+		if err := rows.Scan(
+			&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
+			&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
+			&b.WorkID, &b.Authors, &b.Rating.Average, &b.Rating.Count, &b.Rating.Histogram,
+			&b.CreatedAt, &b.UpdatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		books = append(books, b)
+	}
+	return books, total, rows.Err()
+}
@@ -0,0 +1,29 @@
+package author
+
+import (
+	"context"
+
+	"bookapi/internal/book"
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Get returns the author with the given key.
+func (s *Service) Get(ctx context.Context, key string) (Author, error) {
+	return s.repo.GetByKey(ctx, key)
+}
+
+// ListBooks returns a page of the books the author is credited on, in any
+// role, along with the total count. Unknown authors yield ErrNotFound.
+func (s *Service) ListBooks(ctx context.Context, key string, limit, offset int) ([]book.Book, int, error) {
+	if _, err := s.repo.GetByKey(ctx, key); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListBooks(ctx, key, limit, offset)
+}
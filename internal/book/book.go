@@ -2,46 +2,210 @@ package book
 
 import (
 	"errors"
+	"strings"
 	"time"
+
+	"bookapi/internal/platform/textsearch"
 )
 
-// ErrNotFound is returned when a book is not found.
-var ErrNotFound = errors.New("book not found")
+var (
+	// ErrNotFound is returned when a book is not found.
+	ErrNotFound = errors.New("book not found")
+	// ErrAlreadyExists is returned when a book with the same ISBN already exists.
+	ErrAlreadyExists = errors.New("book already exists")
+	// ErrDeadlock is returned when an ingest upsert was rolled back to break
+	// a deadlock with a concurrent one; it is safe to retry.
+	ErrDeadlock = errors.New("book upsert deadlocked")
+)
 
 // Book represents a book entity.
 type Book struct {
-	ID              string    `json:"id"`
-	ISBN            string    `json:"isbn"`
-	Title           string    `json:"title"`
-	Subtitle        string    `json:"subtitle,omitempty"`
-	Genre           string    `json:"genre,omitempty"`
-	Publisher       string    `json:"publisher,omitempty"`
-	Description     string    `json:"description,omitempty"`
-	PublishedDate   string    `json:"published_date,omitempty"`
-	PublicationYear *int      `json:"publication_year,omitempty"`
-	PageCount       *int      `json:"page_count,omitempty"`
-	Language        string    `json:"language,omitempty"`
-	CoverURL        *string   `json:"cover_url,omitempty"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              string      `json:"id"`
+	ISBN            string      `json:"isbn"`
+	Title           string      `json:"title"`
+	Subtitle        string      `json:"subtitle,omitempty"`
+	Genre           string      `json:"genre,omitempty"`
+	Publisher       string      `json:"publisher,omitempty"`
+	Description     string      `json:"description,omitempty"`
+	PublishedDate   string      `json:"published_date,omitempty"`
+	PublicationYear *int        `json:"publication_year,omitempty"`
+	PageCount       *int        `json:"page_count,omitempty"`
+	Language        string      `json:"language,omitempty"`
+	CoverURL        *string     `json:"cover_url,omitempty"`
+	WorkID          *string     `json:"work_id,omitempty"`
+	Series          *SeriesRef  `json:"series,omitempty"`
+	Authors         []AuthorRef `json:"authors,omitempty"`
+	Genres          []string    `json:"genres,omitempty"`
+	// Rating is maintained on the book row as ratings are written.
+	Rating RatingSummary `json:"rating"`
+	// Highlights are the matched fragments of a full-text search. Only set
+	// by List when Query.Search is.
+	Highlights *textsearch.Highlights `json:"highlights,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+
+	// WorkKey is the Open Library work key (e.g. "/works/OL45804W") the
+	// edition belongs to. Only read by UpsertFromIngest.
+	WorkKey string `json:"-"`
+	// SeriesMemberships lists the series the edition belongs to, matched by
+	// name. Only read by UpsertFromIngest.
+	SeriesMemberships []SeriesRef `json:"-"`
+	// Subjects are the free-text Open Library subjects of the edition, in
+	// source order. They are mapped to curated genres on write and set the
+	// display Genre. Only read by UpsertFromIngest.
+	Subjects []string `json:"-"`
+
+	// sortKey is the value of the ORDER BY expression for this row, used to
+	// build the next-page cursor. Only set by List.
+	sortKey *string
+}
+
+// RatingSummary aggregates the star ratings of a book.
+type RatingSummary struct {
+	Average float64 `json:"average_rating"`
+	Count   int     `json:"ratings_count"`
+	// Histogram counts the 1 to 5 star ratings, in that order.
+	Histogram []int `json:"histogram"`
+}
+
+// SeriesRef places a book in a series. Position may be fractional (2.5) and
+// is nil when the series is known but the book's place in it is not.
+type SeriesRef struct {
+	ID       string   `json:"id,omitempty"`
+	Name     string   `json:"name"`
+	Position *float64 `json:"position,omitempty"`
+}
+
+// Contributor roles recorded in book_authors.
+const (
+	RoleAuthor     = "author"
+	RoleTranslator = "translator"
+	RoleEditor     = "editor"
+)
+
+// AuthorRef credits a person on a book. Key is the Open Library author key
+// (e.g. "OL23919A"); authors are listed in the order the book credits them.
+type AuthorRef struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// GenreSlug normalizes a genre name or legacy subject ("Science Fiction",
+// "science_fiction") to its slug ("science-fiction").
+func GenreSlug(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "-")
 }
 
 // Query defines filters and pagination for listing books.
 type Query struct {
+	// Genre and Genres select genre slugs (names are accepted for Genre)
+	// and match books in the genre or any of its descendants.
 	Genre     string
 	Genres    []string
 	Publisher string
 	Q         string
-	Search    string
-	MinRating *float64
-	YearFrom  *int
-	YearTo    *int
-	Language  string
-	Sort      string
-	Desc      bool
-	Limit     int
-	Offset    int
+	// Search is full-text in websearch_to_tsquery syntax: "exact phrase",
+	// -excluded, a OR b.
+	Search string
+	// Title, Author and PublisherMatch are the field qualifiers of an
+	// advanced search (title:"dune" by:herbert publisher:ace).
+	Title          string
+	Author         string
+	PublisherMatch string
+	// SearchLanguage selects the text search config Search and Title are
+	// parsed with; see textsearch.Config.
+	SearchLanguage string
+	MinRating      *float64
+	YearFrom       *int
+	YearTo         *int
+	Language       string
+	Sort           string
+	Desc           bool
+	Limit          int
+	Offset         int
 	// Cursor pagination
-	Cursor  string // Base64 encoded cursor for next page
-	AfterID string // Alternative: use book ID directly
+	Cursor  string      // Signed cursor for next page
+	AfterID string      // Alternative: use book ID directly
+	After   *CursorData // Decoded cursor, set by Service.List
+
+	// Facets lists the facet buckets to compute alongside the results.
+	Facets []string
+	// Fuzzy reruns a search that matched nothing with its best correction.
+	Fuzzy bool
+	// Highlight sets the markers of search highlights.
+	Highlight textsearch.Highlight
+}
+
+// Page is a page of books returned by Service.List.
+type Page struct {
+	Books      []Book
+	Total      int
+	NextCursor string
+	Facets     map[string][]FacetBucket
+	// Suggestions holds corrected queries when Search matched nothing.
+	Suggestions []string
+	// CorrectedQuery is the suggestion used when a fuzzy search was rerun.
+	CorrectedQuery string
+}
+
+// UpdateCommand holds a partial update for a book. Nil fields are left unchanged.
+type UpdateCommand struct {
+	Title           *string `json:"title" validate:"omitempty,min=1,max=500"`
+	Subtitle        *string `json:"subtitle" validate:"omitempty,max=500"`
+	Genre           *string `json:"genre" validate:"omitempty,min=1,max=100"`
+	Publisher       *string `json:"publisher" validate:"omitempty,min=1,max=255"`
+	Description     *string `json:"description"`
+	PublishedDate   *string `json:"published_date" validate:"omitempty,max=50"`
+	PublicationYear *int    `json:"publication_year" validate:"omitempty,gt=0"`
+	PageCount       *int    `json:"page_count" validate:"omitempty,gt=0"`
+	Language        *string `json:"language" validate:"omitempty,max=10"`
+	CoverURL        *string `json:"cover_url" validate:"omitempty,url,max=500"`
+}
+
+// Trim trims the text fields of the command, so that validation rejects
+// blank values for fields that must not be empty.
+func (c *UpdateCommand) Trim() {
+	for _, f := range []*string{c.Title, c.Subtitle, c.Genre, c.Publisher, c.PublishedDate, c.Language} {
+		if f != nil {
+			*f = strings.TrimSpace(*f)
+		}
+	}
+}
+
+// Apply copies the non-nil fields of the command onto the book.
+func (c UpdateCommand) Apply(b *Book) {
+	if c.Title != nil {
+		b.Title = *c.Title
+	}
+	if c.Subtitle != nil {
+		b.Subtitle = *c.Subtitle
+	}
+	if c.Genre != nil {
+		b.Genre = *c.Genre
+	}
+	if c.Publisher != nil {
+		b.Publisher = *c.Publisher
+	}
+	if c.Description != nil {
+		b.Description = *c.Description
+	}
+	if c.PublishedDate != nil {
+		b.PublishedDate = *c.PublishedDate
+	}
+	if c.PublicationYear != nil {
+		b.PublicationYear = c.PublicationYear
+	}
+	if c.PageCount != nil {
+		b.PageCount = c.PageCount
+	}
+	if c.Language != nil {
+		b.Language = *c.Language
+	}
+	if c.CoverURL != nil {
+		b.CoverURL = c.CoverURL
+	}
 }
@@ -1,40 +1,105 @@
 package book
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"strings"
+
+	"bookapi/internal/platform/textsearch"
 )
 
-// CursorData represents the data encoded in a cursor
+// ErrInvalidCursor is returned when a cursor is malformed, has been tampered
+// with, or was issued for a different query.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorData represents the data encoded in a cursor: the sort key of the last
+// row on the previous page (as text, nil when the key is NULL) and its ID as a
+// tie-breaker.
 type CursorData struct {
-	AfterID   string `json:"after_id,omitempty"`
-	CreatedAt string `json:"created_at,omitempty"`
+	Key     *string `json:"k"`
+	AfterID string  `json:"id"`
+}
+
+// CursorCodec signs and verifies pagination cursors. A cursor is bound to the
+// filters and sort order of the query it was issued for, so it cannot be
+// replayed against a different query.
+type CursorCodec struct {
+	secret []byte
 }
 
-// EncodeCursor encodes cursor data to a base64 string
-func EncodeCursor(data CursorData) string {
+// NewCursorCodec creates a codec that signs cursors with the given secret.
+func NewCursorCodec(secret string) *CursorCodec {
+	return &CursorCodec{secret: []byte(secret)}
+}
+
+// Encode encodes cursor data for the given query to a URL-safe string.
+func (c *CursorCodec) Encode(data CursorData, q Query) string {
 	if data.AfterID == "" {
 		return ""
 	}
-	jsonBytes, err := json.Marshal(data)
+	payload, err := json.Marshal(data)
 	if err != nil {
 		return ""
 	}
-	return base64.URLEncoding.EncodeToString(jsonBytes)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(c.sign(payload, q))
 }
 
-// DecodeCursor decodes a base64 cursor string to CursorData
-func DecodeCursor(cursor string) (CursorData, error) {
-	if cursor == "" {
-		return CursorData{}, nil
+// Decode verifies a cursor against the given query and returns its data.
+func (c *CursorCodec) Decode(cursor string, q Query) (CursorData, error) {
+	enc := base64.RawURLEncoding
+	payloadPart, sigPart, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return CursorData{}, ErrInvalidCursor
 	}
-
-	decoded, err := base64.URLEncoding.DecodeString(cursor)
+	payload, err := enc.DecodeString(payloadPart)
 	if err != nil {
-		return CursorData{}, err
+		return CursorData{}, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(sigPart)
+	if err != nil || !hmac.Equal(sig, c.sign(payload, q)) {
+		return CursorData{}, ErrInvalidCursor
 	}
-
 	var data CursorData
-	err = json.Unmarshal(decoded, &data)
-	return data, err
+	if err := json.Unmarshal(payload, &data); err != nil || data.AfterID == "" {
+		return CursorData{}, ErrInvalidCursor
+	}
+	return data, nil
+}
+
+func (c *CursorCodec) sign(payload []byte, q Query) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(queryFingerprint(q))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// queryFingerprint serializes the parts of a query that determine which rows
+// are returned and in what order. Pagination fields are deliberately excluded.
+func queryFingerprint(q Query) []byte {
+	b, _ := json.Marshal(struct {
+		Genre          string
+		Genres         []string
+		Publisher      string
+		Q              string
+		Search         string
+		Title          string
+		Author         string
+		PublisherMatch string
+		SearchConfig   string
+		MinRating      *float64
+		YearFrom       *int
+		YearTo         *int
+		Language       string
+		Sort           string
+		Desc           bool
+	}{
+		q.Genre, q.Genres, q.Publisher, q.Q, q.Search, q.Title, q.Author, q.PublisherMatch,
+		textsearch.Config(q.SearchLanguage), q.MinRating, q.YearFrom, q.YearTo, q.Language, q.Sort, q.Desc,
+	})
+	return b
 }
@@ -1,50 +1,76 @@
 package book
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestEncodeCursor(t *testing.T) {
+func TestCursorCodec_Encode(t *testing.T) {
+	codec := NewCursorCodec("secret")
+
 	t.Run("empty data", func(t *testing.T) {
-		result := EncodeCursor(CursorData{})
+		result := codec.Encode(CursorData{}, Query{})
 		assert.Empty(t, result)
 	})
 
 	t.Run("with after_id", func(t *testing.T) {
-		result := EncodeCursor(CursorData{AfterID: "abc123"})
+		result := codec.Encode(CursorData{AfterID: "abc123"}, Query{})
 		assert.NotEmpty(t, result)
-		// Should be base64 encoded JSON
-		assert.Equal(t, "eyJhZnRlcl9pZCI6ImFiYzEyMyJ9", result)
+		assert.Contains(t, result, ".")
 	})
 }
 
-func TestDecodeCursor(t *testing.T) {
-	t.Run("empty cursor", func(t *testing.T) {
-		data, err := DecodeCursor("")
-		assert.NoError(t, err)
-		assert.Equal(t, CursorData{}, data)
-	})
+func TestCursorCodec_Decode(t *testing.T) {
+	codec := NewCursorCodec("secret")
+	key := "Dune"
+	q := Query{Sort: "title", Genre: "Science Fiction"}
+	valid := codec.Encode(CursorData{Key: &key, AfterID: "abc123"}, q)
 
 	t.Run("valid cursor", func(t *testing.T) {
-		// "eyJhZnRlcl9pZCI6ImFiYzEyMyJ9" = {"after_id":"abc123"}
-		data, err := DecodeCursor("eyJhZnRlcl9pZCI6ImFiYzEyMyJ9")
+		data, err := codec.Decode(valid, q)
 		assert.NoError(t, err)
 		assert.Equal(t, "abc123", data.AfterID)
+		assert.Equal(t, "Dune", *data.Key)
+	})
+
+	t.Run("ignores pagination fields", func(t *testing.T) {
+		paged := q
+		paged.Limit, paged.Offset, paged.Cursor = 50, 100, valid
+		_, err := codec.Decode(valid, paged)
+		assert.NoError(t, err)
 	})
 
 	t.Run("invalid cursor", func(t *testing.T) {
-		data, err := DecodeCursor("invalid-base64!!!")
-		assert.Error(t, err)
-		assert.Equal(t, CursorData{}, data)
+		_, err := codec.Decode("invalid-base64!!!", q)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		other := codec.Encode(CursorData{AfterID: "zzz999"}, q)
+		otherPayload, _, _ := strings.Cut(other, ".")
+		_, validSig, _ := strings.Cut(valid, ".")
+		forged := otherPayload + "." + validSig
+		_, err := codec.Decode(forged, q)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("different query", func(t *testing.T) {
+		_, err := codec.Decode(valid, Query{Sort: "year", Genre: "Science Fiction"})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("different secret", func(t *testing.T) {
+		_, err := NewCursorCodec("other").Decode(valid, q)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
 	})
 }
 
-func TestRoundTrip(t *testing.T) {
+func TestCursorCodec_RoundTripNullKey(t *testing.T) {
+	codec := NewCursorCodec("secret")
 	original := CursorData{AfterID: "test-uuid-123"}
-	encoded := EncodeCursor(original)
-	decoded, err := DecodeCursor(encoded)
+	decoded, err := codec.Decode(codec.Encode(original, Query{}), Query{})
 	assert.NoError(t, err)
-	assert.Equal(t, original.AfterID, decoded.AfterID)
+	assert.Equal(t, original, decoded)
 }
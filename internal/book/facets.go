@@ -0,0 +1,47 @@
+package book
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Facet names accepted by the facets query parameter.
+const (
+	FacetGenre     = "genre"
+	FacetLanguage  = "language"
+	FacetPublisher = "publisher"
+	FacetDecade    = "decade"
+	FacetRating    = "rating"
+)
+
+var validFacets = map[string]bool{
+	FacetGenre:     true,
+	FacetLanguage:  true,
+	FacetPublisher: true,
+	FacetDecade:    true,
+	FacetRating:    true,
+}
+
+// FacetBucket is the number of books sharing one facet value.
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// ParseFacets parses a comma-separated facet list, dropping duplicates.
+func ParseFacets(s string) ([]string, error) {
+	var facets []string
+	seen := make(map[string]bool)
+	for _, f := range strings.Split(s, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" || seen[f] {
+			continue
+		}
+		if !validFacets[f] {
+			return nil, fmt.Errorf("unknown facet: %s", f)
+		}
+		seen[f] = true
+		facets = append(facets, f)
+	}
+	return facets, nil
+}
@@ -0,0 +1,17 @@
+package book
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFacets(t *testing.T) {
+	facets, err := ParseFacets(" Genre, decade,genre,,rating")
+	require.NoError(t, err)
+	assert.Equal(t, []string{FacetGenre, FacetDecade, FacetRating}, facets)
+
+	_, err = ParseFacets("genre,color")
+	assert.Error(t, err)
+}
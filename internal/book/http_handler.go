@@ -2,6 +2,10 @@ package book
 
 import (
 	"bookapi/internal/httpx"
+	"bookapi/internal/platform/isbn"
+	"bookapi/internal/platform/textsearch"
+	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
@@ -24,20 +28,26 @@ func NewHTTPHandler(service *Service) *HTTPHandler {
 // @Produce json
 // @Param page query int false "Page number (offset-based)" default(1)
 // @Param page_size query int false "Items per page (offset-based)" default(20)
-// @Param cursor query string false "Cursor for cursor-based pagination (base64 encoded)"
+// @Param cursor query string false "Cursor for cursor-based pagination (next_cursor from a previous page)"
 // @Param after_id query string false "After ID for cursor-based pagination"
 // @Param q query string false "Simple search query"
-// @Param search query string false "Full-text search query"
-// @Param genre query string false "Filter by genre"
-// @Param genres query string false "Filter by multiple genres (comma-separated)"
+// @Param search query string false "Full-text search: \"exact phrase\", -exclude, a OR b, and qualifiers title: author: (by:) publisher: genre: language: year: (1965, >1980, <=1990, 1960..1970)"
+// @Param genre query string false "Filter by genre slug or name, including its descendant genres"
+// @Param genres query string false "Filter by genre slugs, including descendants (comma-separated, e.g. science-fiction,fantasy)"
 // @Param publisher query string false "Filter by publisher"
 // @Param min_rating query number false "Minimum rating (0-5)"
 // @Param year_from query int false "Filter by publication year from"
 // @Param year_to query int false "Filter by publication year to"
 // @Param language query string false "Filter by language"
+// @Param Accept-Language header string false "Language to parse the search in when no language filter is given (stemming and stop words)"
 // @Param sort query string false "Sort field"
 // @Param desc query boolean false "Sort descending" default(false)
+// @Param facets query string false "Facet counts to include in meta (comma-separated: genre, language, publisher, decade, rating)"
+// @Param fuzzy query boolean false "When search matches nothing, rerun it with the best suggestion" default(false)
+// @Param highlight_start query string false "Marker inserted before matched words in highlights" default(<mark>)
+// @Param highlight_stop query string false "Marker inserted after matched words in highlights" default(</mark>)
 // @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
 // @Failure 500 {object} httpx.ErrorResponse
 // @Router /books [get]
 func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -51,6 +61,7 @@ func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
 		Sort:      query.Get("sort"),
 		Desc:      query.Get("desc") == "true",
 		Language:  query.Get("language"),
+		Fuzzy:     query.Get("fuzzy") == "true",
 	}
 
 	// Cursor-based pagination
@@ -59,7 +70,34 @@ func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	// Parse other filters
 	if genres := query.Get("genres"); genres != "" {
-		params.Genres = strings.Split(genres, ",")
+		for _, g := range strings.Split(genres, ",") {
+			if slug := GenreSlug(g); slug != "" {
+				params.Genres = append(params.Genres, slug)
+			}
+		}
+	}
+	if facets := query.Get("facets"); facets != "" {
+		parsed, err := ParseFacets(facets)
+		if err != nil {
+			httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", []httpx.ErrorDetail{
+				{Field: "facets", Message: err.Error()},
+			})
+			return
+		}
+		params.Facets = parsed
+	}
+
+	for _, param := range []string{"highlight_start", "highlight_stop"} {
+		if err := textsearch.CheckMarker(query.Get(param)); err != nil {
+			httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", []httpx.ErrorDetail{
+				{Field: param, Message: err.Error()},
+			})
+			return
+		}
+	}
+	params.Highlight = textsearch.Highlight{
+		StartSel: query.Get("highlight_start"),
+		StopSel:  query.Get("highlight_stop"),
 	}
 
 	if minRatingStr := query.Get("min_rating"); minRatingStr != "" {
@@ -80,6 +118,17 @@ func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if params.Search != "" {
+		parsed, err := textsearch.Parse(params.Search, searchFields...)
+		if err != nil {
+			httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid search query", searchErrorDetails(err))
+			return
+		}
+		applySearch(&params, parsed)
+		params.SearchLanguage = textsearch.QueryLanguage(params.Language, r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
+	}
+
 	// Offset-based pagination (fallback if no cursor)
 	page, _ := strconv.Atoi(query.Get("page"))
 	if page < 1 {
@@ -92,11 +141,16 @@ func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
 	params.Limit = pageSize
 	params.Offset = (page - 1) * pageSize
 
-	books, total, err := h.service.List(r.Context(), params)
+	result, err := h.service.List(r.Context(), params)
 	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid cursor", nil)
+			return
+		}
 		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
 		return
 	}
+	books, total := result.Books, result.Total
 
 	// Build response meta - different format for cursor vs offset pagination
 	var meta map[string]any
@@ -105,11 +159,7 @@ func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
 		// Cursor-based pagination response
 		meta = map[string]any{
 			"total":    total,
-			"has_more": len(books) == params.Limit,
-		}
-		if len(books) > 0 && len(books) == params.Limit {
-			lastBook := books[len(books)-1]
-			meta["next_cursor"] = EncodeCursor(CursorData{AfterID: lastBook.ID})
+			"has_more": result.NextCursor != "",
 		}
 	} else {
 		// Offset-based pagination response (default)
@@ -120,11 +170,178 @@ func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
 			"total_pages": (total + pageSize - 1) / pageSize,
 		}
 	}
+	// A cursor is returned in both modes so clients can switch to keyset
+	// pagination after the first page.
+	if result.NextCursor != "" {
+		meta["next_cursor"] = result.NextCursor
+	}
+	if result.Facets != nil {
+		meta["facets"] = result.Facets
+	}
+	if len(result.Suggestions) > 0 {
+		meta["suggestions"] = result.Suggestions
+	}
+	if result.CorrectedQuery != "" {
+		meta["corrected_query"] = result.CorrectedQuery
+	}
+
+	httpx.JSONSuccess(w, r, books, meta)
+}
+
+// Suggest handles GET /books/suggest
+// @Summary Suggest books
+// @Description Typeahead suggestions of titles and author names for a partial query, ranked by trigram similarity with prefix matches first
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param q query string true "Partial query (at least 2 characters)"
+// @Param limit query int false "Maximum suggestions" default(10)
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /books/suggest [get]
+func (h *HTTPHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if len([]rune(q)) < minSuggestLen || len(q) > maxSuggestLen {
+		httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", []httpx.ErrorDetail{
+			{Field: "q", Message: "must be between 2 and 100 characters"},
+		})
+		return
+	}
+
+	limit := DefaultSuggestLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	suggestions, err := h.service.Suggest(r.Context(), q, limit)
+	if err != nil {
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	// Suggestions are requested on every keystroke; let clients and proxies
+	// reuse them briefly.
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	httpx.JSONSuccess(w, r, suggestions, nil)
+}
+
+const (
+	minSuggestLen = 2
+	maxSuggestLen = 100
+)
+
+// TopRated handles GET /books/top
+// @Summary Top rated books
+// @Description Books ranked by Bayesian average rating: each book is scored as if it also had a fixed number of ratings at the overall mean, so a few high ratings do not outrank a high average over many. Rankings are cached between recomputations.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param genre query string false "Genre slug or name; includes its descendants"
+// @Param language query string false "Filter by language"
+// @Param limit query int false "Number of books (max 100)" default(20)
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /books/top [get]
+func (h *HTTPHandler) TopRated(w http.ResponseWriter, r *http.Request) {
+	h.ranked(w, r, h.service.TopRated)
+}
+
+// Trending handles GET /books/trending
+// @Summary Trending books
+// @Description Books ranked by recent rating and reading list activity, with older activity decaying exponentially. Rankings are cached between recomputations.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param genre query string false "Genre slug or name; includes its descendants"
+// @Param language query string false "Filter by language"
+// @Param limit query int false "Number of books (max 100)" default(20)
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /books/trending [get]
+func (h *HTTPHandler) Trending(w http.ResponseWriter, r *http.Request) {
+	h.ranked(w, r, h.service.Trending)
+}
+
+func (h *HTTPHandler) ranked(w http.ResponseWriter, r *http.Request, rank func(context.Context, RankQuery) (Ranking, error)) {
+	query := r.URL.Query()
+	q := RankQuery{
+		Genre:    query.Get("genre"),
+		Language: query.Get("language"),
+		Limit:    DefaultRankLimit,
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > MaxRankLimit {
+			httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", []httpx.ErrorDetail{
+				{Field: "limit", Message: "must be between 1 and 100"},
+			})
+			return
+		}
+		q.Limit = limit
+	}
+
+	ranking, err := rank(r.Context(), q)
+	if err != nil {
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	httpx.JSONSuccess(w, r, ranking.Books, map[string]any{
+		"computed_at": ranking.ComputedAt,
+		"limit":       q.Limit,
+	})
+}
+
+// Similar handles GET /books/{isbn}/similar
+// @Summary Similar books
+// @Description Books like the given one, ranked by shared authors, subjects and genres, overlapping title words, and readers who shelved or rated both highly. Each book carries a reason naming its strongest match. Other editions of the same work are excluded.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param isbn path string true "Book ISBN"
+// @Param limit query int false "Number of books (max 50)" default(10)
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /books/{isbn}/similar [get]
+func (h *HTTPHandler) Similar(w http.ResponseWriter, r *http.Request) {
+	key := isbn.Key(r.PathValue("isbn"))
+
+	limit := DefaultSimilarLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > MaxSimilarLimit {
+			httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", []httpx.ErrorDetail{
+				{Field: "limit", Message: "must be between 1 and 50"},
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	books, err := h.service.Similar(r.Context(), key, limit)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "ISBN not found", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, books, map[string]any{"limit": limit})
 }
 
 // GetByISBN handles GET /books/{isbn}
 // @Summary Get book by ISBN
-// @Description Retrieve a book by its ISBN
+// @Description Retrieve a book by its ISBN. ISBN-10, hyphenated and previous forms are accepted.
 // @Tags books
 // @Accept json
 // @Produce json
@@ -135,19 +352,19 @@ func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
 // @Router /books/{isbn} [get]
 func (h *HTTPHandler) GetByISBN(w http.ResponseWriter, r *http.Request) {
 	// Go 1.22+ routing: use r.PathValue
-	isbn := r.PathValue("isbn")
-	if isbn == "" {
+	raw := r.PathValue("isbn")
+	if raw == "" {
 		// Fallback for old routing if needed, but we aim for modern
 		const prefix = "/books/"
-		isbn = strings.TrimPrefix(r.URL.Path, prefix)
+		raw = strings.TrimPrefix(r.URL.Path, prefix)
 	}
 
-	if isbn == "" || strings.Contains(isbn, "/") {
+	if raw == "" || strings.Contains(raw, "/") {
 		http.NotFound(w, r)
 		return
 	}
 
-	book, err := h.service.GetByISBN(r.Context(), isbn)
+	book, err := h.service.GetByISBN(r.Context(), isbn.Key(raw))
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "ISBN not found", nil)
@@ -158,3 +375,272 @@ func (h *HTTPHandler) GetByISBN(w http.ResponseWriter, r *http.Request) {
 	}
 	httpx.JSONSuccess(w, r, book, nil)
 }
+
+type bookReq struct {
+	Title           string  `json:"title" validate:"required,max=500"`
+	Subtitle        string  `json:"subtitle" validate:"max=500"`
+	Genre           string  `json:"genre" validate:"required,max=100"`
+	Publisher       string  `json:"publisher" validate:"required,max=255"`
+	Description     string  `json:"description"`
+	PublishedDate   string  `json:"published_date" validate:"max=50"`
+	PublicationYear *int    `json:"publication_year" validate:"omitempty,gt=0"`
+	PageCount       *int    `json:"page_count" validate:"omitempty,gt=0"`
+	Language        string  `json:"language" validate:"max=10"`
+	CoverURL        *string `json:"cover_url" validate:"omitempty,url,max=500"`
+}
+
+// trim trims the text fields of the request before it is validated, so that
+// blank required fields are rejected.
+func (req *bookReq) trim() {
+	for _, f := range []*string{&req.Title, &req.Subtitle, &req.Genre, &req.Publisher, &req.PublishedDate, &req.Language} {
+		*f = strings.TrimSpace(*f)
+	}
+}
+
+func (req bookReq) toBook(isbn string) Book {
+	return Book{
+		ISBN:            isbn,
+		Title:           req.Title,
+		Subtitle:        req.Subtitle,
+		Genre:           req.Genre,
+		Publisher:       req.Publisher,
+		Description:     req.Description,
+		PublishedDate:   req.PublishedDate,
+		PublicationYear: req.PublicationYear,
+		PageCount:       req.PageCount,
+		Language:        req.Language,
+		CoverURL:        req.CoverURL,
+	}
+}
+
+type createBookReq struct {
+	ISBN string `json:"isbn" validate:"required,isbn"`
+	bookReq
+}
+
+type isbnParam struct {
+	ISBN string `validate:"required"`
+}
+
+// pathISBN extracts the {isbn} path value, writing a 400 response if it is
+// missing. The returned value is the lookup key from isbn.Key, so books still
+// stored under a legacy ISBN stay reachable.
+func pathISBN(w http.ResponseWriter, r *http.Request) (string, bool) {
+	raw := strings.TrimSpace(r.PathValue("isbn"))
+	if validationErrors := httpx.ValidateStruct(isbnParam{ISBN: raw}); len(validationErrors) > 0 {
+		httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", validationErrors)
+		return "", false
+	}
+	return isbn.Key(raw), true
+}
+
+// Create handles POST /books
+// @Summary Create book
+// @Description Add a new book to the catalog (admin only)
+// @Tags books
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body createBookReq true "Book"
+// @Success 201 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 403 {object} httpx.ErrorResponse
+// @Failure 409 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /books [post]
+func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req createBookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid request body", nil)
+		return
+	}
+	req.ISBN = strings.TrimSpace(req.ISBN)
+	req.trim()
+
+	if validationErrors := httpx.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", validationErrors)
+		return
+	}
+
+	key, _ := isbn.Canonical(req.ISBN)
+	b := req.toBook(key)
+	if err := h.service.Create(r.Context(), &b); err != nil {
+		if errors.Is(err, ErrAlreadyExists) {
+			httpx.JSONError(w, r, http.StatusConflict, "ALREADY_EXISTS", "A book with this ISBN already exists", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccessCreated(w, r, b)
+}
+
+// Replace handles PUT /books/{isbn}
+// @Summary Replace book
+// @Description Overwrite all editable fields of a book (admin only)
+// @Tags books
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param isbn path string true "Book ISBN"
+// @Param request body bookReq true "Book"
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 403 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /books/{isbn} [put]
+func (h *HTTPHandler) Replace(w http.ResponseWriter, r *http.Request) {
+	isbn, ok := pathISBN(w, r)
+	if !ok {
+		return
+	}
+
+	var req bookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid request body", nil)
+		return
+	}
+	req.trim()
+
+	if validationErrors := httpx.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", validationErrors)
+		return
+	}
+
+	b := req.toBook(isbn)
+	if err := h.service.Replace(r.Context(), isbn, &b); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "ISBN not found", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, b, nil)
+}
+
+// Patch handles PATCH /books/{isbn}
+// @Summary Update book
+// @Description Partially update a book; omitted fields are left unchanged (admin only)
+// @Tags books
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param isbn path string true "Book ISBN"
+// @Param request body UpdateCommand true "Fields to update"
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 403 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /books/{isbn} [patch]
+func (h *HTTPHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	isbn, ok := pathISBN(w, r)
+	if !ok {
+		return
+	}
+
+	var cmd UpdateCommand
+	if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid request body", nil)
+		return
+	}
+	cmd.Trim()
+
+	if validationErrors := httpx.ValidateStruct(cmd); len(validationErrors) > 0 {
+		httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", validationErrors)
+		return
+	}
+
+	b, err := h.service.Patch(r.Context(), isbn, cmd)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "ISBN not found", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, b, nil)
+}
+
+// Delete handles DELETE /books/{isbn}
+// @Summary Delete book
+// @Description Remove a book together with its ratings and reading-list entries (admin only)
+// @Tags books
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param isbn path string true "Book ISBN"
+// @Success 204 "No Content"
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 403 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /books/{isbn} [delete]
+func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	isbn, ok := pathISBN(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), isbn); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "ISBN not found", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccessNoContent(w)
+}
+
+// searchFields are the qualifiers accepted in the search parameter.
+var searchFields = []string{
+	textsearch.FieldTitle, textsearch.FieldAuthor, textsearch.FieldPublisher,
+	textsearch.FieldGenre, textsearch.FieldLanguage, textsearch.FieldYear,
+}
+
+// applySearch moves the qualifiers of a parsed search into the query. A
+// language qualifier overrides the language parameter, a genre joins the
+// selected genres and year bounds intersect year_from and year_to.
+func applySearch(q *Query, p textsearch.Parsed) {
+	q.Search = p.Text
+	q.Title = p.Title
+	q.Author = p.Author
+	q.PublisherMatch = p.Publisher
+	if p.Language != "" {
+		q.Language = p.Language
+	}
+	if slug := GenreSlug(p.Genre); slug != "" {
+		q.Genres = append(q.Genres, slug)
+	}
+	if p.YearFrom != nil && (q.YearFrom == nil || *p.YearFrom > *q.YearFrom) {
+		q.YearFrom = p.YearFrom
+	}
+	if p.YearTo != nil && (q.YearTo == nil || *p.YearTo < *q.YearTo) {
+		q.YearTo = p.YearTo
+	}
+}
+
+// searchErrorDetails turns the syntax errors of a search into one detail each.
+func searchErrorDetails(err error) []httpx.ErrorDetail {
+	var syntaxErrs textsearch.SyntaxErrors
+	if !errors.As(err, &syntaxErrs) {
+		return []httpx.ErrorDetail{{Field: "search", Message: err.Error()}}
+	}
+	details := make([]httpx.ErrorDetail, len(syntaxErrs))
+	for i, e := range syntaxErrs {
+		details[i] = httpx.ErrorDetail{Field: "search", Message: e.Error()}
+	}
+	return details
+}
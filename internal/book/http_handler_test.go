@@ -2,11 +2,14 @@ package book
 
 import (
 	"context"
-	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
+	"bookapi/internal/platform/textsearch"
+
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -15,7 +18,7 @@ func TestHTTPHandler_List(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, "test-secret")
 	handler := NewHTTPHandler(service)
 
 	testBook := Book{
@@ -47,14 +50,19 @@ func TestHTTPHandler_List(t *testing.T) {
 	})
 
 	t.Run("success with cursor", func(t *testing.T) {
+		key := "Dune"
+		cursor := NewCursorCodec("test-secret").Encode(CursorData{Key: &key, AfterID: "6f1c1d5e-0d7a-4c1e-9d4f-2a3b4c5d6e7f"}, Query{})
 		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
 			func(ctx context.Context, q Query) ([]Book, int, error) {
-				assert.Equal(t, "abc123", q.Cursor, "cursor should be parsed from query")
+				assert.Equal(t, cursor, q.Cursor, "cursor should be parsed from query")
+				assert.NotNil(t, q.After)
+				assert.Equal(t, "Dune", *q.After.Key)
+				assert.Zero(t, q.Offset)
 				return []Book{testBook}, 1, nil
 			})
 
 		w := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodGet, "/books?cursor=abc123", nil)
+		r := httptest.NewRequest(http.MethodGet, "/books?cursor="+cursor, nil)
 
 		handler.List(w, r)
 
@@ -64,53 +72,633 @@ func TestHTTPHandler_List(t *testing.T) {
 	t.Run("success with after_id", func(t *testing.T) {
 		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
 			func(ctx context.Context, q Query) ([]Book, int, error) {
-				assert.Equal(t, "uuid-123", q.AfterID, "after_id should be parsed from query")
+				assert.Equal(t, "6f1c1d5e-0d7a-4c1e-9d4f-2a3b4c5d6e7f", q.AfterID, "after_id should be parsed from query")
 				return []Book{testBook}, 1, nil
 			})
 
 		w := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodGet, "/books?after_id=uuid-123", nil)
+		r := httptest.NewRequest(http.MethodGet, "/books?after_id=6f1c1d5e-0d7a-4c1e-9d4f-2a3b4c5d6e7f", nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("cursor from another query", func(t *testing.T) {
+		cursor := NewCursorCodec("test-secret").Encode(CursorData{AfterID: "6f1c1d5e-0d7a-4c1e-9d4f-2a3b4c5d6e7f"}, Query{Sort: "year"})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?sort=title&cursor="+cursor, nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("full page returns next cursor", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]Book{testBook}, 5, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?page_size=1", nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"next_cursor"`)
+	})
+
+	t.Run("facets are returned in meta", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]Book{testBook}, 1, nil)
+		mockRepo.EXPECT().Facets(gomock.Any(), gomock.Any(), []string{FacetGenre, FacetDecade}).Return(
+			map[string][]FacetBucket{
+				FacetGenre:  {{Value: "Fiction", Count: 1}},
+				FacetDecade: {{Value: "1960", Count: 1}},
+			}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?facets=genre,decade", nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"facets":{"decade":[{"value":"1960","count":1}],"genre":[{"value":"Fiction","count":1}]}`)
+	})
+
+	t.Run("empty search returns suggestions", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]Book{}, 0, nil)
+		mockRepo.EXPECT().Corrections(gomock.Any(), "dnue", maxCorrections).Return([]string{"dune"}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?search=dnue", nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"suggestions":["dune"]`)
+		assert.NotContains(t, w.Body.String(), "corrected_query")
+	})
+
+	t.Run("fuzzy search reruns the correction", func(t *testing.T) {
+		gomock.InOrder(
+			mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]Book{}, 0, nil),
+			mockRepo.EXPECT().Corrections(gomock.Any(), "dnue", maxCorrections).Return([]string{"dune"}, nil),
+			mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, q Query) ([]Book, int, error) {
+					assert.Equal(t, "dune", q.Search)
+					return []Book{testBook}, 1, nil
+				}),
+		)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?search=dnue&fuzzy=true", nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"corrected_query":"dune"`)
+		assert.Contains(t, w.Body.String(), `"total":1`)
+	})
+
+	t.Run("genres are normalized to slugs", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, q Query) ([]Book, int, error) {
+			assert.Equal(t, []string{"science-fiction", "fantasy"}, q.Genres)
+			return []Book{testBook}, 1, nil
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?genres=science_fiction,%20Fantasy%20,", nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("search returns highlights with custom markers", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, q Query) ([]Book, int, error) {
+			assert.Equal(t, textsearch.Highlight{StartSel: "<em>", StopSel: "</em>"}, q.Highlight)
+			b := testBook
+			b.Highlights = &textsearch.Highlights{Title: "<em>Dune</em>", Description: "… the desert planet <em>Dune</em> …"}
+			return []Book{b}, 1, nil
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?search=dune&highlight_start=%3Cem%3E&highlight_stop=%3C/em%3E", nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"highlights":{"title":"\u003cem\u003eDune\u003c/em\u003e"`)
+	})
+
+	t.Run("invalid highlight marker", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, `/books?search=dune&highlight_stop=%22`, nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "highlight_stop")
+	})
+
+	t.Run("search qualifiers become filters", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, q Query) ([]Book, int, error) {
+			assert.Equal(t, "-sequel", q.Search)
+			assert.Equal(t, "dune", q.Title)
+			assert.Equal(t, "ace", q.PublisherMatch)
+			assert.Equal(t, 1981, *q.YearFrom)
+			assert.Nil(t, q.YearTo)
+			return []Book{testBook}, 1, nil
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?search="+url.QueryEscape(`title:"dune" publisher:ace -sequel year:>1980`), nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("search language from accept-language", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, q Query) ([]Book, int, error) {
+			assert.Equal(t, "fr-FR", q.SearchLanguage)
+			assert.Empty(t, q.Language)
+			return []Book{testBook}, 1, nil
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?search=miserables", nil)
+		r.Header.Set("Accept-Language", "fr-FR,fr;q=0.9,en;q=0.8")
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+	})
+
+	t.Run("search language from language filter", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, q Query) ([]Book, int, error) {
+			assert.Equal(t, "de", q.SearchLanguage)
+			return []Book{testBook}, 1, nil
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?search=kafka+lang:de", nil)
+		r.Header.Set("Accept-Language", "fr")
 
 		handler.List(w, r)
 
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
+
+	t.Run("malformed search", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?search="+url.QueryEscape(`title:"dune year:1980..1970`), nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+		assert.Contains(t, w.Body.String(), `"field":"search"`)
+		assert.Contains(t, w.Body.String(), "unterminated quote")
+	})
+
+	t.Run("unknown facet", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books?facets=genre,color", nil)
+
+		handler.List(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+	})
 }
 
 func TestHTTPHandler_GetByISBN(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, "test-secret")
 	handler := NewHTTPHandler(service)
 
 	testBook := Book{
 		ID:    "1",
-		ISBN:  "123",
+		ISBN:  "9780306406157",
 		Title: "Test",
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.EXPECT().GetByISBN(gomock.Any(), "123").Return(testBook, nil)
+		mockRepo.EXPECT().GetByISBN(gomock.Any(), "9780306406157").Return(testBook, nil)
 
 		w := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodGet, "/books/123", nil)
-		r.SetPathValue("isbn", "123")
+		r := httptest.NewRequest(http.MethodGet, "/books/9780306406157", nil)
+		r.SetPathValue("isbn", "9780306406157")
+
+		handler.GetByISBN(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("includes rating aggregates", func(t *testing.T) {
+		rated := testBook
+		rated.Rating = RatingSummary{Average: 4.5, Count: 2, Histogram: []int{0, 0, 0, 1, 1}}
+		mockRepo.EXPECT().GetByISBN(gomock.Any(), "9780306406157").Return(rated, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/9780306406157", nil)
+		r.SetPathValue("isbn", "9780306406157")
+
+		handler.GetByISBN(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"rating":{"average_rating":4.5,"ratings_count":2,"histogram":[0,0,0,1,1]}`)
+	})
+
+	t.Run("isbn-10 resolves to canonical isbn-13", func(t *testing.T) {
+		mockRepo.EXPECT().GetByISBN(gomock.Any(), "9780306406157").Return(testBook, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/0-306-40615-2", nil)
+		r.SetPathValue("isbn", "0-306-40615-2")
+
+		handler.GetByISBN(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("legacy isbn is looked up as is", func(t *testing.T) {
+		mockRepo.EXPECT().GetByISBN(gomock.Any(), "978-00000001").Return(Book{ISBN: "978-00000001", Title: "Legacy"}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/978-00000001", nil)
+		r.SetPathValue("isbn", "978-00000001")
 
 		handler.GetByISBN(w, r)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"title":"Legacy"`)
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		mockRepo.EXPECT().GetByISBN(gomock.Any(), "123").Return(Book{}, ErrNotFound)
+		mockRepo.EXPECT().GetByISBN(gomock.Any(), "9780306406157").Return(Book{}, ErrNotFound)
 
 		w := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodGet, "/books/123", nil)
-		r.SetPathValue("isbn", "123")
+		r := httptest.NewRequest(http.MethodGet, "/books/9780306406157", nil)
+		r.SetPathValue("isbn", "9780306406157")
 
 		handler.GetByISBN(w, r)
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 }
+
+func TestHTTPHandler_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, "test-secret")
+	handler := NewHTTPHandler(service)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, b *Book) error {
+				assert.Equal(t, "9780306406157", b.ISBN)
+				assert.Equal(t, "Dune", b.Title)
+				b.ID = "new-id"
+				return nil
+			})
+
+		body := `{"isbn":"9780306406157","title":"Dune","genre":"Science Fiction","publisher":"Ace"}`
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(body))
+
+		handler.Create(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":"new-id"`)
+	})
+
+	t.Run("invalid isbn", func(t *testing.T) {
+		body := `{"isbn":"abc","title":"Dune","genre":"Science Fiction","publisher":"Ace"}`
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(body))
+
+		handler.Create(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+	})
+
+	t.Run("missing title", func(t *testing.T) {
+		body := `{"isbn":"9780306406157","genre":"Science Fiction","publisher":"Ace"}`
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(body))
+
+		handler.Create(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("blank title", func(t *testing.T) {
+		body := `{"isbn":"9780306406157","title":"   ","genre":"Science Fiction","publisher":"Ace"}`
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(body))
+
+		handler.Create(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(ErrAlreadyExists)
+
+		body := `{"isbn":"9780306406157","title":"Dune","genre":"Science Fiction","publisher":"Ace"}`
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(body))
+
+		handler.Create(w, r)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestHTTPHandler_Patch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, "test-secret")
+	handler := NewHTTPHandler(service)
+
+	existing := Book{ID: "1", ISBN: "9780306406157", Title: "Dnue", Genre: "Fiction", Publisher: "Ace"}
+
+	t.Run("updates only provided fields", func(t *testing.T) {
+		mockRepo.EXPECT().GetByISBN(gomock.Any(), "9780306406157").Return(existing, nil)
+		mockRepo.EXPECT().Update(gomock.Any(), "9780306406157", gomock.Any()).DoAndReturn(
+			func(ctx context.Context, isbn string, b *Book) error {
+				assert.Equal(t, "Dune", b.Title)
+				assert.Equal(t, "Ace", b.Publisher)
+				return nil
+			})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/books/9780306406157", strings.NewReader(`{"title":"Dune"}`))
+		r.SetPathValue("isbn", "9780306406157")
+
+		handler.Patch(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("trims provided fields", func(t *testing.T) {
+		mockRepo.EXPECT().GetByISBN(gomock.Any(), "9780306406157").Return(existing, nil)
+		mockRepo.EXPECT().Update(gomock.Any(), "9780306406157", gomock.Any()).DoAndReturn(
+			func(ctx context.Context, isbn string, b *Book) error {
+				assert.Equal(t, "Dune", b.Title)
+				return nil
+			})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/books/9780306406157", strings.NewReader(`{"title":"  Dune "}`))
+		r.SetPathValue("isbn", "9780306406157")
+
+		handler.Patch(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("blank fields", func(t *testing.T) {
+		for _, body := range []string{`{"title":" "}`, `{"genre":"\t"}`, `{"publisher":""}`} {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, "/books/9780306406157", strings.NewReader(body))
+			r.SetPathValue("isbn", "9780306406157")
+
+			handler.Patch(w, r)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+			assert.Contains(t, w.Body.String(), "VALIDATION_ERROR", body)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetByISBN(gomock.Any(), "9780306406157").Return(Book{}, ErrNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/books/9780306406157", strings.NewReader(`{"title":"Dune"}`))
+		r.SetPathValue("isbn", "9780306406157")
+
+		handler.Patch(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHTTPHandler_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, "test-secret")
+	handler := NewHTTPHandler(service)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().Delete(gomock.Any(), "9780306406157").Return(nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/books/9780306406157", nil)
+		r.SetPathValue("isbn", "9780306406157")
+
+		handler.Delete(w, r)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("unknown legacy isbn", func(t *testing.T) {
+		mockRepo.EXPECT().Delete(gomock.Any(), "nope").Return(ErrNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/books/nope", nil)
+		r.SetPathValue("isbn", "nope")
+
+		handler.Delete(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHTTPHandler_Suggest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, "test-secret")
+	handler := NewHTTPHandler(service)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().Suggest(gomock.Any(), "dun", DefaultSuggestLimit).Return(
+			[]Suggestion{
+				{Type: SuggestionTitle, Text: "Dune", ISBN: "9780441172719", Score: 1.5},
+				{Type: SuggestionAuthor, Text: "Dunsany", AuthorKey: "OL2A", Score: 1.4},
+			}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/suggest?q=+dun+", nil)
+
+		handler.Suggest(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"text":"Dune"`)
+		assert.Contains(t, w.Body.String(), `"type":"author","text":"Dunsany","author_key":"OL2A"`)
+		assert.NotEmpty(t, w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("limit is capped", func(t *testing.T) {
+		mockRepo.EXPECT().Suggest(gomock.Any(), "dune", MaxSuggestLimit).Return([]Suggestion{}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/suggest?q=dune&limit=500", nil)
+
+		handler.Suggest(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("query too short", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/suggest?q=d", nil)
+
+		handler.Suggest(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHTTPHandler_TopRated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, "test-secret")
+	handler := NewHTTPHandler(service)
+
+	ranked := []RankedBook{
+		{Book: Book{ID: "1", ISBN: "123", Title: "Dune"}, Score: 4.7},
+		{Book: Book{ID: "2", ISBN: "456", Title: "Hyperion"}, Score: 4.5},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().TopRated(gomock.Any(), RankQuery{Genre: "science-fiction", Language: "en", Limit: MaxRankLimit}, gomock.Any()).
+			Return(ranked, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/top?genre=science-fiction&language=en&limit=1", nil)
+
+		handler.TopRated(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"score":4.7`)
+		assert.NotContains(t, w.Body.String(), "Hyperion")
+		assert.Contains(t, w.Body.String(), `"computed_at"`)
+		assert.NotEmpty(t, w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/top?limit=500", nil)
+
+		handler.TopRated(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"limit"`)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mockRepo.EXPECT().TopRated(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, context.DeadlineExceeded)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/top?language=fr", nil)
+
+		handler.TopRated(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestHTTPHandler_Trending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, "test-secret")
+	handler := NewHTTPHandler(service)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().Trending(gomock.Any(), RankQuery{Limit: MaxRankLimit}, DefaultRankingConfig()).
+			Return([]RankedBook{{Book: Book{ID: "1", ISBN: "123", Title: "Dune"}, Score: 2.5}}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/trending", nil)
+
+		handler.Trending(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"score":2.5`)
+		assert.Contains(t, w.Body.String(), `"limit":20`)
+	})
+}
+
+func TestHTTPHandler_Similar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, "test-secret")
+	handler := NewHTTPHandler(service)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().SimilarCandidates(gomock.Any(), "9780306406157").Return([]SimilarCandidate{
+			{Book: Book{ID: "1", Title: "Dune Messiah"}, SharedAuthors: []string{"Frank Herbert"}},
+			{Book: Book{ID: "2", Title: "Hyperion"}, SharedGenres: []string{"Science Fiction"}, GenreCount: 1, SourceGenres: 1},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/0-306-40615-2/similar?limit=1", nil)
+		r.SetPathValue("isbn", "0-306-40615-2")
+
+		handler.Similar(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"reason":"Also by Frank Herbert"`)
+		assert.NotContains(t, w.Body.String(), "Hyperion")
+		assert.Contains(t, w.Body.String(), `"limit":1`)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().SimilarCandidates(gomock.Any(), "9780306406157").Return(nil, ErrNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/9780306406157/similar", nil)
+		r.SetPathValue("isbn", "9780306406157")
+
+		handler.Similar(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("unknown legacy isbn", func(t *testing.T) {
+		mockRepo.EXPECT().SimilarCandidates(gomock.Any(), "123").Return(nil, ErrNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/123/similar", nil)
+		r.SetPathValue("isbn", "123")
+
+		handler.Similar(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/books/9780306406157/similar?limit=0", nil)
+		r.SetPathValue("isbn", "9780306406157")
+
+		handler.Similar(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"limit"`)
+	})
+}
@@ -0,0 +1,59 @@
+package book
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// LexiconRefresher rebuilds the book_lexicon dictionary in the background
+// after admin edits. Rebuilding reads every book, so it never runs inside a
+// request, and a burst of edits costs at most two rebuilds.
+type LexiconRefresher struct {
+	repo Repository
+
+	mu      sync.Mutex
+	running bool
+	pending bool
+	wg      sync.WaitGroup
+}
+
+func NewLexiconRefresher(repo Repository) *LexiconRefresher {
+	return &LexiconRefresher{repo: repo}
+}
+
+// Trigger starts a rebuild, or queues one to follow the rebuild already
+// running so that it sees the latest edit.
+func (l *LexiconRefresher) Trigger() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running {
+		l.pending = true
+		return
+	}
+	l.running = true
+	l.wg.Add(1)
+	go l.run()
+}
+
+// Wait blocks until no rebuild is running or queued.
+func (l *LexiconRefresher) Wait() {
+	l.wg.Wait()
+}
+
+func (l *LexiconRefresher) run() {
+	defer l.wg.Done()
+	for {
+		if err := l.repo.RefreshLexicon(context.Background()); err != nil {
+			log.Printf("Failed to refresh search lexicon: %v", err)
+		}
+		l.mu.Lock()
+		if !l.pending {
+			l.running = false
+			l.mu.Unlock()
+			return
+		}
+		l.pending = false
+		l.mu.Unlock()
+	}
+}
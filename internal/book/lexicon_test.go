@@ -0,0 +1,61 @@
+package book
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexiconRefresher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	refresher := NewLexiconRefresher(mockRepo)
+
+	t.Run("edits during a rebuild queue one more", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		first := mockRepo.EXPECT().RefreshLexicon(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+		mockRepo.EXPECT().RefreshLexicon(gomock.Any()).Return(nil).After(first)
+
+		refresher.Trigger()
+		<-started
+		refresher.Trigger()
+		refresher.Trigger()
+		close(release)
+		refresher.Wait()
+	})
+
+	t.Run("service refreshes after an update", func(t *testing.T) {
+		service := NewService(mockRepo, "test-secret").WithLexicon(refresher)
+		b := &Book{ISBN: "9780441172719", Title: "Dune"}
+		mockRepo.EXPECT().Update(gomock.Any(), b.ISBN, b).Return(nil)
+		mockRepo.EXPECT().RefreshLexicon(gomock.Any()).Return(nil)
+
+		assert.NoError(t, service.Replace(context.Background(), b.ISBN, b))
+		refresher.Wait()
+	})
+
+	t.Run("service refreshes after a delete", func(t *testing.T) {
+		service := NewService(mockRepo, "test-secret").WithLexicon(refresher)
+		mockRepo.EXPECT().Delete(gomock.Any(), "9780441172719").Return(nil)
+		mockRepo.EXPECT().RefreshLexicon(gomock.Any()).Return(nil)
+
+		assert.NoError(t, service.Delete(context.Background(), "9780441172719"))
+		refresher.Wait()
+	})
+
+	t.Run("failed create does not refresh", func(t *testing.T) {
+		service := NewService(mockRepo, "test-secret").WithLexicon(refresher)
+		mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(ErrAlreadyExists)
+
+		assert.Error(t, service.Create(context.Background(), &Book{}))
+		refresher.Wait()
+	})
+}
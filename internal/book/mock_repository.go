@@ -34,6 +34,64 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Corrections mocks base method.
+func (m *MockRepository) Corrections(ctx context.Context, search string, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Corrections", ctx, search, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Corrections indicates an expected call of Corrections.
+func (mr *MockRepositoryMockRecorder) Corrections(ctx, search, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Corrections", reflect.TypeOf((*MockRepository)(nil).Corrections), ctx, search, limit)
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(ctx context.Context, book *Book) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, book)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(ctx, book interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, book)
+}
+
+// Delete mocks base method.
+func (m *MockRepository) Delete(ctx context.Context, isbn string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, isbn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepositoryMockRecorder) Delete(ctx, isbn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, isbn)
+}
+
+// Facets mocks base method.
+func (m *MockRepository) Facets(ctx context.Context, q Query, facets []string) (map[string][]FacetBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Facets", ctx, q, facets)
+	ret0, _ := ret[0].(map[string][]FacetBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Facets indicates an expected call of Facets.
+func (mr *MockRepositoryMockRecorder) Facets(ctx, q, facets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Facets", reflect.TypeOf((*MockRepository)(nil).Facets), ctx, q, facets)
+}
+
 // GetByISBN mocks base method.
 func (m *MockRepository) GetByISBN(ctx context.Context, isbn string) (Book, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +123,94 @@ func (mr *MockRepositoryMockRecorder) List(ctx, q interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, q)
 }
 
+// RefreshLexicon mocks base method.
+func (m *MockRepository) RefreshLexicon(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshLexicon", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshLexicon indicates an expected call of RefreshLexicon.
+func (mr *MockRepositoryMockRecorder) RefreshLexicon(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshLexicon", reflect.TypeOf((*MockRepository)(nil).RefreshLexicon), ctx)
+}
+
+// SimilarCandidates mocks base method.
+func (m *MockRepository) SimilarCandidates(ctx context.Context, isbn string) ([]SimilarCandidate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimilarCandidates", ctx, isbn)
+	ret0, _ := ret[0].([]SimilarCandidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimilarCandidates indicates an expected call of SimilarCandidates.
+func (mr *MockRepositoryMockRecorder) SimilarCandidates(ctx, isbn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimilarCandidates", reflect.TypeOf((*MockRepository)(nil).SimilarCandidates), ctx, isbn)
+}
+
+// Suggest mocks base method.
+func (m *MockRepository) Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suggest", ctx, prefix, limit)
+	ret0, _ := ret[0].([]Suggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Suggest indicates an expected call of Suggest.
+func (mr *MockRepositoryMockRecorder) Suggest(ctx, prefix, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suggest", reflect.TypeOf((*MockRepository)(nil).Suggest), ctx, prefix, limit)
+}
+
+// TopRated mocks base method.
+func (m *MockRepository) TopRated(ctx context.Context, q RankQuery, cfg RankingConfig) ([]RankedBook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopRated", ctx, q, cfg)
+	ret0, _ := ret[0].([]RankedBook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopRated indicates an expected call of TopRated.
+func (mr *MockRepositoryMockRecorder) TopRated(ctx, q, cfg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopRated", reflect.TypeOf((*MockRepository)(nil).TopRated), ctx, q, cfg)
+}
+
+// Trending mocks base method.
+func (m *MockRepository) Trending(ctx context.Context, q RankQuery, cfg RankingConfig) ([]RankedBook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trending", ctx, q, cfg)
+	ret0, _ := ret[0].([]RankedBook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trending indicates an expected call of Trending.
+func (mr *MockRepositoryMockRecorder) Trending(ctx, q, cfg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trending", reflect.TypeOf((*MockRepository)(nil).Trending), ctx, q, cfg)
+}
+
+// Update mocks base method.
+func (m *MockRepository) Update(ctx context.Context, isbn string, book *Book) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, isbn, book)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRepositoryMockRecorder) Update(ctx, isbn, book interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, isbn, book)
+}
+
 // UpsertFromIngest mocks base method.
 func (m *MockRepository) UpsertFromIngest(ctx context.Context, book *Book) error {
 	m.ctrl.T.Helper()
@@ -7,6 +7,16 @@ import (
 // Repository defines the contract for book data storage.
 type Repository interface {
 	List(ctx context.Context, q Query) ([]Book, int, error)
+	Facets(ctx context.Context, q Query, facets []string) (map[string][]FacetBucket, error)
 	GetByISBN(ctx context.Context, isbn string) (Book, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error)
+	Corrections(ctx context.Context, search string, limit int) ([]string, error)
+	RefreshLexicon(ctx context.Context) error
+	TopRated(ctx context.Context, q RankQuery, cfg RankingConfig) ([]RankedBook, error)
+	Trending(ctx context.Context, q RankQuery, cfg RankingConfig) ([]RankedBook, error)
+	SimilarCandidates(ctx context.Context, isbn string) ([]SimilarCandidate, error)
 	UpsertFromIngest(ctx context.Context, book *Book) error
+	Create(ctx context.Context, book *Book) error
+	Update(ctx context.Context, isbn string, book *Book) error
+	Delete(ctx context.Context, isbn string) error
 }
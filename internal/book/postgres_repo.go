@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"bookapi/internal/platform/isbn"
+	"bookapi/internal/platform/textsearch"
+
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -25,49 +30,190 @@ func (r *PostgresRepo) withTimeout(ctx context.Context) (context.Context, contex
 }
 
 func (r *PostgresRepo) List(ctx context.Context, q Query) ([]Book, int, error) {
+	f := buildFilters(q, "")
+	args := f.args
+	argn := len(args) + 1
+	where := f.where()
+	searchArg := f.searchArg
+
+	sort := sortSpecFor(q, searchArg)
+
+	order := "ASC"
+	if q.Desc || q.Sort == "relevance" || q.Sort == "rating" {
+		order = "DESC"
+	}
+
+	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM books b %s", where)
+	var total int
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	if err := r.db.QueryRow(timeoutCtx, countSQL, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Cursor-based pagination: continue after the (sort key, id) of the last
+	// row of the previous page. A bare after_id looks the key up instead.
+	pageWhere := where
+	pageArgs := append([]any{}, args...)
+	if q.After != nil || q.AfterID != "" {
+		var key, id string
+		if q.After != nil {
+			key = fmt.Sprintf("CAST($%d::text AS %s)", argn, sort.keyType)
+			id = fmt.Sprintf("$%d::uuid", argn+1)
+			pageArgs = append(pageArgs, q.After.Key, q.After.AfterID)
+			argn += 2
+		} else {
+			id = fmt.Sprintf("$%d::uuid", argn)
+			key = fmt.Sprintf("(SELECT %s FROM books b WHERE b.id = %s)", sort.expr, id)
+			pageArgs = append(pageArgs, q.AfterID)
+			argn++
+		}
+		pageWhere += " AND " + keysetPredicate(sort, order, key, id)
+	}
+
+	// Highlights are only computed for a full-text search, and only for
+	// the rows of the page.
+	highlightCols := "NULL::text, NULL::text"
+	if searchArg > 0 {
+		highlightCols = fmt.Sprintf(`
+		       ts_headline('%[4]s', b.title, websearch_to_tsquery('%[4]s', $%[1]d), $%[2]d),
+		       ts_headline('%[4]s', COALESCE(b.description, ''), websearch_to_tsquery('%[4]s', $%[1]d), $%[3]d)`,
+			searchArg, argn, argn+1, textsearch.Config(q.SearchLanguage))
+		pageArgs = append(pageArgs, q.Highlight.TitleOptions(), q.Highlight.SnippetOptions())
+		argn += 2
+	}
+
+	dataSQL := fmt.Sprintf(`
+		SELECT b.id, b.isbn, b.title, b.subtitle, b.genre, b.publisher, b.description, 
+		       b.published_date, b.publication_year, b.page_count, b.language, b.cover_url,
+		       b.work_id, bser.id, bser.name, bser.position, bauth.authors, bgen.genres,
+		       `+ratingColumnsSQL+`, b.created_at, b.updated_at, (%s)::text, %s
+		FROM books b
+		`+seriesJoinSQL+`
+		`+authorsJoinSQL+`
+		`+genresJoinSQL+`
+		%s
+		ORDER BY %s %s NULLS LAST, b.id %s
+		LIMIT $%d OFFSET $%d`,
+		sort.expr, highlightCols, pageWhere, sort.expr, order, order, argn, argn+1)
+
+	pageArgs = append(pageArgs, q.Limit, q.Offset)
+	timeoutCtx2, cancel2 := r.withTimeout(ctx)
+	defer cancel2()
+	rows, err := r.db.Query(timeoutCtx2, dataSQL, pageArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var out []Book
+	for rows.Next() {
+		var b Book
+		var ser seriesScan
+		var titleHL, descHL *string
+		if err := rows.Scan(
+			&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
+			&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
+			&b.WorkID, &ser.id, &ser.name, &ser.position, &b.Authors, &b.Genres,
+			&b.Rating.Average, &b.Rating.Count, &b.Rating.Histogram,
+			&b.CreatedAt, &b.UpdatedAt, &b.sortKey, &titleHL, &descHL,
+		); err != nil {
+			return nil, 0, err
+		}
+		b.Series = ser.ref()
+		if titleHL != nil {
+			b.Highlights = &textsearch.Highlights{Title: *titleHL}
+			if descHL != nil {
+				b.Highlights.Description = *descHL
+			}
+		}
+		out = append(out, b)
+	}
+	return out, total, rows.Err()
+}
+
+// filterSet holds the WHERE clauses and arguments derived from a Query.
+type filterSet struct {
+	clauses   []string
+	args      []any
+	searchArg int // placeholder index of the search term, 0 if none
+}
+
+func (f filterSet) where() string {
+	return "WHERE " + strings.Join(f.clauses, " AND ")
+}
+
+// buildFilters translates the query filters into SQL. The filter owned by the
+// facet named by exclude is skipped, so a facet's counts ignore its own selection.
+func buildFilters(q Query, exclude string) filterSet {
 	clauses := []string{"1=1"}
 	args := []any{}
 	argn := 1
 
-	if q.Genre != "" {
-		clauses = append(clauses, fmt.Sprintf("genre = $%d", argn))
-		args = append(args, q.Genre)
-		argn++
-	}
-
-	if len(q.Genres) > 0 {
-		clauses = append(clauses, fmt.Sprintf("genre = ANY($%d)", argn))
-		args = append(args, q.Genres)
+	if slugs := genreSlugs(q); len(slugs) > 0 && exclude != FacetGenre {
+		// Selecting a genre selects its whole subtree.
+		clauses = append(clauses, fmt.Sprintf(`EXISTS (
+				SELECT 1 FROM book_genres bg
+				JOIN genre_closure gc ON gc.descendant = bg.genre_slug
+				WHERE bg.book_id = b.id AND gc.ancestor = ANY($%d))`, argn))
+		args = append(args, slugs)
 		argn++
 	}
 
-	if q.Publisher != "" {
+	if q.Publisher != "" && exclude != FacetPublisher {
 		clauses = append(clauses, fmt.Sprintf("publisher = $%d", argn))
 		args = append(args, q.Publisher)
 		argn++
 	}
 
-	if q.Language != "" {
+	if q.Language != "" && exclude != FacetLanguage {
 		clauses = append(clauses, fmt.Sprintf("language = $%d", argn))
 		args = append(args, q.Language)
 		argn++
 	}
 
-	if q.YearFrom != nil {
-		clauses = append(clauses, fmt.Sprintf("publication_year >= $%d", argn))
-		args = append(args, *q.YearFrom)
+	if exclude != FacetDecade {
+		if q.YearFrom != nil {
+			clauses = append(clauses, fmt.Sprintf("publication_year >= $%d", argn))
+			args = append(args, *q.YearFrom)
+			argn++
+		}
+
+		if q.YearTo != nil {
+			clauses = append(clauses, fmt.Sprintf("publication_year <= $%d", argn))
+			args = append(args, *q.YearTo)
+			argn++
+		}
+	}
+
+	cfg := textsearch.Config(q.SearchLanguage)
+
+	// Field qualifiers of an advanced search.
+	if q.Title != "" {
+		clauses = append(clauses, fmt.Sprintf("to_tsvector('%[1]s', title) @@ phraseto_tsquery('%[1]s', $%[2]d)", cfg, argn))
+		args = append(args, q.Title)
 		argn++
 	}
 
-	if q.YearTo != nil {
-		clauses = append(clauses, fmt.Sprintf("publication_year <= $%d", argn))
-		args = append(args, *q.YearTo)
+	if q.Author != "" {
+		clauses = append(clauses, fmt.Sprintf(`EXISTS (
+				SELECT 1 FROM book_authors ba
+				WHERE ba.book_id = b.id AND to_tsvector('%[1]s', ba.name) @@ phraseto_tsquery('%[1]s', $%[2]d))`, textsearch.SimpleConfig, argn))
+		args = append(args, q.Author)
 		argn++
 	}
 
+	if q.PublisherMatch != "" {
+		clauses = append(clauses, fmt.Sprintf("publisher ILIKE $%d", argn))
+		args = append(args, likeContains(q.PublisherMatch))
+		argn++
+	}
+
+	searchArg := 0
 	if q.Search != "" {
-		clauses = append(clauses, fmt.Sprintf("search_vector @@ plainto_tsquery('english', $%d)", argn))
+		clauses = append(clauses, fmt.Sprintf("search_vector @@ websearch_to_tsquery('%s', $%d)", cfg, argn))
 		args = append(args, q.Search)
+		searchArg = argn
 		argn++
 	} else if q.Q != "" {
 		clauses = append(clauses, fmt.Sprintf("(isbn ILIKE $%d OR title ILIKE $%d OR publisher ILIKE $%d OR description ILIKE $%d OR genre ILIKE $%d)", argn, argn+1, argn+2, argn+3, argn+4))
@@ -76,156 +222,633 @@ func (r *PostgresRepo) List(ctx context.Context, q Query) ([]Book, int, error) {
 		argn += 5
 	}
 
-	where := "WHERE " + strings.Join(clauses, " AND ")
-
-	ratingJoin := ""
-	if q.MinRating != nil {
-		ratingJoin = "JOIN (SELECT book_id, AVG(star) as avg_star FROM ratings GROUP BY book_id) r_stats ON r_stats.book_id = b.id"
-		where += fmt.Sprintf(" AND r_stats.avg_star >= $%d", argn)
+	if q.MinRating != nil && exclude != FacetRating {
+		clauses = append(clauses, fmt.Sprintf("b.rating_count > 0 AND b.rating_avg >= $%d", argn))
 		args = append(args, *q.MinRating)
-		argn++
 	}
 
-	sortCol := "b.title"
+	return filterSet{clauses: clauses, args: args, searchArg: searchArg}
+}
+
+// genreSlugs merges the single genre filter into the multi-genre one.
+func genreSlugs(q Query) []string {
+	slugs := q.Genres
+	if slug := GenreSlug(q.Genre); slug != "" {
+		slugs = append([]string{slug}, slugs...)
+	}
+	return slugs
+}
+
+// ratingColumnsSQL selects the rating aggregates kept on books b, scanned
+// into Book.Rating.
+const ratingColumnsSQL = "b.rating_avg, b.rating_count, b.rating_histogram"
+
+// sortSpec describes the ORDER BY expression for a sort option.
+type sortSpec struct {
+	expr     string // SQL expression over books b
+	keyType  string // SQL type a cursor key is cast back to
+	nullable bool   // whether expr can be NULL; NULLs always sort last
+}
+
+func sortSpecFor(q Query, searchArg int) sortSpec {
 	if q.Search != "" && q.Sort == "relevance" {
-		sortCol = fmt.Sprintf("ts_rank(b.search_vector, plainto_tsquery('english', $%d))", argn-1)
-	} else {
-		switch q.Sort {
-		case "created_at":
-			sortCol = "b.created_at"
-		case "rating":
-			if ratingJoin == "" {
-				ratingJoin = "LEFT JOIN (SELECT book_id, AVG(star) as avg_star FROM ratings GROUP BY book_id) r_stats ON r_stats.book_id = b.id"
-			}
-			sortCol = "COALESCE(r_stats.avg_star, 0)"
-		case "year":
-			sortCol = "b.publication_year"
-		default:
-			sortCol = "b.title"
+		return sortSpec{
+			expr:    fmt.Sprintf("ts_rank(b.search_vector, websearch_to_tsquery('%s', $%d))", textsearch.Config(q.SearchLanguage), searchArg),
+			keyType: "real",
 		}
 	}
+	switch q.Sort {
+	case "created_at":
+		return sortSpec{expr: "b.created_at", keyType: "timestamptz"}
+	case "rating":
+		return sortSpec{expr: "b.rating_avg", keyType: "float8"}
+	case "year":
+		return sortSpec{expr: "b.publication_year", keyType: "int", nullable: true}
+	default:
+		return sortSpec{expr: "b.title", keyType: "text"}
+	}
+}
 
-	order := "ASC"
-	if q.Desc || q.Sort == "relevance" || q.Sort == "rating" {
-		order = "DESC"
+// keysetPredicate returns a condition matching rows that sort strictly after
+// the row identified by (key, id) under ORDER BY expr order NULLS LAST, b.id order.
+func keysetPredicate(sort sortSpec, order, key, id string) string {
+	op := ">"
+	if order == "DESC" {
+		op = "<"
 	}
+	if !sort.nullable {
+		return fmt.Sprintf("(%s, b.id) %s (%s, %s)", sort.expr, op, key, id)
+	}
+	// A NULL key is followed only by other NULL rows with a later id; a non-NULL
+	// key is followed by later keys, ties with a later id, and all NULL rows.
+	return fmt.Sprintf(
+		"((%[3]s IS NULL AND %[1]s IS NULL AND b.id %[2]s %[4]s) OR "+
+			"(%[3]s IS NOT NULL AND (%[1]s IS NULL OR %[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND b.id %[2]s %[4]s))))",
+		sort.expr, op, key, id)
+}
+
+// facetLimit caps the number of buckets returned per facet.
+const facetLimit = 20
 
-	// Cursor-based pagination
-	// If cursor or after_id is provided, use cursor-based pagination instead of offset
-	useCursor := q.Cursor != "" || q.AfterID != ""
+func (r *PostgresRepo) Facets(ctx context.Context, q Query, facets []string) (map[string][]FacetBucket, error) {
+	out := make(map[string][]FacetBucket, len(facets))
+	for _, facet := range facets {
+		f := buildFilters(q, facet)
+
+		var sql string
+		switch facet {
+		case FacetRating:
+			// Rating buckets are cumulative, matching min_rating semantics:
+			// bucket "4" counts books averaging 4 stars or more.
+			sql = fmt.Sprintf(`
+				SELECT t::text, COUNT(*)
+				FROM books b
+				JOIN generate_series(1, 5) t ON b.rating_count > 0 AND b.rating_avg >= t
+				%s
+				GROUP BY t
+				ORDER BY t DESC`,
+				f.where())
+		case FacetGenre:
+			// A book counts once towards each of its genres and their
+			// ancestors, matching the subtree semantics of the filter.
+			sql = fmt.Sprintf(`
+				SELECT bg.genre_slug, COUNT(*)
+				FROM books b
+				JOIN LATERAL (
+					SELECT DISTINCT gc.ancestor AS genre_slug
+					FROM book_genres g
+					JOIN genre_closure gc ON gc.descendant = g.genre_slug
+					WHERE g.book_id = b.id
+				) bg ON true
+				%s
+				GROUP BY 1
+				ORDER BY 2 DESC, 1 ASC
+				LIMIT %d`,
+				f.where(), facetLimit)
+		default:
+			expr := facetExpr[facet]
+			sql = fmt.Sprintf(`
+				SELECT (%[1]s)::text, COUNT(*)
+				FROM books b
+				%[2]s AND %[1]s IS NOT NULL
+				GROUP BY 1
+				ORDER BY 2 DESC, 1 ASC
+				LIMIT %[3]d`,
+				expr, f.where(), facetLimit)
+		}
 
-	// Decode cursor if provided
-	var cursorAfterID string
-	if q.Cursor != "" {
-		cursorData, err := DecodeCursor(q.Cursor)
-		if err == nil && cursorData.AfterID != "" {
-			cursorAfterID = cursorData.AfterID
+		buckets, err := r.queryFacet(ctx, sql, f.args)
+		if err != nil {
+			return nil, fmt.Errorf("facet %s: %w", facet, err)
 		}
+		out[facet] = buckets
 	}
-	if q.AfterID != "" {
-		cursorAfterID = q.AfterID
+	return out, nil
+}
+
+func (r *PostgresRepo) queryFacet(ctx context.Context, sql string, args []any) ([]FacetBucket, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, sql, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	// Add cursor condition if provided
-	if cursorAfterID != "" && sortCol == "b.created_at" {
-		// For created_at sort, we can use the ID as cursor
-		if order == "ASC" {
-			where += fmt.Sprintf(" AND (b.created_at, b.id) > (SELECT created_at, id FROM books WHERE id = $%d)", argn)
-			args = append(args, cursorAfterID)
-			argn++
-		} else {
-			where += fmt.Sprintf(" AND (b.created_at, b.id) < (SELECT created_at, id FROM books WHERE id = $%d)", argn)
-			args = append(args, cursorAfterID)
-			argn++
-		}
-	} else if cursorAfterID != "" {
-		// Fallback: use id comparison for other sort columns
-		if order == "ASC" {
-			where += fmt.Sprintf(" AND b.id > $%d", argn)
-		} else {
-			where += fmt.Sprintf(" AND b.id < $%d", argn)
+	buckets := []FacetBucket{}
+	for rows.Next() {
+		var b FacetBucket
+		if err := rows.Scan(&b.Value, &b.Count); err != nil {
+			return nil, err
 		}
-		args = append(args, cursorAfterID)
-		argn++
+		buckets = append(buckets, b)
 	}
+	return buckets, rows.Err()
+}
 
-	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM books b %s %s", ratingJoin, where)
-	var total int
+// facetExpr maps value facets to the column expression they group by.
+var facetExpr = map[string]string{
+	FacetLanguage:  "b.language",
+	FacetPublisher: "b.publisher",
+	FacetDecade:    "(b.publication_year / 10) * 10",
+}
+
+func (r *PostgresRepo) GetByISBN(ctx context.Context, isbn string) (Book, error) {
+	const query = `
+		SELECT b.id, b.isbn, b.title, b.subtitle, b.genre, b.publisher, b.description, 
+		       b.published_date, b.publication_year, b.page_count, b.language, b.cover_url,
+		       b.work_id, bser.id, bser.name, bser.position, bauth.authors, bgen.genres,
+		       ` + ratingColumnsSQL + `, b.created_at, b.updated_at
+		FROM books b
+		` + seriesJoinSQL + `
+		` + authorsJoinSQL + `
+		` + genresJoinSQL + `
+		WHERE b.id = book_id_for_isbn($1)
+	`
+	var b Book
+	var ser seriesScan
 	timeoutCtx, cancel := r.withTimeout(ctx)
 	defer cancel()
-	if err := r.db.QueryRow(timeoutCtx, countSQL, args...).Scan(&total); err != nil {
-		return nil, 0, err
+	err := r.db.QueryRow(timeoutCtx, query, isbn).Scan(
+		&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
+		&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
+		&b.WorkID, &ser.id, &ser.name, &ser.position, &b.Authors, &b.Genres,
+		&b.Rating.Average, &b.Rating.Count, &b.Rating.Histogram,
+		&b.CreatedAt, &b.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Book{}, ErrNotFound
+		}
+		return Book{}, err
 	}
+	b.Series = ser.ref()
+	return b, nil
+}
 
-	dataSQL := fmt.Sprintf(`
-		SELECT b.id, b.isbn, b.title, b.subtitle, b.genre, b.publisher, b.description, 
+// seriesJoinSQL attaches the book's series membership as bser. A book in
+// several series reports the first by name.
+const seriesJoinSQL = `LEFT JOIN LATERAL (
+			SELECT s.id::text AS id, s.name, bs.position::float8 AS position
+			FROM book_series bs
+			JOIN series s ON s.id = bs.series_id
+			WHERE bs.book_id = b.id
+			ORDER BY s.name
+			LIMIT 1
+		) bser ON true`
+
+// authorsJoinSQL attaches the book's credits as a JSON array in
+// bauth.authors, NULL when none are recorded.
+const authorsJoinSQL = `LEFT JOIN LATERAL (
+			SELECT json_agg(json_build_object('key', ba.author_key, 'name', ba.name, 'role', ba.role)
+			                ORDER BY ba.position, ba.role) AS authors
+			FROM book_authors ba
+			WHERE ba.book_id = b.id
+		) bauth ON true`
+
+// genresJoinSQL attaches the slugs of the book's genres as bgen.genres,
+// NULL when it has none.
+const genresJoinSQL = `LEFT JOIN LATERAL (
+			SELECT array_agg(g.genre_slug ORDER BY g.genre_slug) AS genres
+			FROM book_genres g
+			WHERE g.book_id = b.id
+		) bgen ON true`
+
+// seriesScan receives the nullable bser columns.
+type seriesScan struct {
+	id       *string
+	name     *string
+	position *float64
+}
+
+func (s seriesScan) ref() *SeriesRef {
+	if s.id == nil || s.name == nil {
+		return nil
+	}
+	return &SeriesRef{ID: *s.id, Name: *s.name, Position: s.position}
+}
+
+// rankedSelectSQL selects the columns scanned by scanRanked; the score
+// expression is appended by the caller.
+const rankedSelectSQL = `
+		SELECT b.id, b.isbn, b.title, b.subtitle, b.genre, b.publisher, b.description,
 		       b.published_date, b.publication_year, b.page_count, b.language, b.cover_url,
-		       b.created_at, b.updated_at
+		       b.work_id, bser.id, bser.name, bser.position, bauth.authors, bgen.genres,
+		       ` + ratingColumnsSQL + `, b.created_at, b.updated_at`
+
+func (r *PostgresRepo) TopRated(ctx context.Context, q RankQuery, cfg RankingConfig) ([]RankedBook, error) {
+	f := buildFilters(Query{Genre: q.Genre, Language: q.Language}, "")
+	argn := len(f.args) + 1
+
+	// Bayesian average: every book starts with PriorWeight ratings at the
+	// prior mean, which defaults to the mean over all ratings.
+	query := fmt.Sprintf(rankedSelectSQL+`,
+		       ($%[1]d::float8 * m.mean + b.rating_count * b.rating_avg) / ($%[1]d::float8 + b.rating_count) AS score
 		FROM books b
-		%s
-		%s
-		ORDER BY %s %s
-		LIMIT $%d OFFSET $%d`,
-		ratingJoin, where, sortCol, order, argn, argn+1)
+		CROSS JOIN (
+			SELECT COALESCE($%[2]d::float8, SUM(rating_avg * rating_count) / NULLIF(SUM(rating_count), 0), 0) AS mean
+			FROM books
+		) m
+		`+seriesJoinSQL+`
+		`+authorsJoinSQL+`
+		`+genresJoinSQL+`
+		%[3]s AND b.rating_count > 0
+		ORDER BY score DESC, b.rating_count DESC, b.id
+		LIMIT $%[4]d`,
+		argn, argn+1, f.where(), argn+2)
+	args := append(f.args, float64(cfg.PriorWeight), cfg.PriorMean, q.Limit)
 
-	argsWithPage := append([]any{}, args...)
-	argsWithPage = append(argsWithPage, q.Limit, q.Offset)
-	timeoutCtx2, cancel2 := r.withTimeout(ctx)
-	defer cancel2()
-	rows, err := r.db.Query(timeoutCtx2, dataSQL, argsWithPage...)
+	return r.queryRanked(ctx, query, args)
+}
+
+func (r *PostgresRepo) Trending(ctx context.Context, q RankQuery, cfg RankingConfig) ([]RankedBook, error) {
+	f := buildFilters(Query{Genre: q.Genre, Language: q.Language}, "")
+	argn := len(f.args) + 1
+
+	// Each rating or reading list change in the window scores
+	// 2^(-age / half-life), so recent activity counts the most.
+	query := fmt.Sprintf(`
+		WITH activity AS (
+			SELECT book_id, updated_at FROM ratings
+			WHERE updated_at > now() - make_interval(secs => $%[1]d)
+			UNION ALL
+			SELECT book_id, updated_at FROM user_books
+			WHERE updated_at > now() - make_interval(secs => $%[1]d)
+		), scores AS (
+			SELECT book_id, SUM(power(2, -extract(epoch FROM now() - updated_at) / $%[2]d))::float8 AS score
+			FROM activity
+			GROUP BY book_id
+		)`+rankedSelectSQL+`, s.score
+		FROM scores s
+		JOIN books b ON b.id = s.book_id
+		`+seriesJoinSQL+`
+		`+authorsJoinSQL+`
+		`+genresJoinSQL+`
+		%[3]s
+		ORDER BY s.score DESC, b.id
+		LIMIT $%[4]d`,
+		argn, argn+1, f.where(), argn+2)
+	args := append(f.args, cfg.TrendingWindow.Seconds(), cfg.TrendingHalfLife.Seconds(), q.Limit)
+
+	return r.queryRanked(ctx, query, args)
+}
+
+func (r *PostgresRepo) queryRanked(ctx context.Context, query string, args []any) ([]RankedBook, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	var out []Book
+	out := []RankedBook{}
 	for rows.Next() {
-		var b Book
+		var rb RankedBook
+		b := &rb.Book
+		var ser seriesScan
 		if err := rows.Scan(
 			&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
 			&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
+			&b.WorkID, &ser.id, &ser.name, &ser.position, &b.Authors, &b.Genres,
+			&b.Rating.Average, &b.Rating.Count, &b.Rating.Histogram,
+			&b.CreatedAt, &b.UpdatedAt, &rb.Score,
+		); err != nil {
+			return nil, err
+		}
+		b.Series = ser.ref()
+		out = append(out, rb)
+	}
+	return out, rows.Err()
+}
+
+// similarCandidateLimit bounds the candidates each similarity signal
+// contributes before scoring.
+const similarCandidateLimit = 200
+
+// similarMinStar is the lowest rating counted as a reader enjoying a book.
+const similarMinStar = 4
+
+// SimilarCandidates collects the books sharing an author, subject or genre
+// with the book, matching the words of its title, authors and genre, or
+// shelved or rated highly by its readers. Editions of the same work are
+// excluded. The signals are returned raw and scored by the service.
+func (r *PostgresRepo) SimilarCandidates(ctx context.Context, isbn string) ([]SimilarCandidate, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var id string
+	err := r.db.QueryRow(timeoutCtx, "SELECT id FROM books WHERE id = book_id_for_isbn($1)", isbn).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	// The source's A and B weighted lexemes are OR-ed into a tsquery. Each
+	// is quoted, with backslashes and quotes escaped, so it is taken as is.
+	query := `
+		WITH src AS (
+			SELECT b.id, b.work_id,
+			       (SELECT string_agg('''' || replace(replace(lexeme, '\', '\\'), '''', '\''') || '''', ' | ')
+			        FROM unnest(ts_filter(b.search_vector, '{a,b}'))) AS terms,
+			       (SELECT COUNT(*) FROM book_subjects WHERE book_id = b.id) AS subjects,
+			       (SELECT COUNT(*) FROM book_genres WHERE book_id = b.id) AS genres,
+			       (SELECT COUNT(*) FROM (
+			           SELECT user_id FROM user_books WHERE book_id = b.id
+			           UNION
+			           SELECT user_id FROM ratings WHERE book_id = b.id AND star >= $2
+			       ) u) AS readers
+			FROM books b
+			WHERE b.id = $1
+		), fans AS (
+			SELECT user_id FROM user_books WHERE book_id = $1
+			UNION
+			SELECT user_id FROM ratings WHERE book_id = $1 AND star >= $2
+		), shared_authors AS (
+			SELECT c.book_id, array_agg(DISTINCT c.name ORDER BY c.name) AS names
+			FROM book_authors s
+			JOIN book_authors c ON c.author_key = s.author_key AND c.book_id <> s.book_id
+			WHERE s.book_id = $1 AND s.role = 'author' AND c.role = 'author'
+			GROUP BY c.book_id
+			ORDER BY COUNT(DISTINCT c.author_key) DESC
+			LIMIT $3
+		), shared_subjects AS (
+			SELECT c.book_id, array_agg(sub.name ORDER BY s.position) AS names
+			FROM book_subjects s
+			JOIN book_subjects c ON c.subject_id = s.subject_id AND c.book_id <> s.book_id
+			JOIN subjects sub ON sub.id = s.subject_id
+			WHERE s.book_id = $1
+			GROUP BY c.book_id
+			ORDER BY COUNT(*) DESC
+			LIMIT $3
+		), shared_genres AS (
+			SELECT c.book_id, array_agg(gn.name ORDER BY gn.name) AS names
+			FROM book_genres s
+			JOIN book_genres c ON c.genre_slug = s.genre_slug AND c.book_id <> s.book_id
+			JOIN genres gn ON gn.slug = s.genre_slug
+			WHERE s.book_id = $1
+			GROUP BY c.book_id
+			ORDER BY COUNT(*) DESC
+			LIMIT $3
+		), text_matches AS (
+			SELECT b.id AS book_id, ts_rank(b.search_vector, q.q)::float8 AS rank
+			FROM (SELECT terms::tsquery AS q FROM src WHERE terms IS NOT NULL) q
+			JOIN books b ON b.search_vector @@ q.q
+			WHERE b.id <> $1
+			ORDER BY rank DESC
+			LIMIT $3
+		), co_readers AS (
+			SELECT x.book_id, COUNT(DISTINCT x.user_id) AS readers
+			FROM (
+				SELECT ub.user_id, ub.book_id FROM user_books ub JOIN fans f ON f.user_id = ub.user_id
+				UNION ALL
+				SELECT rt.user_id, rt.book_id FROM ratings rt JOIN fans f ON f.user_id = rt.user_id
+				WHERE rt.star >= $2
+			) x
+			WHERE x.book_id <> $1
+			GROUP BY x.book_id
+			ORDER BY readers DESC
+			LIMIT $3
+		), candidates AS (
+			SELECT book_id FROM shared_authors
+			UNION SELECT book_id FROM shared_subjects
+			UNION SELECT book_id FROM shared_genres
+			UNION SELECT book_id FROM text_matches
+			UNION SELECT book_id FROM co_readers
+		)` + rankedSelectSQL + `,
+		       COALESCE(sa.names, '{}'), COALESCE(ss.names, '{}'),
+		       (SELECT COUNT(*) FROM book_subjects WHERE book_id = b.id), src.subjects,
+		       COALESCE(sg.names, '{}'),
+		       (SELECT COUNT(*) FROM book_genres WHERE book_id = b.id), src.genres,
+		       COALESCE(tm.rank, 0),
+		       COALESCE(cr.readers, 0),
+		       CASE WHEN cr.readers IS NULL THEN 0 ELSE (SELECT COUNT(*) FROM (
+		           SELECT user_id FROM user_books WHERE book_id = b.id
+		           UNION
+		           SELECT user_id FROM ratings WHERE book_id = b.id AND star >= $2
+		       ) u) END,
+		       src.readers
+		FROM candidates c
+		JOIN books b ON b.id = c.book_id
+		CROSS JOIN src
+		LEFT JOIN shared_authors sa ON sa.book_id = b.id
+		LEFT JOIN shared_subjects ss ON ss.book_id = b.id
+		LEFT JOIN shared_genres sg ON sg.book_id = b.id
+		LEFT JOIN text_matches tm ON tm.book_id = b.id
+		LEFT JOIN co_readers cr ON cr.book_id = b.id
+		` + seriesJoinSQL + `
+		` + authorsJoinSQL + `
+		` + genresJoinSQL + `
+		WHERE src.work_id IS NULL OR b.work_id IS DISTINCT FROM src.work_id`
+
+	rows, err := r.db.Query(timeoutCtx, query, id, similarMinStar, similarCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []SimilarCandidate{}
+	for rows.Next() {
+		var c SimilarCandidate
+		b := &c.Book
+		var ser seriesScan
+		if err := rows.Scan(
+			&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
+			&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
+			&b.WorkID, &ser.id, &ser.name, &ser.position, &b.Authors, &b.Genres,
+			&b.Rating.Average, &b.Rating.Count, &b.Rating.Histogram,
 			&b.CreatedAt, &b.UpdatedAt,
+			&c.SharedAuthors, &c.SharedSubjects, &c.SubjectCount, &c.SourceSubjects,
+			&c.SharedGenres, &c.GenreCount, &c.SourceGenres,
+			&c.TextRank, &c.CoReaders, &c.Readers, &c.SourceReaders,
 		); err != nil {
-			return nil, 0, err
+			return nil, err
 		}
-		out = append(out, b)
+		b.Series = ser.ref()
+		out = append(out, c)
 	}
-	return out, total, rows.Err()
+	return out, rows.Err()
 }
 
-func (r *PostgresRepo) GetByISBN(ctx context.Context, isbn string) (Book, error) {
+// Suggest ranks titles and author names by trigram word similarity to
+// prefix, boosting those that start with it. The predicates are served by
+// books_title_trgm_idx and book_authors_name_trgm_idx. Each side is capped
+// at its best suggestCandidates matches before editions sharing a title, and
+// books sharing an author, collapse into one suggestion, so a short prefix
+// never sorts every match.
+func (r *PostgresRepo) Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error) {
 	const query = `
-		SELECT id, isbn, title, subtitle, genre, publisher, description, 
-		       published_date, publication_year, page_count, language, cover_url,
-		       created_at, updated_at
-		FROM books
-		WHERE isbn = $1
-		LIMIT 1
+		WITH titles AS (
+			SELECT isbn, title,
+			       word_similarity($1, title)
+			         + CASE WHEN title ILIKE $2 THEN 1 ELSE 0 END AS score
+			FROM books
+			WHERE title ILIKE $2 OR $1 <% title
+			ORDER BY score DESC
+			LIMIT $4
+		), authors AS (
+			SELECT author_key, name,
+			       word_similarity($1, name)
+			         + CASE WHEN name ILIKE $2 THEN 1 ELSE 0 END AS score
+			FROM book_authors
+			WHERE name ILIKE $2 OR $1 <% name
+			ORDER BY score DESC
+			LIMIT $4
+		)
+		SELECT type, key, text, score
+		FROM (
+			(SELECT DISTINCT ON (lower(title)) 'title' AS type, isbn AS key, title AS text, score
+			 FROM titles
+			 ORDER BY lower(title), score DESC, isbn)
+			UNION ALL
+			(SELECT DISTINCT ON (author_key) 'author', author_key, name, score
+			 FROM authors
+			 ORDER BY author_key, score DESC)
+		) s
+		ORDER BY score DESC, length(text), text
+		LIMIT $3
 	`
-	var b Book
 	timeoutCtx, cancel := r.withTimeout(ctx)
 	defer cancel()
-	err := r.db.QueryRow(timeoutCtx, query, isbn).Scan(
-		&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
-		&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
-		&b.CreatedAt, &b.UpdatedAt,
-	)
+	rows, err := r.db.Query(timeoutCtx, query, prefix, likePrefix(prefix), limit, limit*suggestCandidates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	suggestions := []Suggestion{}
+	for rows.Next() {
+		var s Suggestion
+		var key string
+		if err := rows.Scan(&s.Type, &key, &s.Text, &s.Score); err != nil {
+			return nil, err
+		}
+		if s.Type == SuggestionAuthor {
+			s.AuthorKey = key
+		} else {
+			s.ISBN = key
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
+
+// Corrections suggests alternative queries for a search that matched nothing.
+// The first candidate replaces each word with its closest entry in the
+// book_lexicon dictionary; the rest are whole titles similar to the search.
+func (r *PostgresRepo) Corrections(ctx context.Context, search string, limit int) ([]string, error) {
+	words := searchWords(search)
+	if len(words) == 0 {
+		return []string{}, nil
+	}
+	original := strings.Join(words, " ")
+	suggestions := []string{}
+
+	const wordsQuery = `
+		SELECT COALESCE(m.word, w.word)
+		FROM unnest($1::text[]) WITH ORDINALITY AS w(word, pos)
+		LEFT JOIN LATERAL (
+			SELECT l.word
+			FROM book_lexicon l
+			WHERE l.word % w.word
+			ORDER BY l.word = w.word DESC, similarity(l.word, w.word) DESC, l.ndoc DESC
+			LIMIT 1
+		) m ON true
+		ORDER BY w.pos
+	`
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, wordsQuery, words)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return Book{}, ErrNotFound
+		return nil, err
+	}
+	corrected := make([]string, 0, len(words))
+	for rows.Next() {
+		var w string
+		if err := rows.Scan(&w); err != nil {
+			rows.Close()
+			return nil, err
 		}
-		return Book{}, err
+		corrected = append(corrected, w)
 	}
-	return b, nil
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	suggestions = appendUnique(suggestions, original, limit, strings.Join(corrected, " "))
+
+	const titlesQuery = `
+		SELECT title
+		FROM books
+		WHERE title % $1
+		ORDER BY similarity(title, $1) DESC, title
+		LIMIT $2
+	`
+	rows, err = r.db.Query(timeoutCtx, titlesQuery, original, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		suggestions = appendUnique(suggestions, original, limit, title)
+	}
+	return suggestions, rows.Err()
 }
 
+// RefreshLexicon rebuilds the book_lexicon dictionary used by Corrections.
+// It reads every book, so like ReconcileAggregates it is bounded by ctx
+// rather than the query timeout.
+func (r *PostgresRepo) RefreshLexicon(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY book_lexicon`)
+	return err
+}
+
+// UpsertFromIngest writes an ingested book with its credits, subjects and
+// series. Works, subjects and series are shared by every worker importing
+// at once, so they are inserted without touching existing rows and in a
+// fixed order; a deadlock that still happens is reported as ErrDeadlock.
 func (r *PostgresRepo) UpsertFromIngest(ctx context.Context, book *Book) error {
+	err := r.upsertFromIngest(ctx, book)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "40P01" {
+		return fmt.Errorf("%w: %w", ErrDeadlock, err)
+	}
+	return err
+}
+
+func (r *PostgresRepo) upsertFromIngest(ctx context.Context, book *Book) error {
 	const sql = `
 		INSERT INTO books (isbn, title, subtitle, genre, publisher, description, 
 		                   published_date, publication_year, page_count, language, cover_url, 
-		                   created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+		                   work_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
 		ON CONFLICT (isbn) DO UPDATE SET
 			title = EXCLUDED.title,
 			subtitle = EXCLUDED.subtitle,
@@ -237,13 +860,236 @@ func (r *PostgresRepo) UpsertFromIngest(ctx context.Context, book *Book) error {
 			page_count = EXCLUDED.page_count,
 			language = EXCLUDED.language,
 			cover_url = EXCLUDED.cover_url,
-			updated_at = NOW()`
+			work_id = COALESCE(EXCLUDED.work_id, books.work_id),
+			updated_at = NOW()
+		RETURNING id`
+
+	const workSQL = `
+		INSERT INTO works (ol_key, title) VALUES ($1, $2)
+		ON CONFLICT (ol_key) DO NOTHING
+		RETURNING id`
+	const workIDSQL = `SELECT id FROM works WHERE ol_key = $1`
+
+	const seriesSQL = `
+		INSERT INTO series (name) VALUES ($1)
+		ON CONFLICT ((lower(name))) DO NOTHING
+		RETURNING id`
+	const seriesIDSQL = `SELECT id FROM series WHERE lower(name) = lower($1)`
+
+	const membershipSQL = `
+		INSERT INTO book_series (book_id, series_id, position)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (book_id, series_id) DO UPDATE SET
+			position = COALESCE(EXCLUDED.position, book_series.position)`
+
+	const subjectSQL = `
+		INSERT INTO subjects (name, genre_slug) VALUES ($1, genre_for_subject($1))
+		ON CONFLICT ((lower(name))) DO NOTHING
+		RETURNING id`
+	const subjectIDSQL = `SELECT id FROM subjects WHERE lower(name) = lower($1)`
+
+	const subjectLinkSQL = `
+		INSERT INTO book_subjects (book_id, subject_id, position)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (book_id, subject_id) DO NOTHING`
+
+	// The display genre follows the first subject that maps to a genre.
+	const genreSQL = `
+		UPDATE books SET genre = g.name
+		FROM (
+			SELECT gn.name
+			FROM book_subjects bs
+			JOIN subjects s ON s.id = bs.subject_id
+			JOIN genres gn ON gn.slug = s.genre_slug
+			WHERE bs.book_id = $1
+			ORDER BY bs.position
+			LIMIT 1
+		) g
+		WHERE books.id = $1 AND books.genre <> g.name
+		RETURNING books.genre`
+
+	const authorSQL = `
+		INSERT INTO book_authors (book_id, author_key, name, role, position)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (book_id, author_key, role) DO NOTHING`
+
+	key, err := isbn.Canonical(book.ISBN)
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, book.ISBN)
+	}
+	book.ISBN = key
 
 	timeoutCtx, cancel := r.withTimeout(ctx)
 	defer cancel()
-	_, err := r.db.Exec(timeoutCtx, sql,
+	tx, err := r.db.Begin(timeoutCtx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// The works row is created on first sight of its Open Library key;
+	// an empty key leaves work_id untouched.
+	var workID *string
+	if book.WorkKey != "" {
+		id, err := sharedRowID(timeoutCtx, tx, workSQL, workIDSQL, book.WorkKey, book.Title)
+		if err != nil {
+			return fmt.Errorf("upsert work %q: %w", book.WorkKey, err)
+		}
+		workID = &id
+	}
+
+	err = tx.QueryRow(timeoutCtx, sql,
 		book.ISBN, book.Title, book.Subtitle, book.Genre, book.Publisher, book.Description,
 		book.PublishedDate, book.PublicationYear, book.PageCount, book.Language, book.CoverURL,
-	)
-	return err
+		workID,
+	).Scan(&book.ID)
+	if err != nil {
+		return fmt.Errorf("upsert book: %w", err)
+	}
+
+	// Ingest is authoritative for credits: a book that lists authors has
+	// them replaced wholesale, one that lists none keeps what it had.
+	if len(book.Authors) > 0 {
+		if _, err := tx.Exec(timeoutCtx, `DELETE FROM book_authors WHERE book_id = $1`, book.ID); err != nil {
+			return fmt.Errorf("clear authors: %w", err)
+		}
+		for i, a := range book.Authors {
+			if _, err := tx.Exec(timeoutCtx, authorSQL, book.ID, a.Key, a.Name, a.Role, i+1); err != nil {
+				return fmt.Errorf("link author %q: %w", a.Key, err)
+			}
+		}
+	}
+
+	if len(book.Subjects) > 0 {
+		if _, err := tx.Exec(timeoutCtx, `DELETE FROM book_subjects WHERE book_id = $1`, book.ID); err != nil {
+			return fmt.Errorf("clear subjects: %w", err)
+		}
+		for _, i := range byLowerName(book.Subjects, func(name string) string { return name }) {
+			name := book.Subjects[i]
+			subjectID, err := sharedRowID(timeoutCtx, tx, subjectSQL, subjectIDSQL, name)
+			if err != nil {
+				return fmt.Errorf("upsert subject %q: %w", name, err)
+			}
+			if _, err := tx.Exec(timeoutCtx, subjectLinkSQL, book.ID, subjectID, i); err != nil {
+				return fmt.Errorf("link subject %q: %w", name, err)
+			}
+		}
+		err := tx.QueryRow(timeoutCtx, genreSQL, book.ID).Scan(&book.Genre)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("derive genre: %w", err)
+		}
+	}
+
+	for _, i := range byLowerName(book.SeriesMemberships, func(m SeriesRef) string { return m.Name }) {
+		m := book.SeriesMemberships[i]
+		seriesID, err := sharedRowID(timeoutCtx, tx, seriesSQL, seriesIDSQL, m.Name)
+		if err != nil {
+			return fmt.Errorf("upsert series %q: %w", m.Name, err)
+		}
+		if _, err := tx.Exec(timeoutCtx, membershipSQL, book.ID, seriesID, m.Position); err != nil {
+			return fmt.Errorf("link series %q: %w", m.Name, err)
+		}
+	}
+
+	return tx.Commit(timeoutCtx)
+}
+
+// sharedRowID returns the id of a row shared between books, inserting it
+// when missing. insertSQL must do nothing on conflict, so an existing row is
+// never locked, and selectSQL looks the row up by the first argument.
+func sharedRowID(ctx context.Context, tx pgx.Tx, insertSQL, selectSQL string, args ...any) (string, error) {
+	var id string
+	err := tx.QueryRow(ctx, insertSQL, args...).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = tx.QueryRow(ctx, selectSQL, args[0]).Scan(&id)
+	}
+	return id, err
+}
+
+// byLowerName returns the indexes of items ordered by lower-cased name, so
+// concurrent upserts take the locks on shared rows in the same order.
+// Items with the same name keep their relative order.
+func byLowerName[T any](items []T, name func(T) string) []int {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return strings.ToLower(name(items[order[a]])) < strings.ToLower(name(items[order[b]]))
+	})
+	return order
+}
+
+func (r *PostgresRepo) Create(ctx context.Context, book *Book) error {
+	const sql = `
+		INSERT INTO books (isbn, title, subtitle, genre, publisher, description,
+		                   published_date, publication_year, page_count, language, cover_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at`
+
+	key, err := isbn.Canonical(book.ISBN)
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, book.ISBN)
+	}
+	book.ISBN = key
+
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	err = r.db.QueryRow(timeoutCtx, sql,
+		book.ISBN, book.Title, book.Subtitle, book.Genre, book.Publisher, book.Description,
+		book.PublishedDate, book.PublicationYear, book.PageCount, book.Language, book.CoverURL,
+	).Scan(&book.ID, &book.CreatedAt, &book.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresRepo) Update(ctx context.Context, isbn string, book *Book) error {
+	const sql = `
+		UPDATE books SET
+			title = $2,
+			subtitle = $3,
+			genre = $4,
+			publisher = $5,
+			description = $6,
+			published_date = $7,
+			publication_year = $8,
+			page_count = $9,
+			language = $10,
+			cover_url = $11,
+			updated_at = NOW()
+		WHERE id = book_id_for_isbn($1)
+		RETURNING id, isbn, work_id, created_at, updated_at`
+
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	err := r.db.QueryRow(timeoutCtx, sql, isbn,
+		book.Title, book.Subtitle, book.Genre, book.Publisher, book.Description,
+		book.PublishedDate, book.PublicationYear, book.PageCount, book.Language, book.CoverURL,
+	).Scan(&book.ID, &book.ISBN, &book.WorkID, &book.CreatedAt, &book.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresRepo) Delete(ctx context.Context, isbn string) error {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tag, err := r.db.Exec(timeoutCtx, "DELETE FROM books WHERE id = book_id_for_isbn($1)", isbn)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
@@ -0,0 +1,103 @@
+package book
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortSpecFor(t *testing.T) {
+	assert.Equal(t, "b.title", sortSpecFor(Query{}, 0).expr)
+	assert.Equal(t, "b.title", sortSpecFor(Query{Sort: "relevance"}, 0).expr, "relevance needs a search term")
+	assert.Contains(t, sortSpecFor(Query{Sort: "relevance", Search: "dune"}, 3).expr, "$3")
+	assert.True(t, sortSpecFor(Query{Sort: "year"}, 0).nullable)
+	assert.Equal(t, "b.rating_avg", sortSpecFor(Query{Sort: "rating"}, 0).expr)
+}
+
+func TestKeysetPredicate(t *testing.T) {
+	t.Run("non-nullable ascending", func(t *testing.T) {
+		got := keysetPredicate(sortSpecFor(Query{Sort: "title"}, 0), "ASC", "$1", "$2")
+		assert.Equal(t, "(b.title, b.id) > ($1, $2)", got)
+	})
+
+	t.Run("non-nullable descending", func(t *testing.T) {
+		got := keysetPredicate(sortSpecFor(Query{Sort: "created_at"}, 0), "DESC", "$1", "$2")
+		assert.Equal(t, "(b.created_at, b.id) < ($1, $2)", got)
+	})
+
+	t.Run("nullable keeps NULL rows last", func(t *testing.T) {
+		got := keysetPredicate(sortSpecFor(Query{Sort: "year"}, 0), "DESC", "$1", "$2")
+		assert.Equal(t,
+			"(($1 IS NULL AND b.publication_year IS NULL AND b.id < $2) OR "+
+				"($1 IS NOT NULL AND (b.publication_year IS NULL OR b.publication_year < $1 OR (b.publication_year = $1 AND b.id < $2))))",
+			got)
+	})
+}
+
+func TestBuildFilters_ExcludesOwnFacet(t *testing.T) {
+	minRating := 4.0
+	q := Query{Genre: "Fiction", Language: "en", MinRating: &minRating}
+
+	all := buildFilters(q, "")
+	assert.Len(t, all.args, 3)
+	assert.Contains(t, all.where(), "b.rating_avg >= $3")
+
+	noGenre := buildFilters(q, FacetGenre)
+	assert.Equal(t, []any{"en", 4.0}, noGenre.args)
+	assert.NotContains(t, noGenre.where(), "genre")
+
+	noRating := buildFilters(q, FacetRating)
+	assert.NotContains(t, noRating.where(), "rating_avg")
+	assert.Equal(t, []any{[]string{"fiction"}, "en"}, noRating.args)
+}
+
+func TestBuildFilters_GenresIncludeDescendants(t *testing.T) {
+	f := buildFilters(Query{Genre: "Science Fiction", Genres: []string{"fantasy"}}, "")
+	assert.Contains(t, f.where(), "genre_closure")
+	assert.Equal(t, []any{[]string{"science-fiction", "fantasy"}}, f.args)
+}
+
+func TestBuildFilters_FieldQualifiers(t *testing.T) {
+	f := buildFilters(Query{Title: "dune", Author: "herbert", PublisherMatch: "a_e"}, "")
+	assert.Contains(t, f.where(), "phraseto_tsquery('english_unaccent', $1)")
+	assert.Contains(t, f.where(), "book_authors")
+	assert.Contains(t, f.where(), "publisher ILIKE $3")
+	assert.Equal(t, []any{"dune", "herbert", `%a\_e%`}, f.args)
+}
+
+func TestBuildFilters_SearchLanguage(t *testing.T) {
+	assert.Contains(t, buildFilters(Query{Search: "misérables", SearchLanguage: "fr"}, "").where(),
+		"websearch_to_tsquery('french_unaccent', $1)")
+	assert.Contains(t, buildFilters(Query{Search: "dune"}, "").where(),
+		"websearch_to_tsquery('english_unaccent', $1)")
+	assert.Contains(t, buildFilters(Query{Title: "dune", SearchLanguage: "ja"}, "").where(),
+		"to_tsvector('simple_unaccent', title)")
+}
+
+func TestGenreSlug(t *testing.T) {
+	assert.Equal(t, "science-fiction", GenreSlug("Science Fiction"))
+	assert.Equal(t, "science-fiction", GenreSlug(" science_fiction "))
+	assert.Equal(t, "self-help", GenreSlug("Self-Help"))
+	assert.Equal(t, "", GenreSlug("  "))
+}
+
+func TestLikePrefix(t *testing.T) {
+	assert.Equal(t, "dune%", likePrefix("dune"))
+	assert.Equal(t, `100\% pure\_%`, likePrefix("100% pure_"))
+}
+
+func TestSearchWords(t *testing.T) {
+	assert.Equal(t, []string{"harry", "potter", "2"}, searchWords("  Harry-Potter, #2 "))
+	assert.Empty(t, searchWords("--"))
+}
+
+func TestAppendUnique(t *testing.T) {
+	got := appendUnique(nil, "dnue", 2, "dune", "Dune", "dnue", "", "dune messiah", "children of dune")
+	assert.Equal(t, []string{"dune", "dune messiah"}, got)
+}
+
+func TestByLowerName(t *testing.T) {
+	subjects := []string{"science fiction", "Deserts", "Ecology", "deserts"}
+	got := byLowerName(subjects, func(name string) string { return name })
+	assert.Equal(t, []int{1, 3, 2, 0}, got, "case-insensitive and stable, keeping each original position")
+}
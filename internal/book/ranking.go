@@ -0,0 +1,118 @@
+package book
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRankLimit is the number of books returned by ranked lists
+	// when no limit is given.
+	DefaultRankLimit = 20
+	// MaxRankLimit caps ranked lists. Rankings are computed and cached at
+	// this size and cut down to the requested limit.
+	MaxRankLimit = 100
+)
+
+// RankingConfig tunes the top rated and trending lists.
+type RankingConfig struct {
+	// PriorWeight is the number of phantom ratings at PriorMean every book
+	// starts with: score = (C·m + n·avg) / (C + n). The higher it is, the
+	// more ratings a book needs before its own average dominates.
+	PriorWeight int
+	// PriorMean is the rating of the phantom ratings; nil uses the mean of
+	// all ratings.
+	PriorMean *float64
+	// TrendingWindow is how far back rating and reading list activity
+	// counts towards trending.
+	TrendingWindow time.Duration
+	// TrendingHalfLife is the age at which an event weighs half as much as
+	// one happening now.
+	TrendingHalfLife time.Duration
+	// CacheTTL is how long a computed ranking is served before it is
+	// recomputed.
+	CacheTTL time.Duration
+}
+
+// DefaultRankingConfig returns the ranking settings used by NewService.
+func DefaultRankingConfig() RankingConfig {
+	return RankingConfig{
+		PriorWeight:      10,
+		TrendingWindow:   30 * 24 * time.Hour,
+		TrendingHalfLife: 7 * 24 * time.Hour,
+		CacheTTL:         10 * time.Minute,
+	}
+}
+
+// RankQuery filters a ranked list. Genre matches the genre and its
+// descendants, as in Query.
+type RankQuery struct {
+	Genre    string
+	Language string
+	Limit    int
+}
+
+// RankedBook is a book with the score it was ranked by.
+type RankedBook struct {
+	Book
+	Score float64 `json:"score"`
+}
+
+// Ranking is a ranked list and when it was computed.
+type Ranking struct {
+	Books      []RankedBook
+	ComputedAt time.Time
+}
+
+// maxRankingCacheEntries bounds the number of filter combinations cached.
+const maxRankingCacheEntries = 256
+
+// rankingCache keeps computed rankings per list and filter until they expire.
+type rankingCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]Ranking
+}
+
+func newRankingCache(ttl time.Duration) *rankingCache {
+	return &rankingCache{ttl: ttl, entries: make(map[string]Ranking)}
+}
+
+func rankingKey(list string, q RankQuery) string {
+	return list + "|" + GenreSlug(q.Genre) + "|" + strings.ToLower(strings.TrimSpace(q.Language))
+}
+
+func (c *rankingCache) get(key string, now time.Time) (Ranking, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.entries[key]
+	if !ok || now.Sub(r.ComputedAt) >= c.ttl {
+		return Ranking{}, false
+	}
+	return r, true
+}
+
+func (c *rankingCache) put(key string, r Ranking) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxRankingCacheEntries {
+		for k, e := range c.entries {
+			if r.ComputedAt.Sub(e.ComputedAt) >= c.ttl {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxRankingCacheEntries {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = r
+}
+
+// limited returns the first limit books of the ranking.
+func (r Ranking) limited(limit int) Ranking {
+	if limit > 0 && limit < len(r.Books) {
+		r.Books = r.Books[:limit]
+	}
+	return r
+}
@@ -0,0 +1,62 @@
+package book
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RankingsAreCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, "test-secret")
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	ranked := []RankedBook{{Book: Book{ID: "1"}, Score: 3}, {Book: Book{ID: "2"}, Score: 2}}
+	mockRepo.EXPECT().TopRated(gomock.Any(), RankQuery{Genre: "Science Fiction", Limit: MaxRankLimit}, gomock.Any()).
+		Return(ranked, nil).Times(2)
+
+	first, err := service.TopRated(context.Background(), RankQuery{Genre: "Science Fiction", Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, first.Books, 1)
+
+	// Same list and filters within the TTL: served from the cache, at a
+	// different limit.
+	now = now.Add(DefaultRankingConfig().CacheTTL - time.Second)
+	second, err := service.TopRated(context.Background(), RankQuery{Genre: "Science Fiction", Limit: 5})
+	require.NoError(t, err)
+	assert.Len(t, second.Books, 2)
+	assert.Equal(t, first.ComputedAt, second.ComputedAt)
+
+	// Expired: recomputed.
+	now = now.Add(time.Second)
+	third, err := service.TopRated(context.Background(), RankQuery{Genre: "Science Fiction"})
+	require.NoError(t, err)
+	assert.Equal(t, now, third.ComputedAt)
+}
+
+func TestService_RankingCacheIsPerList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, "test-secret")
+
+	mockRepo.EXPECT().TopRated(gomock.Any(), gomock.Any(), gomock.Any()).Return([]RankedBook{}, nil).Times(1)
+	mockRepo.EXPECT().Trending(gomock.Any(), gomock.Any(), gomock.Any()).Return([]RankedBook{}, nil).Times(2)
+
+	for range 2 {
+		_, err := service.TopRated(context.Background(), RankQuery{Language: "en"})
+		require.NoError(t, err)
+	}
+	_, err := service.Trending(context.Background(), RankQuery{Language: "en"})
+	require.NoError(t, err)
+	_, err = service.Trending(context.Background(), RankQuery{Language: "fr"})
+	require.NoError(t, err)
+}
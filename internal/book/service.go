@@ -2,24 +2,229 @@ package book
 
 import (
 	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 // Service provides book-related business logic.
 type Service struct {
-	repo Repository
+	repo     Repository
+	cursors  *CursorCodec
+	ranking  RankingConfig
+	rankings *rankingCache
+	lexicon  *LexiconRefresher
+	now      func() time.Time
+}
+
+// NewService creates a new book service. cursorSecret signs pagination cursors.
+func NewService(repo Repository, cursorSecret string) *Service {
+	return (&Service{repo: repo, cursors: NewCursorCodec(cursorSecret), now: time.Now}).
+		WithRanking(DefaultRankingConfig())
+}
+
+// WithRanking replaces the ranking settings and drops cached rankings.
+func (s *Service) WithRanking(cfg RankingConfig) *Service {
+	s.ranking = cfg
+	s.rankings = newRankingCache(cfg.CacheTTL)
+	return s
 }
 
-// NewService creates a new book service.
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+// WithLexicon rebuilds the spelling-correction dictionary through l after
+// books are created or updated.
+func (s *Service) WithLexicon(l *LexiconRefresher) *Service {
+	s.lexicon = l
+	return s
 }
 
-// List returns a list of books matching the query.
-func (s *Service) List(ctx context.Context, q Query) ([]Book, int, error) {
-	return s.repo.List(ctx, q)
+// List returns a page of books matching the query. When the query carries a
+// cursor or after_id, rows are fetched by keyset and the offset is ignored.
+// NextCursor is set whenever the page is full. Requested facets are counted
+// over the whole result set, not just the current page. A full-text search
+// that matches nothing yields spelling suggestions, and with Fuzzy set the
+// best one is searched instead.
+func (s *Service) List(ctx context.Context, q Query) (Page, error) {
+	if q.Cursor != "" {
+		after, err := s.cursors.Decode(q.Cursor, q)
+		if err != nil {
+			return Page{}, err
+		}
+		if _, err := uuid.Parse(after.AfterID); err != nil {
+			return Page{}, ErrInvalidCursor
+		}
+		q.After = &after
+	} else if q.AfterID != "" {
+		if _, err := uuid.Parse(q.AfterID); err != nil {
+			return Page{}, ErrInvalidCursor
+		}
+	}
+	if q.After != nil || q.AfterID != "" {
+		q.Offset = 0
+	}
+
+	books, total, err := s.repo.List(ctx, q)
+	if err != nil {
+		return Page{}, err
+	}
+
+	var page Page
+	// listed is the query the returned books actually match; it differs from
+	// q only when a fuzzy search was rerun with a correction.
+	listed := q
+	if q.Search != "" && total == 0 {
+		page.Suggestions, err = s.repo.Corrections(ctx, q.Search, maxCorrections)
+		if err != nil {
+			return Page{}, err
+		}
+		if q.Fuzzy && len(page.Suggestions) > 0 {
+			listed.Search = page.Suggestions[0]
+			books, total, err = s.repo.List(ctx, listed)
+			if err != nil {
+				return Page{}, err
+			}
+			page.CorrectedQuery = listed.Search
+		}
+	}
+
+	page.Books, page.Total = books, total
+	if len(books) > 0 && len(books) == q.Limit {
+		last := books[len(books)-1]
+		// Sign against the original query so the client can page on with
+		// unchanged parameters; the correction is re-derived on each page.
+		page.NextCursor = s.cursors.Encode(CursorData{Key: last.sortKey, AfterID: last.ID}, q)
+	}
+
+	if len(q.Facets) > 0 {
+		page.Facets, err = s.repo.Facets(ctx, listed, q.Facets)
+		if err != nil {
+			return Page{}, err
+		}
+	}
+	return page, nil
 }
 
 // GetByISBN returns a book by its ISBN.
 func (s *Service) GetByISBN(ctx context.Context, isbn string) (Book, error) {
 	return s.repo.GetByISBN(ctx, isbn)
 }
+
+// Create adds a new book to the catalog.
+func (s *Service) Create(ctx context.Context, b *Book) error {
+	if err := s.repo.Create(ctx, b); err != nil {
+		return err
+	}
+	s.refreshLexicon()
+	return nil
+}
+
+// Replace overwrites every editable field of the book with the given ISBN.
+func (s *Service) Replace(ctx context.Context, isbn string, b *Book) error {
+	if err := s.repo.Update(ctx, isbn, b); err != nil {
+		return err
+	}
+	s.refreshLexicon()
+	return nil
+}
+
+// Patch applies a partial update to the book with the given ISBN and returns the result.
+func (s *Service) Patch(ctx context.Context, isbn string, cmd UpdateCommand) (Book, error) {
+	b, err := s.repo.GetByISBN(ctx, isbn)
+	if err != nil {
+		return Book{}, err
+	}
+	cmd.Apply(&b)
+	if err := s.repo.Update(ctx, isbn, &b); err != nil {
+		return Book{}, err
+	}
+	s.refreshLexicon()
+	return b, nil
+}
+
+func (s *Service) refreshLexicon() {
+	if s.lexicon != nil {
+		s.lexicon.Trigger()
+	}
+}
+
+// Delete removes the book with the given ISBN.
+func (s *Service) Delete(ctx context.Context, isbn string) error {
+	if err := s.repo.Delete(ctx, isbn); err != nil {
+		return err
+	}
+	s.refreshLexicon()
+	return nil
+}
+
+// Suggest returns typeahead suggestions for a partially typed query.
+func (s *Service) Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return []Suggestion{}, nil
+	}
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+	if limit > MaxSuggestLimit {
+		limit = MaxSuggestLimit
+	}
+	return s.repo.Suggest(ctx, prefix, limit)
+}
+
+// TopRated ranks rated books by their Bayesian average rating, so that a
+// handful of perfect ratings does not outrank a high average over many.
+func (s *Service) TopRated(ctx context.Context, q RankQuery) (Ranking, error) {
+	return s.ranked(ctx, "top", q, s.repo.TopRated)
+}
+
+// Trending ranks books by recent rating and reading list activity, each
+// event weighted down exponentially with age.
+func (s *Service) Trending(ctx context.Context, q RankQuery) (Ranking, error) {
+	return s.ranked(ctx, "trending", q, s.repo.Trending)
+}
+
+// Similar returns the books most like the one with the given ISBN, scored
+// on shared authors, subjects and genres, overlapping title words, and the
+// readers the books have in common. Other editions of the same work are left
+// out.
+func (s *Service) Similar(ctx context.Context, isbn string, limit int) ([]SimilarBook, error) {
+	if limit <= 0 {
+		limit = DefaultSimilarLimit
+	}
+	if limit > MaxSimilarLimit {
+		limit = MaxSimilarLimit
+	}
+	candidates, err := s.repo.SimilarCandidates(ctx, isbn)
+	if err != nil {
+		return nil, err
+	}
+	return rankSimilar(candidates, limit), nil
+}
+
+// ranked serves a ranking from the cache, computing it at MaxRankLimit
+// when missing or expired.
+func (s *Service) ranked(ctx context.Context, list string, q RankQuery,
+	compute func(context.Context, RankQuery, RankingConfig) ([]RankedBook, error),
+) (Ranking, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultRankLimit
+	}
+	if q.Limit > MaxRankLimit {
+		q.Limit = MaxRankLimit
+	}
+
+	key := rankingKey(list, q)
+	if cached, ok := s.rankings.get(key, s.now()); ok {
+		return cached.limited(q.Limit), nil
+	}
+
+	full := q
+	full.Limit = MaxRankLimit
+	books, err := compute(ctx, full, s.ranking)
+	if err != nil {
+		return Ranking{}, err
+	}
+	r := Ranking{Books: books, ComputedAt: s.now()}
+	s.rankings.put(key, r)
+	return r.limited(q.Limit), nil
+}
@@ -0,0 +1,177 @@
+package book
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultSimilarLimit is the number of similar books returned when no
+	// limit is given.
+	DefaultSimilarLimit = 10
+	// MaxSimilarLimit caps the similar books list.
+	MaxSimilarLimit = 50
+)
+
+// Weights of the similarity signals; they add up to 1 so a book sharing
+// everything with the source scores 1.
+const (
+	similarAuthorWeight  = 0.30
+	similarSubjectWeight = 0.25
+	similarReaderWeight  = 0.25
+	similarGenreWeight   = 0.10
+	similarTextWeight    = 0.10
+)
+
+// similarTextHalfRank is the ts_rank at which the text signal counts half.
+const similarTextHalfRank = 0.1
+
+// SimilarCandidate is a book sharing at least one signal with the source
+// book, along with the raw signals. Counts prefixed with Source are those of
+// the source book and are the same on every candidate.
+type SimilarCandidate struct {
+	Book
+	// SharedAuthors are the names of the authors credited on both books.
+	SharedAuthors []string
+	// SharedSubjects are the subjects of the source the candidate also has.
+	SharedSubjects []string
+	SubjectCount   int
+	SourceSubjects int
+	// SharedGenres are the names of the genres both books are in.
+	SharedGenres []string
+	GenreCount   int
+	SourceGenres int
+	// TextRank is the ts_rank of the candidate against the words of the
+	// source's title, authors and genre.
+	TextRank float64
+	// CoReaders is the number of users who have both books on a reading
+	// list or rated both highly. Readers and SourceReaders count the users
+	// who have each book on a list or rated it highly.
+	CoReaders     int
+	Readers       int
+	SourceReaders int
+}
+
+// SimilarBook is a book similar to another, with its score and the reason
+// it was picked.
+type SimilarBook struct {
+	Book
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+type similarSignal struct {
+	score  float64
+	reason func(SimilarCandidate) string
+}
+
+// rankSimilar scores the candidates, drops those scoring nothing and
+// returns the best limit of them, best first.
+func rankSimilar(candidates []SimilarCandidate, limit int) []SimilarBook {
+	out := make([]SimilarBook, 0, len(candidates))
+	for _, c := range candidates {
+		score, reason := scoreSimilar(c)
+		if score <= 0 {
+			continue
+		}
+		out = append(out, SimilarBook{Book: c.Book, Score: score, Reason: reason})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		if out[i].Rating.Count != out[j].Rating.Count {
+			return out[i].Rating.Count > out[j].Rating.Count
+		}
+		return out[i].ID < out[j].ID
+	})
+	if limit > 0 && limit < len(out) {
+		out = out[:limit]
+	}
+	return out
+}
+
+// scoreSimilar combines the weighted signals of a candidate. The reason
+// describes the signal contributing the most.
+func scoreSimilar(c SimilarCandidate) (float64, string) {
+	signals := []similarSignal{
+		{similarAuthorWeight * boolScore(len(c.SharedAuthors) > 0), authorReason},
+		{similarReaderWeight * cosine(c.CoReaders, c.Readers, c.SourceReaders), readerReason},
+		{similarSubjectWeight * jaccard(len(c.SharedSubjects), c.SubjectCount, c.SourceSubjects), subjectReason},
+		{similarGenreWeight * jaccard(len(c.SharedGenres), c.GenreCount, c.SourceGenres), genreReason},
+		{similarTextWeight * c.TextRank / (c.TextRank + similarTextHalfRank), textReason},
+	}
+
+	var total float64
+	best := -1
+	for i, s := range signals {
+		total += s.score
+		if s.score > 0 && (best < 0 || s.score > signals[best].score) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 0, ""
+	}
+	return math.Round(total*1000) / 1000, signals[best].reason(c)
+}
+
+func boolScore(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// jaccard is |A∩B| / |A∪B| given the size of the intersection and of each set.
+func jaccard(shared, a, b int) float64 {
+	union := a + b - shared
+	if shared <= 0 || union <= 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// cosine is the cosine similarity of two sets of readers.
+func cosine(shared, a, b int) float64 {
+	if shared <= 0 || a <= 0 || b <= 0 {
+		return 0
+	}
+	return math.Min(1, float64(shared)/math.Sqrt(float64(a)*float64(b)))
+}
+
+func authorReason(c SimilarCandidate) string {
+	return "Also by " + joinNames(c.SharedAuthors, 2)
+}
+
+func readerReason(c SimilarCandidate) string {
+	if c.CoReaders == 1 {
+		return "Also enjoyed by 1 reader of this book"
+	}
+	return fmt.Sprintf("Also enjoyed by %d readers of this book", c.CoReaders)
+}
+
+func subjectReason(c SimilarCandidate) string {
+	return "Also about " + joinNames(c.SharedSubjects, 3)
+}
+
+func genreReason(c SimilarCandidate) string {
+	return "Also in " + joinNames(c.SharedGenres, 2)
+}
+
+func textReason(SimilarCandidate) string {
+	return "Similar title and keywords"
+}
+
+// joinNames lists up to n names as "a, b and c".
+func joinNames(names []string, n int) string {
+	if len(names) > n {
+		names = names[:n]
+	}
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
@@ -0,0 +1,78 @@
+package book
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreSimilar(t *testing.T) {
+	tests := []struct {
+		name      string
+		candidate SimilarCandidate
+		score     float64
+		reason    string
+	}{
+		{
+			name:      "no signal",
+			candidate: SimilarCandidate{},
+			score:     0,
+			reason:    "",
+		},
+		{
+			name:      "same author",
+			candidate: SimilarCandidate{SharedAuthors: []string{"Frank Herbert"}},
+			score:     0.3,
+			reason:    "Also by Frank Herbert",
+		},
+		{
+			name: "readers outweigh a shared genre",
+			candidate: SimilarCandidate{
+				SharedGenres: []string{"Science Fiction"}, GenreCount: 2, SourceGenres: 1,
+				CoReaders: 3, Readers: 4, SourceReaders: 9,
+			},
+			score:  0.175,
+			reason: "Also enjoyed by 3 readers of this book",
+		},
+		{
+			name: "subjects",
+			candidate: SimilarCandidate{
+				SharedSubjects: []string{"Deserts", "Ecology", "Politics", "Religion"},
+				SubjectCount:   4, SourceSubjects: 4,
+			},
+			score:  0.25,
+			reason: "Also about Deserts, Ecology and Politics",
+		},
+		{
+			name:      "text only",
+			candidate: SimilarCandidate{TextRank: 0.1},
+			score:     0.05,
+			reason:    "Similar title and keywords",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, reason := scoreSimilar(tt.candidate)
+			assert.InDelta(t, tt.score, score, 0.001)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestRankSimilar(t *testing.T) {
+	candidates := []SimilarCandidate{
+		{Book: Book{ID: "a"}, TextRank: 0.05},
+		{Book: Book{ID: "b"}},
+		{Book: Book{ID: "c"}, SharedAuthors: []string{"Ursula K. Le Guin"}},
+		{Book: Book{ID: "d", Rating: RatingSummary{Count: 12}}, TextRank: 0.05},
+	}
+
+	ranked := rankSimilar(candidates, 10)
+
+	ids := make([]string, len(ranked))
+	for i, b := range ranked {
+		ids[i] = b.ID
+	}
+	assert.Equal(t, []string{"c", "d", "a"}, ids, "unscored candidates are dropped, ties go to the more rated book")
+	assert.Len(t, rankSimilar(candidates, 1), 1)
+}
@@ -0,0 +1,76 @@
+package book
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Suggestion types.
+const (
+	SuggestionTitle  = "title"
+	SuggestionAuthor = "author"
+)
+
+const (
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 20
+
+	// suggestCandidates is how many matches per requested suggestion each
+	// of titles and authors is cut to before duplicates collapse.
+	suggestCandidates = 5
+
+	// maxCorrections caps the "did you mean" suggestions for an empty search.
+	maxCorrections = 5
+)
+
+// Suggestion is a typeahead match for a partially typed query: a title,
+// with the ISBN of one of its editions, or an author name with its key.
+type Suggestion struct {
+	Type      string  `json:"type"`
+	Text      string  `json:"text"`
+	ISBN      string  `json:"isbn,omitempty"`
+	AuthorKey string  `json:"author_key,omitempty"`
+	Score     float64 `json:"score"`
+}
+
+// likePrefix escapes LIKE metacharacters in s and appends a trailing wildcard.
+func likePrefix(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s) + "%"
+}
+
+// likeContains escapes s for use as an ILIKE substring pattern.
+func likeContains(s string) string {
+	return "%" + likePrefix(s)
+}
+
+// searchWords lowercases s and splits it into letter/digit runs.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// appendUnique appends candidates to list, skipping case-insensitive
+// duplicates and anything equal to exclude, and stops at limit entries.
+func appendUnique(list []string, exclude string, limit int, candidates ...string) []string {
+	for _, c := range candidates {
+		if len(list) >= limit {
+			break
+		}
+		if c == "" || strings.EqualFold(c, exclude) {
+			continue
+		}
+		dup := false
+		for _, existing := range list {
+			if strings.EqualFold(existing, c) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			list = append(list, c)
+		}
+	}
+	return list
+}
@@ -2,6 +2,8 @@ package catalog
 
 import (
 	"time"
+
+	"bookapi/internal/platform/textsearch"
 )
 
 type Book struct {
@@ -15,6 +17,8 @@ type Book struct {
 	Language      string
 	PageCount     int
 	UpdatedAt     time.Time
+	// Highlights are set by List for a full-text search.
+	Highlights *textsearch.Highlights `json:"highlights,omitempty"`
 }
 
 type Author struct {
@@ -25,9 +29,38 @@ type Author struct {
 	UpdatedAt time.Time
 }
 
+// Work groups the editions of a book. Only works loaded from bulk dumps are
+// kept.
+type Work struct {
+	Key         string
+	Title       string
+	Subjects    []string
+	Description string
+	UpdatedAt   time.Time
+}
+
+// BookRecord is a book to upsert in bulk with the source data it came from.
+type BookRecord struct {
+	Book    Book
+	RawJSON []byte
+}
+
+// AuthorRecord is an author to upsert in bulk with the source data it came
+// from.
+type AuthorRecord struct {
+	Author  Author
+	RawJSON []byte
+}
+
+// WorkRecord is a work to upsert in bulk with the source data it came from.
+type WorkRecord struct {
+	Work    Work
+	RawJSON []byte
+}
+
 type Source struct {
 	ID         string
-	EntityType string // BOOK, AUTHOR
+	EntityType string // BOOK, AUTHOR, WORK
 	EntityKey  string
 	Provider   string
 	RawJSON    []byte
@@ -35,9 +68,17 @@ type Source struct {
 }
 
 type SearchQuery struct {
+	// Q is full-text in websearch_to_tsquery syntax.
 	Q         string
+	Title     string
 	Publisher string
 	Language  string
-	Limit     int
-	Offset    int
+	YearFrom  *int
+	YearTo    *int
+	// SearchLanguage selects the text search config Q and Title are
+	// parsed with; see textsearch.Config.
+	SearchLanguage string
+	Limit          int
+	Offset         int
+	Highlight      textsearch.Highlight
 }
@@ -2,6 +2,9 @@ package catalog
 
 import (
 	"bookapi/internal/httpx"
+	"bookapi/internal/platform/isbn"
+	"bookapi/internal/platform/textsearch"
+	"errors"
 	"net/http"
 	"strconv"
 )
@@ -20,12 +23,16 @@ func NewHTTPHandler(svc *Service) *HTTPHandler {
 // @Tags catalog
 // @Accept json
 // @Produce json
-// @Param q query string false "Search query"
+// @Param q query string false "Full-text search: \"exact phrase\", -exclude, a OR b, and qualifiers title: publisher: language: year: (1965, >1980, 1960..1970)"
 // @Param publisher query string false "Filter by publisher"
 // @Param language query string false "Filter by language"
+// @Param Accept-Language header string false "Language to parse the search in when no language filter is given (stemming and stop words)"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Items per page" default(20)
+// @Param highlight_start query string false "Marker inserted before matched words in highlights" default(<mark>)
+// @Param highlight_stop query string false "Marker inserted after matched words in highlights" default(</mark>)
 // @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
 // @Failure 500 {object} httpx.ErrorResponse
 // @Router /v1/catalog/search [get]
 func (h *HTTPHandler) Search(w http.ResponseWriter, r *http.Request) {
@@ -40,12 +47,48 @@ func (h *HTTPHandler) Search(w http.ResponseWriter, r *http.Request) {
 		pageSize = 20
 	}
 
+	for _, param := range []string{"highlight_start", "highlight_stop"} {
+		if err := textsearch.CheckMarker(query.Get(param)); err != nil {
+			httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", []httpx.ErrorDetail{
+				{Field: param, Message: err.Error()},
+			})
+			return
+		}
+	}
+
 	q := SearchQuery{
 		Q:         query.Get("q"),
 		Publisher: query.Get("publisher"),
 		Language:  query.Get("language"),
 		Limit:     pageSize,
 		Offset:    (page - 1) * pageSize,
+		Highlight: textsearch.Highlight{
+			StartSel: query.Get("highlight_start"),
+			StopSel:  query.Get("highlight_stop"),
+		},
+	}
+
+	if q.Q != "" {
+		parsed, err := textsearch.Parse(q.Q, textsearch.FieldTitle, textsearch.FieldPublisher, textsearch.FieldLanguage, textsearch.FieldYear)
+		if err != nil {
+			var syntaxErrs textsearch.SyntaxErrors
+			errors.As(err, &syntaxErrs)
+			details := make([]httpx.ErrorDetail, len(syntaxErrs))
+			for i, e := range syntaxErrs {
+				details[i] = httpx.ErrorDetail{Field: "q", Message: e.Error()}
+			}
+			httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid search query", details)
+			return
+		}
+		q.Q, q.Title, q.YearFrom, q.YearTo = parsed.Text, parsed.Title, parsed.YearFrom, parsed.YearTo
+		if parsed.Publisher != "" {
+			q.Publisher = parsed.Publisher
+		}
+		if parsed.Language != "" {
+			q.Language = parsed.Language
+		}
+		q.SearchLanguage = textsearch.QueryLanguage(q.Language, r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
 	}
 
 	books, total, err := h.svc.Search(r.Context(), q)
@@ -70,17 +113,24 @@ func (h *HTTPHandler) Search(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param isbn path string true "Book ISBN"
 // @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
 // @Failure 404 {object} httpx.ErrorResponse
 // @Failure 500 {object} httpx.ErrorResponse
 // @Router /v1/catalog/books/{isbn} [get]
 func (h *HTTPHandler) GetByISBN(w http.ResponseWriter, r *http.Request) {
-	isbn := r.PathValue("isbn")
-	if isbn == "" {
+	raw := r.PathValue("isbn")
+	if raw == "" {
 		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "ISBN is required", nil)
 		return
 	}
 
-	book, err := h.svc.GetByISBN(r.Context(), isbn)
+	isbn13, err := isbn.Canonical(raw)
+	if err != nil {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid ISBN", nil)
+		return
+	}
+
+	book, err := h.svc.GetByISBN(r.Context(), isbn13)
 	if err != nil {
 		httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Book not found in catalog", nil)
 		return
@@ -1,9 +1,11 @@
 package catalog
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -28,6 +30,42 @@ func TestHTTPHandler_Search(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 
+	t.Run("search qualifiers", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, q SearchQuery) ([]Book, int, error) {
+			assert.Equal(t, `"desert planet"`, q.Q)
+			assert.Equal(t, "ace", q.Publisher)
+			assert.Equal(t, 1960, *q.YearFrom)
+			assert.Equal(t, 1970, *q.YearTo)
+			return []Book{}, 0, nil
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/catalog/search?q="+url.QueryEscape(`"desert planet" pub:ace year:1960..1970`), nil)
+
+		handler.Search(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("unsupported qualifier", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/catalog/search?q="+url.QueryEscape("author:herbert"), nil)
+
+		handler.Search(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "author: is not supported here")
+	})
+
+	t.Run("invalid highlight marker", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/catalog/search?q=test&highlight_start=%22", nil)
+
+		handler.Search(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	t.Run("error", func(t *testing.T) {
 		mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, 0, errors.New("db error"))
 
@@ -48,16 +86,16 @@ func TestHTTPHandler_GetByISBN(t *testing.T) {
 	handler := NewHTTPHandler(service)
 
 	testBook := Book{
-		ISBN13: "1234567890123",
+		ISBN13: "9780306406157",
 		Title:  "Test Book",
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.EXPECT().GetByISBN(gomock.Any(), "1234567890123").Return(testBook, nil)
+		mockRepo.EXPECT().GetByISBN(gomock.Any(), "9780306406157").Return(testBook, nil)
 
 		w := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodGet, "/v1/catalog/books/1234567890123", nil)
-		r.SetPathValue("isbn", "1234567890123")
+		r := httptest.NewRequest(http.MethodGet, "/v1/catalog/books/9780306406157", nil)
+		r.SetPathValue("isbn", "9780306406157")
 
 		handler.GetByISBN(w, r)
 
@@ -65,14 +103,24 @@ func TestHTTPHandler_GetByISBN(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		mockRepo.EXPECT().GetByISBN(gomock.Any(), "1234567890123").Return(Book{}, errors.New("book not found: 1234567890123"))
+		mockRepo.EXPECT().GetByISBN(gomock.Any(), "9780306406157").Return(Book{}, errors.New("book not found: 9780306406157"))
 
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/catalog/books/9780306406157", nil)
+		r.SetPathValue("isbn", "9780306406157")
+
+		handler.GetByISBN(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid isbn", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/v1/catalog/books/1234567890123", nil)
 		r.SetPathValue("isbn", "1234567890123")
 
 		handler.GetByISBN(w, r)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
@@ -35,6 +35,21 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AuthorNames mocks base method.
+func (m *MockRepository) AuthorNames(ctx context.Context, keys []string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorNames", ctx, keys)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorNames indicates an expected call of AuthorNames.
+func (mr *MockRepositoryMockRecorder) AuthorNames(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorNames", reflect.TypeOf((*MockRepository)(nil).AuthorNames), ctx, keys)
+}
+
 // GetAuthorUpdatedAt mocks base method.
 func (m *MockRepository) GetAuthorUpdatedAt(ctx context.Context, key string) (time.Time, error) {
 	m.ctrl.T.Helper()
@@ -140,6 +155,20 @@ func (mr *MockRepositoryMockRecorder) UpsertAuthor(ctx, author, rawJSON interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAuthor", reflect.TypeOf((*MockRepository)(nil).UpsertAuthor), ctx, author, rawJSON)
 }
 
+// UpsertAuthors mocks base method.
+func (m *MockRepository) UpsertAuthors(ctx context.Context, authors []AuthorRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAuthors", ctx, authors)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertAuthors indicates an expected call of UpsertAuthors.
+func (mr *MockRepositoryMockRecorder) UpsertAuthors(ctx, authors interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAuthors", reflect.TypeOf((*MockRepository)(nil).UpsertAuthors), ctx, authors)
+}
+
 // UpsertBook mocks base method.
 func (m *MockRepository) UpsertBook(ctx context.Context, book *Book, rawJSON []byte) error {
 	m.ctrl.T.Helper()
@@ -153,3 +182,46 @@ func (mr *MockRepositoryMockRecorder) UpsertBook(ctx, book, rawJSON interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertBook", reflect.TypeOf((*MockRepository)(nil).UpsertBook), ctx, book, rawJSON)
 }
+
+// UpsertBooks mocks base method.
+func (m *MockRepository) UpsertBooks(ctx context.Context, books []BookRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertBooks", ctx, books)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertBooks indicates an expected call of UpsertBooks.
+func (mr *MockRepositoryMockRecorder) UpsertBooks(ctx, books interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertBooks", reflect.TypeOf((*MockRepository)(nil).UpsertBooks), ctx, books)
+}
+
+// UpsertWorks mocks base method.
+func (m *MockRepository) UpsertWorks(ctx context.Context, works []WorkRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorks", ctx, works)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertWorks indicates an expected call of UpsertWorks.
+func (mr *MockRepositoryMockRecorder) UpsertWorks(ctx, works interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorks", reflect.TypeOf((*MockRepository)(nil).UpsertWorks), ctx, works)
+}
+
+// Works mocks base method.
+func (m *MockRepository) Works(ctx context.Context, keys []string) (map[string]Work, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Works", ctx, keys)
+	ret0, _ := ret[0].(map[string]Work)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Works indicates an expected call of Works.
+func (mr *MockRepositoryMockRecorder) Works(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Works", reflect.TypeOf((*MockRepository)(nil).Works), ctx, keys)
+}
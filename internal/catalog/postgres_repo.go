@@ -7,6 +7,9 @@ import (
 	"strings"
 	"time"
 
+	"bookapi/internal/platform/isbn"
+	"bookapi/internal/platform/textsearch"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -14,6 +17,11 @@ import (
 type Repository interface {
 	UpsertBook(ctx context.Context, book *Book, rawJSON []byte) error
 	UpsertAuthor(ctx context.Context, author *Author, rawJSON []byte) error
+	UpsertBooks(ctx context.Context, books []BookRecord) error
+	UpsertAuthors(ctx context.Context, authors []AuthorRecord) error
+	UpsertWorks(ctx context.Context, works []WorkRecord) error
+	AuthorNames(ctx context.Context, keys []string) (map[string]string, error)
+	Works(ctx context.Context, keys []string) (map[string]Work, error)
 	GetTotalBooks(ctx context.Context) (int, error)
 	GetTotalAuthors(ctx context.Context) (int, error)
 	GetBookUpdatedAt(ctx context.Context, isbn13 string) (time.Time, error)
@@ -36,6 +44,12 @@ func (r *PostgresRepo) withTimeout(ctx context.Context) (context.Context, contex
 }
 
 func (r *PostgresRepo) UpsertBook(ctx context.Context, b *Book, rawJSON []byte) error {
+	isbn13, err := isbn.Canonical(b.ISBN13)
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, b.ISBN13)
+	}
+	b.ISBN13 = isbn13
+
 	timeoutCtx, cancel := r.withTimeout(ctx)
 	defer cancel()
 	tx, err := r.db.Begin(timeoutCtx)
@@ -116,6 +130,219 @@ func (r *PostgresRepo) UpsertAuthor(ctx context.Context, a *Author, rawJSON []by
 	return tx.Commit(timeoutCtx)
 }
 
+// UpsertBooks is UpsertBook for many books at once: they are copied into a
+// staging table with COPY and merged in one statement per table. Books
+// with an invalid ISBN must be filtered out by the caller; the last record
+// wins when an ISBN repeats. Like other bulk jobs it runs without the
+// repository timeout.
+func (r *PostgresRepo) UpsertBooks(ctx context.Context, books []BookRecord) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	rows := make([][]any, len(books))
+	for i, rec := range books {
+		b := rec.Book
+		rows[i] = []any{i, b.ISBN13, b.Title, b.Subtitle, b.Description, b.CoverURL, b.PublishedDate, b.Publisher, b.Language, b.PageCount, rec.RawJSON}
+	}
+
+	return r.mergeStaged(ctx, `
+		CREATE TEMP TABLE catalog_books_stage (
+			seq INT, isbn13 TEXT, title TEXT, subtitle TEXT, description TEXT, cover_url TEXT,
+			published_date TEXT, publisher TEXT, language TEXT, page_count INT, raw_json JSONB
+		) ON COMMIT DROP`,
+		"catalog_books_stage",
+		[]string{"seq", "isbn13", "title", "subtitle", "description", "cover_url", "published_date", "publisher", "language", "page_count", "raw_json"},
+		rows,
+		`
+		INSERT INTO catalog_books (isbn13, title, subtitle, description, cover_url, published_date, publisher, language, page_count, updated_at)
+		SELECT DISTINCT ON (isbn13) isbn13, title, subtitle, description, cover_url, published_date, publisher, language, page_count, now()
+		FROM catalog_books_stage
+		ORDER BY isbn13, seq DESC
+		ON CONFLICT (isbn13) DO UPDATE SET
+			title = EXCLUDED.title,
+			subtitle = EXCLUDED.subtitle,
+			description = EXCLUDED.description,
+			cover_url = EXCLUDED.cover_url,
+			published_date = EXCLUDED.published_date,
+			publisher = EXCLUDED.publisher,
+			language = EXCLUDED.language,
+			page_count = EXCLUDED.page_count,
+			updated_at = now()`,
+		`
+		INSERT INTO catalog_sources (entity_type, entity_key, provider, raw_json, fetched_at)
+		SELECT DISTINCT ON (isbn13) 'BOOK', isbn13, 'OPEN_LIBRARY', raw_json, now()
+		FROM catalog_books_stage
+		ORDER BY isbn13, seq DESC
+		ON CONFLICT (entity_type, entity_key, provider) DO UPDATE SET
+			raw_json = EXCLUDED.raw_json,
+			fetched_at = now()`,
+	)
+}
+
+// UpsertAuthors is UpsertAuthor for many authors at once, staged with COPY
+// like UpsertBooks.
+func (r *PostgresRepo) UpsertAuthors(ctx context.Context, authors []AuthorRecord) error {
+	if len(authors) == 0 {
+		return nil
+	}
+
+	rows := make([][]any, len(authors))
+	for i, rec := range authors {
+		a := rec.Author
+		rows[i] = []any{i, a.Key, a.Name, a.BirthDate, a.Bio, rec.RawJSON}
+	}
+
+	return r.mergeStaged(ctx, `
+		CREATE TEMP TABLE catalog_authors_stage (
+			seq INT, key TEXT, name TEXT, birth_date TEXT, bio TEXT, raw_json JSONB
+		) ON COMMIT DROP`,
+		"catalog_authors_stage",
+		[]string{"seq", "key", "name", "birth_date", "bio", "raw_json"},
+		rows,
+		`
+		INSERT INTO catalog_authors (key, name, birth_date, bio, updated_at)
+		SELECT DISTINCT ON (key) key, name, birth_date, bio, now()
+		FROM catalog_authors_stage
+		ORDER BY key, seq DESC
+		ON CONFLICT (key) DO UPDATE SET
+			name = EXCLUDED.name,
+			birth_date = EXCLUDED.birth_date,
+			bio = EXCLUDED.bio,
+			updated_at = now()`,
+		`
+		INSERT INTO catalog_sources (entity_type, entity_key, provider, raw_json, fetched_at)
+		SELECT DISTINCT ON (key) 'AUTHOR', key, 'OPEN_LIBRARY', raw_json, now()
+		FROM catalog_authors_stage
+		ORDER BY key, seq DESC
+		ON CONFLICT (entity_type, entity_key, provider) DO UPDATE SET
+			raw_json = EXCLUDED.raw_json,
+			fetched_at = now()`,
+	)
+}
+
+// UpsertWorks upserts works, staged with COPY like UpsertBooks.
+func (r *PostgresRepo) UpsertWorks(ctx context.Context, works []WorkRecord) error {
+	if len(works) == 0 {
+		return nil
+	}
+
+	rows := make([][]any, len(works))
+	for i, rec := range works {
+		w := rec.Work
+		subjects := w.Subjects
+		if subjects == nil {
+			subjects = []string{}
+		}
+		rows[i] = []any{i, w.Key, w.Title, subjects, w.Description, rec.RawJSON}
+	}
+
+	return r.mergeStaged(ctx, `
+		CREATE TEMP TABLE catalog_works_stage (
+			seq INT, key TEXT, title TEXT, subjects TEXT[], description TEXT, raw_json JSONB
+		) ON COMMIT DROP`,
+		"catalog_works_stage",
+		[]string{"seq", "key", "title", "subjects", "description", "raw_json"},
+		rows,
+		`
+		INSERT INTO catalog_works (key, title, subjects, description, updated_at)
+		SELECT DISTINCT ON (key) key, title, subjects, description, now()
+		FROM catalog_works_stage
+		ORDER BY key, seq DESC
+		ON CONFLICT (key) DO UPDATE SET
+			title = EXCLUDED.title,
+			subjects = EXCLUDED.subjects,
+			description = EXCLUDED.description,
+			updated_at = now()`,
+		`
+		INSERT INTO catalog_sources (entity_type, entity_key, provider, raw_json, fetched_at)
+		SELECT DISTINCT ON (key) 'WORK', key, 'OPEN_LIBRARY', raw_json, now()
+		FROM catalog_works_stage
+		ORDER BY key, seq DESC
+		ON CONFLICT (entity_type, entity_key, provider) DO UPDATE SET
+			raw_json = EXCLUDED.raw_json,
+			fetched_at = now()`,
+	)
+}
+
+// mergeStaged creates a staging table, copies rows into it and runs the
+// merge statements, all in one transaction.
+func (r *PostgresRepo) mergeStaged(ctx context.Context, createSQL, table string, columns []string, rows [][]any, mergeSQL ...string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, createSQL); err != nil {
+		return fmt.Errorf("create %s: %w", table, err)
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return fmt.Errorf("copy into %s: %w", table, err)
+	}
+	for _, sql := range mergeSQL {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("merge %s: %w", table, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// AuthorNames returns the names of the catalog authors with the given keys.
+// Unknown keys are left out.
+func (r *PostgresRepo) AuthorNames(ctx context.Context, keys []string) (map[string]string, error) {
+	names := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return names, nil
+	}
+
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, `SELECT key, name FROM catalog_authors WHERE key = ANY($1)`, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, name string
+		if err := rows.Scan(&key, &name); err != nil {
+			return nil, err
+		}
+		names[key] = name
+	}
+	return names, rows.Err()
+}
+
+// Works returns the catalog works with the given keys. Unknown keys are left
+// out.
+func (r *PostgresRepo) Works(ctx context.Context, keys []string) (map[string]Work, error) {
+	works := make(map[string]Work, len(keys))
+	if len(keys) == 0 {
+		return works, nil
+	}
+
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, `
+		SELECT key, title, subjects, COALESCE(description, ''), updated_at
+		FROM catalog_works
+		WHERE key = ANY($1)`, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w Work
+		if err := rows.Scan(&w.Key, &w.Title, &w.Subjects, &w.Description, &w.UpdatedAt); err != nil {
+			return nil, err
+		}
+		works[w.Key] = w
+	}
+	return works, rows.Err()
+}
+
 func (r *PostgresRepo) GetTotalBooks(ctx context.Context) (int, error) {
 	var count int
 	timeoutCtx, cancel := r.withTimeout(ctx)
@@ -133,6 +360,9 @@ func (r *PostgresRepo) GetTotalAuthors(ctx context.Context) (int, error) {
 }
 
 func (r *PostgresRepo) GetBookUpdatedAt(ctx context.Context, isbn13 string) (time.Time, error) {
+	if key, err := isbn.Canonical(isbn13); err == nil {
+		isbn13 = key
+	}
 	var t time.Time
 	timeoutCtx, cancel := r.withTimeout(ctx)
 	defer cancel()
@@ -158,6 +388,7 @@ func (r *PostgresRepo) List(ctx context.Context, q SearchQuery) ([]Book, int, er
 	clauses := []string{"1=1"}
 	args := []any{}
 	argn := 1
+	cfg := textsearch.Config(q.SearchLanguage)
 
 	if q.Publisher != "" {
 		clauses = append(clauses, fmt.Sprintf("publisher ILIKE $%d", argn))
@@ -171,9 +402,31 @@ func (r *PostgresRepo) List(ctx context.Context, q SearchQuery) ([]Book, int, er
 		argn++
 	}
 
+	if q.Title != "" {
+		clauses = append(clauses, fmt.Sprintf("to_tsvector('%[1]s', title) @@ phraseto_tsquery('%[1]s', $%[2]d)", cfg, argn))
+		args = append(args, q.Title)
+		argn++
+	}
+
+	// published_date is free text ("March 1965", "1965-03-01"); its first
+	// four-digit run is taken as the year.
+	if q.YearFrom != nil {
+		clauses = append(clauses, fmt.Sprintf(`substring(published_date from '\d{4}')::int >= $%d`, argn))
+		args = append(args, *q.YearFrom)
+		argn++
+	}
+
+	if q.YearTo != nil {
+		clauses = append(clauses, fmt.Sprintf(`substring(published_date from '\d{4}')::int <= $%d`, argn))
+		args = append(args, *q.YearTo)
+		argn++
+	}
+
+	searchArg := 0
 	if q.Q != "" {
-		clauses = append(clauses, fmt.Sprintf("search_vector @@ plainto_tsquery('english', $%d)", argn))
+		clauses = append(clauses, fmt.Sprintf("search_vector @@ websearch_to_tsquery('%s', $%d)", cfg, argn))
 		args = append(args, q.Q)
+		searchArg = argn
 		argn++
 	}
 
@@ -187,15 +440,26 @@ func (r *PostgresRepo) List(ctx context.Context, q SearchQuery) ([]Book, int, er
 		return nil, 0, err
 	}
 
+	argsWithPage := append([]any{}, args...)
+	highlightCols := "NULL::text, NULL::text"
+	if searchArg > 0 {
+		highlightCols = fmt.Sprintf(`
+		       ts_headline('%[4]s', title, websearch_to_tsquery('%[4]s', $%[1]d), $%[2]d),
+		       ts_headline('%[4]s', COALESCE(description, ''), websearch_to_tsquery('%[4]s', $%[1]d), $%[3]d)`,
+			searchArg, argn, argn+1, cfg)
+		argsWithPage = append(argsWithPage, q.Highlight.TitleOptions(), q.Highlight.SnippetOptions())
+		argn += 2
+	}
+
 	dataSQL := fmt.Sprintf(`
-		SELECT isbn13, title, subtitle, description, cover_url, published_date, publisher, language, page_count, updated_at
+		SELECT isbn13, title, subtitle, description, cover_url, published_date, publisher, language, page_count, updated_at,
+		       %s
 		FROM catalog_books
 		%s
 		ORDER BY title ASC
 		LIMIT $%d OFFSET $%d`,
-		where, argn, argn+1)
+		highlightCols, where, argn, argn+1)
 
-	argsWithPage := append([]any{}, args...)
 	argsWithPage = append(argsWithPage, q.Limit, q.Offset)
 	timeoutCtx2, cancel2 := r.withTimeout(ctx)
 	defer cancel2()
@@ -208,12 +472,20 @@ func (r *PostgresRepo) List(ctx context.Context, q SearchQuery) ([]Book, int, er
 	var out []Book
 	for rows.Next() {
 		var b Book
+		var titleHL, descHL *string
 		if err := rows.Scan(
 			&b.ISBN13, &b.Title, &b.Subtitle, &b.Description, &b.CoverURL,
 			&b.PublishedDate, &b.Publisher, &b.Language, &b.PageCount, &b.UpdatedAt,
+			&titleHL, &descHL,
 		); err != nil {
 			return nil, 0, err
 		}
+		if titleHL != nil {
+			b.Highlights = &textsearch.Highlights{Title: *titleHL}
+			if descHL != nil {
+				b.Highlights.Description = *descHL
+			}
+		}
 		out = append(out, b)
 	}
 	return out, total, rows.Err()
@@ -225,6 +497,9 @@ func (r *PostgresRepo) GetByISBN(ctx context.Context, isbn13 string) (Book, erro
 		FROM catalog_books
 		WHERE isbn13 = $1
 	`
+	if key, err := isbn.Canonical(isbn13); err == nil {
+		isbn13 = key
+	}
 	var b Book
 	timeoutCtx, cancel := r.withTimeout(ctx)
 	defer cancel()
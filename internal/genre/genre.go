@@ -0,0 +1,53 @@
+package genre
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a genre is not found.
+	ErrNotFound = errors.New("genre not found")
+	// ErrAliasExists is returned when an alias already maps to a genre.
+	ErrAliasExists = errors.New("alias already exists")
+	// ErrAliasNotFound is returned when a genre has no such alias.
+	ErrAliasNotFound = errors.New("alias not found")
+)
+
+// Genre is a node of the curated genre taxonomy. BookCount includes books
+// in descendant genres, each counted once.
+type Genre struct {
+	Slug       string   `json:"slug"`
+	Name       string   `json:"name"`
+	ParentSlug *string  `json:"parent_slug,omitempty"`
+	BookCount  int      `json:"book_count"`
+	Children   []*Genre `json:"children,omitempty"`
+}
+
+// Alias maps a raw subject string (or one of its comma-separated parts) to
+// a genre.
+type Alias struct {
+	Alias     string    `json:"alias"`
+	GenreSlug string    `json:"genre_slug"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NormalizeAlias puts an alias in the form subjects are matched in:
+// lower-case, underscores as spaces, single spaces.
+func NormalizeAlias(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), "_", " ")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+type Repository interface {
+	// List returns every genre, unnested, with subtree book counts.
+	List(ctx context.Context) ([]Genre, error)
+	Exists(ctx context.Context, slug string) (bool, error)
+	ListAliases(ctx context.Context, slug string) ([]Alias, error)
+	// AddAlias maps alias to the genre and remaps existing subjects.
+	AddAlias(ctx context.Context, slug, alias string) (Alias, error)
+	// DeleteAlias unmaps alias from the genre and remaps existing subjects.
+	DeleteAlias(ctx context.Context, slug, alias string) error
+}
@@ -0,0 +1,146 @@
+package genre
+
+import (
+	"bookapi/internal/httpx"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type HTTPHandler struct {
+	service *Service
+}
+
+func NewHTTPHandler(service *Service) *HTTPHandler {
+	return &HTTPHandler{service: service}
+}
+
+// List handles GET /genres
+// @Summary Genre taxonomy
+// @Description Get the genre tree. Book counts include books in descendant genres.
+// @Tags genres
+// @Accept json
+// @Produce json
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /genres [get]
+func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.service.Tree(r.Context())
+	if err != nil {
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	httpx.JSONSuccess(w, r, tree, nil)
+}
+
+// ListAliases handles GET /admin/genres/{slug}/aliases
+// @Summary List genre aliases
+// @Description List the subject aliases that map to a genre (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param slug path string true "Genre slug"
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 403 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /admin/genres/{slug}/aliases [get]
+func (h *HTTPHandler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	aliases, err := h.service.ListAliases(r.Context(), r.PathValue("slug"))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Genre not found", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, aliases, nil)
+}
+
+// addAliasReq rejects commas: subjects are matched part by part on them, so
+// an alias holding one could never match.
+type addAliasReq struct {
+	Alias string `json:"alias" validate:"required,max=100,excludes=0x2C"`
+}
+
+// AddAlias handles POST /admin/genres/{slug}/aliases
+// @Summary Add genre alias
+// @Description Map a subject string to a genre (admin only). Existing subjects are remapped.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param slug path string true "Genre slug"
+// @Param request body addAliasReq true "Alias"
+// @Success 201 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 403 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 409 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /admin/genres/{slug}/aliases [post]
+func (h *HTTPHandler) AddAlias(w http.ResponseWriter, r *http.Request) {
+	var req addAliasReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid request body", nil)
+		return
+	}
+	req.Alias = strings.TrimSpace(req.Alias)
+
+	if validationErrors := httpx.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", validationErrors)
+		return
+	}
+
+	alias, err := h.service.AddAlias(r.Context(), r.PathValue("slug"), req.Alias)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNotFound):
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Genre not found", nil)
+		case errors.Is(err, ErrAliasExists):
+			httpx.JSONError(w, r, http.StatusConflict, "ALREADY_EXISTS", "Alias already maps to a genre", nil)
+		default:
+			httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		}
+		return
+	}
+
+	httpx.JSONSuccessCreated(w, r, alias)
+}
+
+// DeleteAlias handles DELETE /admin/genres/{slug}/aliases/{alias}
+// @Summary Delete genre alias
+// @Description Unmap a subject string from a genre (admin only). Existing subjects are remapped.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param slug path string true "Genre slug"
+// @Param alias path string true "Alias"
+// @Success 204
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 403 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /admin/genres/{slug}/aliases/{alias} [delete]
+func (h *HTTPHandler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	err := h.service.DeleteAlias(r.Context(), r.PathValue("slug"), r.PathValue("alias"))
+	if err != nil {
+		if errors.Is(err, ErrAliasNotFound) {
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Alias not found", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccessNoContent(w)
+}
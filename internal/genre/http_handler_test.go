@@ -0,0 +1,138 @@
+package genre
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPHandler_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo)
+	handler := NewHTTPHandler(service)
+
+	fiction, speculative := "fiction", "speculative"
+	mockRepo.EXPECT().List(gomock.Any()).Return([]Genre{
+		{Slug: "fiction", Name: "Fiction", BookCount: 5},
+		{Slug: "science-fiction", Name: "Science Fiction", ParentSlug: &speculative, BookCount: 2},
+		{Slug: "speculative", Name: "Speculative Fiction", ParentSlug: &fiction, BookCount: 3},
+		{Slug: "nonfiction", Name: "Nonfiction", BookCount: 1},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/genres", nil)
+
+	handler.List(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data []Genre `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 2)
+	assert.Equal(t, "fiction", resp.Data[0].Slug)
+	require.Len(t, resp.Data[0].Children, 1)
+	assert.Equal(t, "speculative", resp.Data[0].Children[0].Slug)
+	require.Len(t, resp.Data[0].Children[0].Children, 1)
+	assert.Equal(t, 2, resp.Data[0].Children[0].Children[0].BookCount)
+	assert.Equal(t, "nonfiction", resp.Data[1].Slug)
+}
+
+func TestHTTPHandler_AddAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo)
+	handler := NewHTTPHandler(service)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().Exists(gomock.Any(), "science-fiction").Return(true, nil)
+		mockRepo.EXPECT().AddAlias(gomock.Any(), "science-fiction", "hard sf").Return(Alias{Alias: "hard sf", GenreSlug: "science-fiction"}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/admin/genres/science-fiction/aliases", strings.NewReader(`{"alias":"  Hard_SF "}`))
+		r.SetPathValue("slug", "science-fiction")
+
+		handler.AddAlias(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("comma is rejected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/admin/genres/science-fiction/aliases", strings.NewReader(`{"alias":"fiction, general"}`))
+		r.SetPathValue("slug", "science-fiction")
+
+		handler.AddAlias(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+	})
+
+	t.Run("unknown genre", func(t *testing.T) {
+		mockRepo.EXPECT().Exists(gomock.Any(), "cyberpunk").Return(false, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/admin/genres/cyberpunk/aliases", strings.NewReader(`{"alias":"cyberpunk"}`))
+		r.SetPathValue("slug", "cyberpunk")
+
+		handler.AddAlias(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("alias taken", func(t *testing.T) {
+		mockRepo.EXPECT().Exists(gomock.Any(), "fantasy").Return(true, nil)
+		mockRepo.EXPECT().AddAlias(gomock.Any(), "fantasy", "magic").Return(Alias{}, ErrAliasExists)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/admin/genres/fantasy/aliases", strings.NewReader(`{"alias":"magic"}`))
+		r.SetPathValue("slug", "fantasy")
+
+		handler.AddAlias(w, r)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestHTTPHandler_DeleteAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo)
+	handler := NewHTTPHandler(service)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteAlias(gomock.Any(), "fantasy", "dragons").Return(nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/admin/genres/fantasy/aliases/Dragons", nil)
+		r.SetPathValue("slug", "fantasy")
+		r.SetPathValue("alias", "Dragons")
+
+		handler.DeleteAlias(w, r)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteAlias(gomock.Any(), "fantasy", "elves").Return(ErrAliasNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/admin/genres/fantasy/aliases/elves", nil)
+		r.SetPathValue("slug", "fantasy")
+		r.SetPathValue("alias", "elves")
+
+		handler.DeleteAlias(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
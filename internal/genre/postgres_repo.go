@@ -0,0 +1,188 @@
+package genre
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+}
+
+func NewPostgresRepo(db *pgxpool.Pool, timeout time.Duration) *PostgresRepo {
+	return &PostgresRepo{db: db, timeout: timeout}
+}
+
+func (r *PostgresRepo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+func (r *PostgresRepo) List(ctx context.Context) ([]Genre, error) {
+	const query = `
+		SELECT g.slug, g.name, g.parent_slug, COUNT(DISTINCT bg.book_id)
+		FROM genres g
+		JOIN genre_closure gc ON gc.ancestor = g.slug
+		LEFT JOIN book_genres bg ON bg.genre_slug = gc.descendant
+		GROUP BY g.slug, g.name, g.parent_slug
+		ORDER BY g.name
+	`
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := []Genre{}
+	for rows.Next() {
+		var g Genre
+		if err := rows.Scan(&g.Slug, &g.Name, &g.ParentSlug, &g.BookCount); err != nil {
+			return nil, err
+		}
+		genres = append(genres, g)
+	}
+	return genres, rows.Err()
+}
+
+func (r *PostgresRepo) Exists(ctx context.Context, slug string) (bool, error) {
+	var ok bool
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	err := r.db.QueryRow(timeoutCtx, `SELECT EXISTS (SELECT 1 FROM genres WHERE slug = $1)`, slug).Scan(&ok)
+	return ok, err
+}
+
+func (r *PostgresRepo) ListAliases(ctx context.Context, slug string) ([]Alias, error) {
+	const query = `
+		SELECT alias, genre_slug, created_at
+		FROM genre_aliases
+		WHERE genre_slug = $1
+		ORDER BY alias
+	`
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, query, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := []Alias{}
+	for rows.Next() {
+		var a Alias
+		if err := rows.Scan(&a.Alias, &a.GenreSlug, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, a)
+	}
+	return aliases, rows.Err()
+}
+
+// remapSubjectsSQL re-derives the genre of the subjects with alias $1 among
+// their parts, the only ones an edit of that alias can change. The LIKE lets
+// subjects_name_trgm_idx narrow them down before the parts are compared.
+const remapSubjectsSQL = `
+	UPDATE subjects s SET genre_slug = m.slug
+	FROM (
+		SELECT id, genre_for_subject(name) AS slug
+		FROM subjects
+		WHERE lower(replace(name, '_', ' ')) LIKE '%' || $1 || '%'
+		  AND $1 = ANY(SELECT btrim(part) FROM unnest(string_to_array(lower(replace(name, '_', ' ')), ',')) AS part)
+	) m
+	WHERE s.id = m.id AND s.genre_slug IS DISTINCT FROM m.slug
+	RETURNING s.id`
+
+// remapBooksSQL re-derives the display genre of the books with one of the
+// subjects $1, from their first subject that maps to a genre.
+const remapBooksSQL = `
+	UPDATE books b SET genre = g.name
+	FROM (
+		SELECT DISTINCT ON (bs.book_id) bs.book_id, gn.name
+		FROM book_subjects bs
+		JOIN subjects s ON s.id = bs.subject_id
+		JOIN genres gn ON gn.slug = s.genre_slug
+		WHERE bs.book_id IN (SELECT book_id FROM book_subjects WHERE subject_id = ANY($1::uuid[]))
+		ORDER BY bs.book_id, bs.position
+	) g
+	WHERE b.id = g.book_id AND b.genre <> g.name`
+
+// remap applies an edit of alias to the subjects and books it affects, in
+// the transaction of the edit.
+func remap(ctx context.Context, tx pgx.Tx, alias string) error {
+	rows, err := tx.Query(ctx, remapSubjectsSQL, alias)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(ids) == 0 {
+		return err
+	}
+	_, err = tx.Exec(ctx, remapBooksSQL, ids)
+	return err
+}
+
+func (r *PostgresRepo) AddAlias(ctx context.Context, slug, alias string) (Alias, error) {
+	const query = `
+		INSERT INTO genre_aliases (alias, genre_slug)
+		VALUES ($1, $2)
+		RETURNING alias, genre_slug, created_at
+	`
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.Begin(timeoutCtx)
+	if err != nil {
+		return Alias{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var a Alias
+	if err := tx.QueryRow(timeoutCtx, query, alias, slug).Scan(&a.Alias, &a.GenreSlug, &a.CreatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return Alias{}, ErrAliasExists
+		}
+		return Alias{}, err
+	}
+	if err := remap(timeoutCtx, tx, alias); err != nil {
+		return Alias{}, err
+	}
+	return a, tx.Commit(timeoutCtx)
+}
+
+func (r *PostgresRepo) DeleteAlias(ctx context.Context, slug, alias string) error {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.Begin(timeoutCtx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(timeoutCtx, `DELETE FROM genre_aliases WHERE genre_slug = $1 AND alias = $2`, slug, alias)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAliasNotFound
+	}
+	if err := remap(timeoutCtx, tx, alias); err != nil {
+		return err
+	}
+	return tx.Commit(timeoutCtx)
+}
@@ -0,0 +1,81 @@
+package genre
+
+import (
+	"context"
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Tree returns the genre taxonomy as a forest of root genres, children
+// ordered as the repository lists them.
+func (s *Service) Tree(ctx context.Context) ([]*Genre, error) {
+	genres, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(genres), nil
+}
+
+// buildTree links genres to their parents. A genre whose parent is missing
+// is treated as a root rather than dropped.
+func buildTree(genres []Genre) []*Genre {
+	nodes := make(map[string]*Genre, len(genres))
+	for i := range genres {
+		nodes[genres[i].Slug] = &genres[i]
+	}
+	roots := []*Genre{}
+	for i := range genres {
+		g := &genres[i]
+		if g.ParentSlug != nil {
+			if parent, ok := nodes[*g.ParentSlug]; ok {
+				parent.Children = append(parent.Children, g)
+				continue
+			}
+		}
+		roots = append(roots, g)
+	}
+	return roots
+}
+
+// Exists reports whether slug names a genre of the taxonomy.
+func (s *Service) Exists(ctx context.Context, slug string) (bool, error) {
+	return s.repo.Exists(ctx, slug)
+}
+
+// ListAliases returns the aliases of a genre.
+func (s *Service) ListAliases(ctx context.Context, slug string) ([]Alias, error) {
+	if err := s.ensureExists(ctx, slug); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAliases(ctx, slug)
+}
+
+// AddAlias maps a normalized alias to a genre.
+func (s *Service) AddAlias(ctx context.Context, slug, alias string) (Alias, error) {
+	if err := s.ensureExists(ctx, slug); err != nil {
+		return Alias{}, err
+	}
+	return s.repo.AddAlias(ctx, slug, NormalizeAlias(alias))
+}
+
+// DeleteAlias removes an alias from a genre.
+func (s *Service) DeleteAlias(ctx context.Context, slug, alias string) error {
+	return s.repo.DeleteAlias(ctx, slug, NormalizeAlias(alias))
+}
+
+func (s *Service) ensureExists(ctx context.Context, slug string) error {
+	ok, err := s.repo.Exists(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package httpx
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// InternalSecretHeader carries the shared secret of the internal job routes.
+const InternalSecretHeader = "X-Internal-Secret"
+
+// AuthorizeInternal reports whether the request carries the internal jobs
+// secret, writing a 401 response when it does not. An empty secret matches
+// no request, so internal jobs fail closed when it is not configured.
+func AuthorizeInternal(w http.ResponseWriter, r *http.Request, secret string) bool {
+	got := r.Header.Get(InternalSecretHeader)
+	if secret == "" || subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+		JSONError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid internal secret", nil)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizeInternal(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		want   bool
+	}{
+		{"matching secret", "secret", "secret", true},
+		{"wrong secret", "secret", "wrong", false},
+		{"missing header", "secret", "", false},
+		{"unset secret fails closed", "", "", false},
+		{"unset secret rejects any header", "", "anything", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/internal/jobs/x", nil)
+			if tt.header != "" {
+				r.Header.Set(InternalSecretHeader, tt.header)
+			}
+
+			assert.Equal(t, tt.want, AuthorizeInternal(w, r, tt.secret))
+			if !tt.want {
+				assert.Equal(t, http.StatusUnauthorized, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Policy declares how a route is protected.
+type Policy struct {
+	// Auth requires a valid, non-revoked access token.
+	Auth bool
+	// Roles restricts access to the listed roles. A non-empty list implies Auth.
+	Roles []string
+	// RateLimit names the rate-limit class applied to the route. Empty means unlimited.
+	RateLimit string
+}
+
+// PolicyEnforcer turns a Policy into a middleware chain.
+type PolicyEnforcer struct {
+	auth     func(http.Handler) http.Handler
+	limiters map[string]*RateLimitMiddleware
+}
+
+func NewPolicyEnforcer(auth func(http.Handler) http.Handler, limiters map[string]*RateLimitMiddleware) *PolicyEnforcer {
+	return &PolicyEnforcer{auth: auth, limiters: limiters}
+}
+
+// Wrap applies the policy to h. Rate limiting runs first so that rejected
+// credentials still count against the limit, followed by authentication and
+// the role check. It panics on an unknown rate-limit class, like ServeMux does
+// for an invalid pattern, so misconfigured routes fail at startup.
+func (e *PolicyEnforcer) Wrap(p Policy, h http.Handler) http.Handler {
+	if len(p.Roles) > 0 {
+		h = RequireRole(p.Roles...)(h)
+	}
+	if p.Auth || len(p.Roles) > 0 {
+		h = e.auth(h)
+	}
+	if p.RateLimit != "" {
+		limiter, ok := e.limiters[p.RateLimit]
+		if !ok {
+			panic(fmt.Sprintf("httpx: unknown rate-limit class %q", p.RateLimit))
+		}
+		h = limiter.Middleware(h)
+	}
+	return h
+}
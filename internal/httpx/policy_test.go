@@ -0,0 +1,75 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAuth authenticates requests carrying an X-Test-Role header as that role.
+func fakeAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := r.Header.Get("X-Test-Role")
+		if role == "" {
+			JSONError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Unauthorized", nil)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithUser(r.Context(), "user-1", role)))
+	})
+}
+
+func TestPolicyEnforcer_Wrap(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	enforcer := NewPolicyEnforcer(fakeAuth, map[string]*RateLimitMiddleware{
+		"strict": NewRateLimitMiddleware(1, 1),
+	})
+
+	tests := []struct {
+		name   string
+		policy Policy
+		role   string
+		want   int
+	}{
+		{"public", Policy{}, "", http.StatusOK},
+		{"auth without token", Policy{Auth: true}, "", http.StatusUnauthorized},
+		{"auth with token", Policy{Auth: true}, "USER", http.StatusOK},
+		{"role without token", Policy{Roles: []string{"ADMIN"}}, "", http.StatusUnauthorized},
+		{"role mismatch", Policy{Roles: []string{"ADMIN"}}, "USER", http.StatusForbidden},
+		{"role match", Policy{Roles: []string{"ADMIN"}}, "ADMIN", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.role != "" {
+				r.Header.Set("X-Test-Role", tt.role)
+			}
+			enforcer.Wrap(tt.policy, ok).ServeHTTP(w, r)
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), `"code":"FORBIDDEN"`)
+			}
+		})
+	}
+
+	t.Run("rate limited", func(t *testing.T) {
+		h := enforcer.Wrap(Policy{RateLimit: "strict"}, ok)
+		codes := make([]int, 2)
+		for i := range codes {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			h.ServeHTTP(w, r)
+			codes[i] = w.Code
+		}
+		assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes)
+	})
+
+	t.Run("unknown rate-limit class", func(t *testing.T) {
+		assert.Panics(t, func() { enforcer.Wrap(Policy{RateLimit: "nope"}, ok) })
+	})
+}
@@ -0,0 +1,28 @@
+package httpx
+
+import (
+	"net/http"
+)
+
+// RequireRole rejects requests whose authenticated role is not one of the given roles.
+// It must run after AuthMiddleware, which puts the role in the request context.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if UserIDFrom(r) == "" {
+				JSONError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Unauthorized", nil)
+				return
+			}
+			if !allowed[RoleFrom(r)] {
+				JSONError(w, r, http.StatusForbidden, "FORBIDDEN", "Insufficient permissions", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strings"
 
+	"bookapi/internal/platform/isbn"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -18,19 +20,7 @@ func init() {
 }
 
 func validateISBN(fl validator.FieldLevel) bool {
-	isbn := fl.Field().String()
-	isbn = strings.ReplaceAll(isbn, "-", "")
-	isbn = strings.ReplaceAll(isbn, " ", "")
-
-	if len(isbn) == 10 {
-		matched, _ := regexp.MatchString(`^\d{9}[\dX]$`, isbn)
-		return matched
-	}
-	if len(isbn) == 13 {
-		matched, _ := regexp.MatchString(`^\d{13}$`, isbn)
-		return matched
-	}
-	return false
+	return isbn.Valid(fl.Field().String())
 }
 
 func validatePasswordStrength(fl validator.FieldLevel) bool {
@@ -70,7 +60,7 @@ func ValidateStruct(s interface{}) []ErrorDetail {
 		case "max":
 			message = fmt.Sprintf("%s must be at most %s characters", field, param)
 		case "isbn":
-			message = fmt.Sprintf("%s must be a valid ISBN (10 or 13 digits)", field)
+			message = fmt.Sprintf("%s must be a valid ISBN-10 or ISBN-13", field)
 		case "password_strength":
 			message = fmt.Sprintf("%s must be at least 8 characters with uppercase, lowercase, number, and special character", field)
 		case "gte", "lte":
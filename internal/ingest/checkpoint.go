@@ -0,0 +1,72 @@
+package ingest
+
+import "time"
+
+// Reset policies for subjects whose search results have all been read.
+const (
+	// ResetRestart starts the subject over at the first page on the next
+	// run, picking up works added since.
+	ResetRestart = "restart"
+	// ResetCooldown starts the subject over once Config.SubjectResetAfter
+	// has passed since its results ran out, skipping it until then.
+	ResetCooldown = "cooldown"
+	// ResetNever skips the subject for good.
+	ResetNever = "never"
+)
+
+// ValidateReset reports whether policy is a known reset policy.
+func ValidateReset(policy string) bool {
+	switch policy {
+	case ResetRestart, ResetCooldown, ResetNever:
+		return true
+	default:
+		return false
+	}
+}
+
+// Checkpoint records how far runs have read into a subject's search
+// results, so each run continues deeper and a crashed run resumes.
+type Checkpoint struct {
+	Subject string
+	// Offset is the index of the next search result to read.
+	Offset int
+	// Page is the number of pages read in the current pass.
+	Page int
+	// NumFound is the subject's result count when last read.
+	NumFound int
+	// Passes counts the times the subject's results were read to the end
+	// and started over.
+	Passes int
+	// ExhaustedAt is set when the last result was read.
+	ExhaustedAt *time.Time
+}
+
+// resume returns the checkpoint a run should read the subject from, and
+// false if the subject is to be skipped under policy.
+func (cp Checkpoint) resume(policy string, after time.Duration, now time.Time) (Checkpoint, bool) {
+	if cp.ExhaustedAt == nil {
+		return cp, true
+	}
+	switch policy {
+	case ResetNever:
+		return cp, false
+	case ResetCooldown:
+		if now.Sub(*cp.ExhaustedAt) < after {
+			return cp, false
+		}
+	}
+	cp.Offset, cp.Page, cp.ExhaustedAt = 0, 0, nil
+	cp.Passes++
+	return cp, true
+}
+
+// advance moves the checkpoint past the consumed results of a page of size
+// results, marking the subject exhausted when nothing is left to read.
+func (cp *Checkpoint) advance(consumed, size, numFound int, now time.Time) {
+	cp.Offset += consumed
+	cp.Page++
+	cp.NumFound = numFound
+	if consumed == size && (size == 0 || cp.Offset >= numFound) {
+		cp.ExhaustedAt = &now
+	}
+}
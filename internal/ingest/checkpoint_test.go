@@ -0,0 +1,51 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint_Advance(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	cp := Checkpoint{Subject: "history"}
+	cp.advance(100, 100, 250, now)
+	assert.Equal(t, 100, cp.Offset)
+	assert.Equal(t, 1, cp.Page)
+	assert.Nil(t, cp.ExhaustedAt)
+
+	// A page left early is not exhausted, even past NumFound.
+	cp.advance(40, 100, 120, now)
+	assert.Equal(t, 140, cp.Offset)
+	assert.Nil(t, cp.ExhaustedAt)
+
+	cp.advance(0, 0, 120, now)
+	assert.Equal(t, 140, cp.Offset)
+	assert.Equal(t, &now, cp.ExhaustedAt)
+}
+
+func TestCheckpoint_Resume(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	exhausted := now.Add(-48 * time.Hour)
+	done := Checkpoint{Subject: "history", Offset: 250, Page: 3, NumFound: 250, Passes: 1, ExhaustedAt: &exhausted}
+
+	cp, ok := Checkpoint{Subject: "history", Offset: 100}.resume(ResetNever, 0, now)
+	assert.True(t, ok)
+	assert.Equal(t, 100, cp.Offset, "unfinished subjects continue")
+
+	cp, ok = done.resume(ResetRestart, 0, now)
+	assert.True(t, ok)
+	assert.Equal(t, Checkpoint{Subject: "history", NumFound: 250, Passes: 2}, cp)
+
+	_, ok = done.resume(ResetNever, 0, now)
+	assert.False(t, ok)
+
+	_, ok = done.resume(ResetCooldown, 72*time.Hour, now)
+	assert.False(t, ok, "still cooling down")
+
+	cp, ok = done.resume(ResetCooldown, 24*time.Hour, now)
+	assert.True(t, ok)
+	assert.Zero(t, cp.Offset)
+}
@@ -0,0 +1,472 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+
+	"bookapi/internal/book"
+	"bookapi/internal/catalog"
+	"bookapi/internal/platform/openlibrary"
+	"bookapi/internal/platform/textsearch"
+)
+
+// DumpFilter selects the editions imported from a dump. Editions without a
+// valid ISBN are always skipped, as books are keyed by ISBN.
+type DumpFilter struct {
+	// Languages are language codes; an edition must be in one of them.
+	Languages []string
+	// Subjects are compared case-insensitively with the whole subjects of an
+	// edition and its work; one of them must equal one of those.
+	Subjects []string
+}
+
+// DumpStats counts what happened to the records of a dump.
+type DumpStats struct {
+	Read            int
+	Imported        int
+	Malformed       int
+	SkippedType     int
+	SkippedNoISBN   int
+	SkippedLanguage int
+	SkippedSubject  int
+	Failed          int
+}
+
+func (s DumpStats) String() string {
+	return fmt.Sprintf("read=%d imported=%d malformed=%d skipped_type=%d skipped_no_isbn=%d skipped_language=%d skipped_subject=%d failed=%d",
+		s.Read, s.Imported, s.Malformed, s.SkippedType, s.SkippedNoISBN, s.SkippedLanguage, s.SkippedSubject, s.Failed)
+}
+
+// DumpImporter loads Open Library bulk data dumps. Catalog rows are written
+// a batch at a time with COPY; books are then materialized through
+// book.Repository.UpsertFromIngest by a pool of workers, since each spans
+// works, authors, subjects and series.
+type DumpImporter struct {
+	catalogRepo catalog.Repository
+	bookRepo    book.Repository
+	filter      DumpFilter
+	batchSize   int
+	workers     int
+}
+
+func NewDumpImporter(catalogRepo catalog.Repository, bookRepo book.Repository, filter DumpFilter, batchSize, workers int) *DumpImporter {
+	var languages, subjects []string
+	for _, l := range filter.Languages {
+		if code := textsearch.LanguageCode(l); code != "" {
+			languages = append(languages, code)
+		}
+	}
+	for _, s := range filter.Subjects {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			subjects = append(subjects, s)
+		}
+	}
+	return &DumpImporter{
+		catalogRepo: catalogRepo,
+		bookRepo:    bookRepo,
+		filter:      DumpFilter{Languages: languages, Subjects: subjects},
+		batchSize:   max(batchSize, 1),
+		workers:     max(workers, 1),
+	}
+}
+
+// LoadAuthors upserts every author in an authors dump, so the editions
+// loaded after can name their credits.
+func (d *DumpImporter) LoadAuthors(ctx context.Context, r *openlibrary.DumpReader) (DumpStats, error) {
+	var stats DumpStats
+	batch := make([]catalog.AuthorRecord, 0, d.batchSize)
+	flush := func() error {
+		if err := d.catalogRepo.UpsertAuthors(ctx, batch); err != nil {
+			return err
+		}
+		stats.Imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := eachRecord(r, &stats, openlibrary.DumpTypeAuthor, func(rec openlibrary.DumpRecord) error {
+		var a openlibrary.DumpAuthor
+		if err := json.Unmarshal(rec.JSON, &a); err != nil || a.Name == "" {
+			stats.Malformed++
+			return nil
+		}
+		batch = append(batch, catalog.AuthorRecord{
+			Author: catalog.Author{
+				Key:       authorKey(rec.Key),
+				Name:      a.Name,
+				BirthDate: a.BirthDate,
+				Bio:       string(a.Bio),
+			},
+			RawJSON: rec.JSON,
+		})
+		if len(batch) >= d.batchSize {
+			return flush()
+		}
+		return ctx.Err()
+	})
+	if err == nil {
+		err = flush()
+	}
+	return stats, err
+}
+
+// LoadWorks upserts every work in a works dump into the catalog, so the
+// editions loaded after can inherit their work's subjects and description.
+// Works are not filtered, as the subject filter also applies to them through
+// their editions.
+func (d *DumpImporter) LoadWorks(ctx context.Context, r *openlibrary.DumpReader) (DumpStats, error) {
+	var stats DumpStats
+	batch := make([]catalog.WorkRecord, 0, d.batchSize)
+	flush := func() error {
+		if err := d.catalogRepo.UpsertWorks(ctx, batch); err != nil {
+			return err
+		}
+		stats.Imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := eachRecord(r, &stats, openlibrary.DumpTypeWork, func(rec openlibrary.DumpRecord) error {
+		var w openlibrary.DumpWork
+		if err := json.Unmarshal(rec.JSON, &w); err != nil {
+			stats.Malformed++
+			return nil
+		}
+		batch = append(batch, catalog.WorkRecord{
+			Work: catalog.Work{
+				Key:         rec.Key,
+				Title:       w.Title,
+				Subjects:    w.Subjects,
+				Description: string(w.Description),
+			},
+			RawJSON: rec.JSON,
+		})
+		if len(batch) >= d.batchSize {
+			return flush()
+		}
+		return ctx.Err()
+	})
+	if err == nil {
+		err = flush()
+	}
+	return stats, err
+}
+
+// LoadEditions imports the editions of an editions dump that pass the
+// filter, up to limit of them if limit is positive. The subject filter is
+// applied a batch at a time, once the batch's works are looked up.
+func (d *DumpImporter) LoadEditions(ctx context.Context, r *openlibrary.DumpReader, limit int) (DumpStats, error) {
+	var stats DumpStats
+	batch := make([]dumpEdition, 0, d.batchSize)
+	flush := func() error {
+		defer func() { batch = batch[:0] }()
+		accepted, err := d.withWorks(ctx, batch)
+		if err != nil {
+			return err
+		}
+		stats.SkippedSubject += len(batch) - len(accepted)
+		if limit > 0 {
+			accepted = accepted[:min(len(accepted), limit-stats.Imported-stats.Failed)]
+		}
+		imported, failed, err := d.importEditions(ctx, accepted)
+		stats.Imported += imported
+		stats.Failed += failed
+		return err
+	}
+
+	err := eachRecord(r, &stats, openlibrary.DumpTypeEdition, func(rec openlibrary.DumpRecord) error {
+		if limit > 0 && stats.Imported+stats.Failed >= limit {
+			return io.EOF
+		}
+		var e openlibrary.DumpEdition
+		if err := json.Unmarshal(rec.JSON, &e); err != nil {
+			stats.Malformed++
+			return nil
+		}
+		ed, reason := d.edition(e, rec.JSON)
+		switch reason {
+		case skipNoISBN:
+			stats.SkippedNoISBN++
+			return nil
+		case skipLanguage:
+			stats.SkippedLanguage++
+			return nil
+		}
+		batch = append(batch, ed)
+		if len(batch) >= d.batchSize {
+			return flush()
+		}
+		return ctx.Err()
+	})
+	if err == nil {
+		err = flush()
+	}
+	if stats.Imported > 0 {
+		if refreshErr := d.bookRepo.RefreshLexicon(ctx); refreshErr != nil && err == nil {
+			err = fmt.Errorf("refresh search lexicon: %w", refreshErr)
+		}
+	}
+	return stats, err
+}
+
+// eachRecord calls fn for each record of type typ until the dump ends or
+// fn returns an error. fn returning io.EOF stops early without error.
+func eachRecord(r *openlibrary.DumpReader, stats *DumpStats, typ string, fn func(openlibrary.DumpRecord) error) error {
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if errors.Is(err, openlibrary.ErrMalformedDump) {
+			stats.Read++
+			stats.Malformed++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		stats.Read++
+		if rec.Type != typ {
+			stats.SkippedType++
+			continue
+		}
+		if err := fn(rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+type skipReason int
+
+const (
+	skipNone skipReason = iota
+	skipNoISBN
+	skipLanguage
+)
+
+// dumpEdition is an edition mapped for import. It takes its work's subjects
+// and description once the batch's works are looked up, and its credits are
+// named once the batch's authors are.
+type dumpEdition struct {
+	record    catalog.BookRecord
+	workKey   string
+	subjects  []string
+	authors   []string
+	byLine    string
+	seriesRaw []string
+}
+
+// edition maps an edition record, or reports why the language filter
+// rejects it.
+func (d *DumpImporter) edition(e openlibrary.DumpEdition, raw []byte) (dumpEdition, skipReason) {
+	candidates := append(append([]string{}, e.ISBN13...), e.ISBN10...)
+	keys := editionISBNs(candidates, 1)
+	if len(keys) == 0 || strings.TrimSpace(e.Title) == "" {
+		return dumpEdition{}, skipNoISBN
+	}
+	key := keys[0]
+
+	var languages []string
+	for _, l := range e.Languages {
+		languages = append(languages, textsearch.LanguageCode(strings.TrimPrefix(l.Key, "/languages/")))
+	}
+	if !d.languageMatches(languages) {
+		return dumpEdition{}, skipLanguage
+	}
+
+	var workKey string
+	if len(e.Works) > 0 {
+		workKey = e.Works[0].Key
+	}
+	var coverURL string
+	if len(e.Covers) > 0 && e.Covers[0] > 0 {
+		coverURL = fmt.Sprintf("https://covers.openlibrary.org/b/id/%d-L.jpg", e.Covers[0])
+	}
+	language := ""
+	if len(languages) == 1 {
+		language = languages[0]
+	}
+
+	var authors []string
+	for _, a := range e.Authors {
+		if k := authorKey(a.Key); k != "" {
+			authors = append(authors, k)
+		}
+	}
+
+	return dumpEdition{
+		record: catalog.BookRecord{
+			Book: catalog.Book{
+				ISBN13:        key,
+				Title:         e.Title,
+				Subtitle:      e.Subtitle,
+				Description:   string(e.Description),
+				CoverURL:      coverURL,
+				PublishedDate: e.PublishDate,
+				Publisher:     strings.Join(e.Publishers, ", "),
+				Language:      language,
+				PageCount:     e.NumberOfPages,
+			},
+			RawJSON: raw,
+		},
+		workKey:   workKey,
+		subjects:  e.Subjects,
+		authors:   authors,
+		byLine:    e.ByStatement,
+		seriesRaw: e.Series,
+	}, skipNone
+}
+
+func (d *DumpImporter) languageMatches(languages []string) bool {
+	if len(d.filter.Languages) == 0 {
+		return true
+	}
+	for _, l := range languages {
+		for _, want := range d.filter.Languages {
+			if l == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// subjectMatches reports whether one of subjects is one of the filter's.
+// Whole subjects are compared, so "art" does not match "Martial arts".
+func (d *DumpImporter) subjectMatches(subjects []string) bool {
+	if len(d.filter.Subjects) == 0 {
+		return true
+	}
+	for _, s := range subjects {
+		s = strings.ToLower(strings.TrimSpace(s))
+		for _, want := range d.filter.Subjects {
+			if s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withWorks looks up the works of a batch, gives editions without subjects
+// or a description their work's, and returns the editions that pass the
+// subject filter on their own subjects or their work's.
+func (d *DumpImporter) withWorks(ctx context.Context, batch []dumpEdition) ([]dumpEdition, error) {
+	if len(batch) == 0 {
+		return nil, nil
+	}
+
+	var keys []string
+	for _, ed := range batch {
+		if ed.workKey != "" {
+			keys = append(keys, ed.workKey)
+		}
+	}
+	works, err := d.catalogRepo.Works(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("look up works: %w", err)
+	}
+
+	accepted := make([]dumpEdition, 0, len(batch))
+	for _, ed := range batch {
+		work := works[ed.workKey]
+		if !d.subjectMatches(ed.subjects) && !d.subjectMatches(work.Subjects) {
+			continue
+		}
+		if len(ed.subjects) == 0 {
+			ed.subjects = work.Subjects
+		}
+		if ed.record.Book.Description == "" {
+			ed.record.Book.Description = work.Description
+		}
+		accepted = append(accepted, ed)
+	}
+	return accepted, nil
+}
+
+// importEditions copies a batch into the catalog, then materializes its
+// books concurrently. It returns how many books were and were not
+// materialized.
+func (d *DumpImporter) importEditions(ctx context.Context, batch []dumpEdition) (int, int, error) {
+	if len(batch) == 0 {
+		return 0, 0, nil
+	}
+
+	records := make([]catalog.BookRecord, len(batch))
+	var keys []string
+	for i, ed := range batch {
+		records[i] = ed.record
+		keys = append(keys, ed.authors...)
+	}
+	if err := d.catalogRepo.UpsertBooks(ctx, records); err != nil {
+		return 0, 0, fmt.Errorf("upsert catalog books: %w", err)
+	}
+	names, err := d.catalogRepo.AuthorNames(ctx, keys)
+	if err != nil {
+		return 0, 0, fmt.Errorf("look up authors: %w", err)
+	}
+
+	var mu sync.Mutex
+	imported, failed := 0, 0
+	jobs := make(chan dumpEdition)
+	var wg sync.WaitGroup
+	for range d.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ed := range jobs {
+				err := upsertBook(ctx, d.bookRepo, d.appBook(ed, names))
+				mu.Lock()
+				if err != nil {
+					log.Printf("Failed to materialize book %s to books table: %v", ed.record.Book.ISBN13, err)
+					failed++
+				} else {
+					imported++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, ed := range batch {
+		jobs <- ed
+	}
+	close(jobs)
+	wg.Wait()
+	return imported, failed, ctx.Err()
+}
+
+func (d *DumpImporter) appBook(ed dumpEdition, names map[string]string) *book.Book {
+	cb := ed.record.Book
+	b := materialize(&cb, ed.workKey)
+	for _, subject := range ed.subjects {
+		if name := strings.TrimSpace(subject); name != "" {
+			b.Subjects = append(b.Subjects, name)
+		}
+	}
+	for _, key := range ed.authors {
+		name := names[key]
+		if name == "" {
+			continue
+		}
+		b.Authors = append(b.Authors, book.AuthorRef{
+			Key:  key,
+			Name: name,
+			Role: creditRole(name, ed.byLine),
+		})
+	}
+	for _, raw := range ed.seriesRaw {
+		if ref, ok := parseSeries(raw); ok {
+			b.SeriesMemberships = append(b.SeriesMemberships, ref)
+		}
+	}
+	return b
+}
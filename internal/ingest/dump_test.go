@@ -0,0 +1,168 @@
+package ingest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"bookapi/internal/book"
+	"bookapi/internal/catalog"
+	"bookapi/internal/platform/openlibrary"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func dumpReader(lines ...string) *openlibrary.DumpReader {
+	return openlibrary.NewDumpReader(strings.NewReader(strings.Join(lines, "\n")))
+}
+
+func TestDumpImporter_LoadAuthors(t *testing.T) {
+	ctx := context.Background()
+	catalogRepo := new(mockCatalogRepo)
+	importer := NewDumpImporter(catalogRepo, new(mockBookRepo), DumpFilter{}, 2, 1)
+
+	catalogRepo.On("UpsertAuthors", ctx, mock.MatchedBy(func(batch []catalog.AuthorRecord) bool {
+		return len(batch) == 2 && batch[0].Author.Key == "OL1A" && batch[0].Author.Bio == "Author of Dune."
+	})).Return(nil).Once()
+	catalogRepo.On("UpsertAuthors", ctx, mock.MatchedBy(func(batch []catalog.AuthorRecord) bool {
+		return len(batch) == 1 && batch[0].Author.Key == "OL3A"
+	})).Return(nil).Once()
+
+	stats, err := importer.LoadAuthors(ctx, dumpReader(
+		"/type/author\t/authors/OL1A\t1\t2024-01-01T00:00:00\t{\"name\": \"Frank Herbert\", \"bio\": {\"type\": \"/type/text\", \"value\": \"Author of Dune.\"}}",
+		"/type/author\t/authors/OL2A\t1\t2024-01-01T00:00:00\t{\"name\": \"Ursula K. Le Guin\"}",
+		"/type/redirect\t/authors/OL9A\t1\t2024-01-01T00:00:00\t{\"location\": \"/authors/OL1A\"}",
+		"/type/author\t/authors/OL4A\t1\t2024-01-01T00:00:00\t{}",
+		"/type/author\t/authors/OL3A\t1\t2024-01-01T00:00:00\t{\"name\": \"Octavia E. Butler\"}",
+	))
+
+	require.NoError(t, err)
+	assert.Equal(t, DumpStats{Read: 5, Imported: 3, Malformed: 1, SkippedType: 1}, stats)
+	catalogRepo.AssertExpectations(t)
+}
+
+func TestDumpImporter_LoadWorks(t *testing.T) {
+	ctx := context.Background()
+	catalogRepo := new(mockCatalogRepo)
+	importer := NewDumpImporter(catalogRepo, new(mockBookRepo), DumpFilter{Subjects: []string{"Science Fiction"}}, 10, 1)
+
+	// Works are stored whatever their subjects; editions are filtered.
+	catalogRepo.On("UpsertWorks", ctx, mock.MatchedBy(func(batch []catalog.WorkRecord) bool {
+		return len(batch) == 2 &&
+			batch[0].Work.Key == "/works/OL1W" &&
+			batch[0].Work.Description == "Spice." &&
+			len(batch[0].Work.Subjects) == 2 &&
+			batch[1].Work.Key == "/works/OL2W"
+	})).Return(nil).Once()
+
+	stats, err := importer.LoadWorks(ctx, dumpReader(
+		"/type/work\t/works/OL1W\t1\t2024-01-01T00:00:00\t{\"title\": \"Dune\", \"subjects\": [\"Science fiction\", \"Deserts\"], \"description\": \"Spice.\"}",
+		"/type/work\t/works/OL2W\t1\t2024-01-01T00:00:00\t{\"title\": \"Emma\", \"subjects\": [\"Courtship\"]}",
+		"/type/work\t/works/OL3W\t1\t2024-01-01T00:00:00\t{\"title\": ",
+	))
+
+	require.NoError(t, err)
+	assert.Equal(t, DumpStats{Read: 3, Imported: 2, Malformed: 1}, stats)
+	catalogRepo.AssertExpectations(t)
+}
+
+func TestDumpImporter_LoadEditions(t *testing.T) {
+	ctx := context.Background()
+	catalogRepo := new(mockCatalogRepo)
+	bookRepo := new(mockBookRepo)
+	importer := NewDumpImporter(catalogRepo, bookRepo, DumpFilter{
+		Languages: []string{"en"},
+		Subjects:  []string{"Science Fiction"},
+	}, 10, 2)
+
+	catalogRepo.On("Works", ctx, []string{"/works/OL1W", "/works/OL2W"}).Return(map[string]catalog.Work{
+		"/works/OL1W": {Key: "/works/OL1W", Title: "Dune", Subjects: []string{"Science fiction", "Deserts"}, Description: "Spice."},
+		"/works/OL2W": {Key: "/works/OL2W", Title: "Emma", Subjects: []string{"Courtship"}},
+	}, nil).Once()
+	catalogRepo.On("UpsertBooks", ctx, mock.MatchedBy(func(batch []catalog.BookRecord) bool {
+		return len(batch) == 1 &&
+			batch[0].Book.ISBN13 == "9780441172719" &&
+			batch[0].Book.CoverURL == "https://covers.openlibrary.org/b/id/42-L.jpg" &&
+			batch[0].Book.Description == "Spice." &&
+			batch[0].Book.Language == "en"
+	})).Return(nil).Once()
+	catalogRepo.On("AuthorNames", ctx, []string{"OL1A"}).Return(map[string]string{"OL1A": "Frank Herbert"}, nil).Once()
+	bookRepo.On("UpsertFromIngest", ctx, mock.MatchedBy(func(b *book.Book) bool {
+		return b.ISBN == "9780441172719" &&
+			b.WorkKey == "/works/OL1W" &&
+			b.Publisher == "Ace Books" &&
+			len(b.Subjects) == 2 &&
+			len(b.Authors) == 1 && b.Authors[0].Name == "Frank Herbert" && b.Authors[0].Role == book.RoleAuthor
+	})).Return(nil).Once()
+	bookRepo.On("RefreshLexicon", ctx).Return(nil).Once()
+
+	stats, err := importer.LoadEditions(ctx, dumpReader(
+		// Imported, inheriting the work's subjects and description.
+		"/type/edition\t/books/OL1M\t1\t2024-01-01T00:00:00\t{\"title\": \"Dune\", \"isbn_10\": [\"0441172717\"], \"publishers\": [\"Ace Books\"], \"languages\": [{\"key\": \"/languages/eng\"}], \"works\": [{\"key\": \"/works/OL1W\"}], \"authors\": [{\"key\": \"/authors/OL1A\"}], \"covers\": [42]}",
+		// No ISBN.
+		"/type/edition\t/books/OL2M\t1\t2024-01-01T00:00:00\t{\"title\": \"Dune\", \"isbn_13\": [\"123\"], \"languages\": [{\"key\": \"/languages/eng\"}], \"works\": [{\"key\": \"/works/OL1W\"}]}",
+		// Wrong language.
+		"/type/edition\t/books/OL3M\t1\t2024-01-01T00:00:00\t{\"title\": \"Dune\", \"isbn_13\": [\"9788497596824\"], \"languages\": [{\"key\": \"/languages/spa\"}], \"works\": [{\"key\": \"/works/OL1W\"}]}",
+		// Wrong subject.
+		"/type/edition\t/books/OL4M\t1\t2024-01-01T00:00:00\t{\"title\": \"Emma\", \"isbn_13\": [\"9780141439587\"], \"languages\": [{\"key\": \"/languages/eng\"}], \"works\": [{\"key\": \"/works/OL2W\"}]}",
+		"/type/edition\t/books/OL5M\t1\t2024-01-01T00:00:00\t{\"title\": ",
+	), 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, DumpStats{Read: 5, Imported: 1, Malformed: 1, SkippedNoISBN: 1, SkippedLanguage: 1, SkippedSubject: 1}, stats)
+	catalogRepo.AssertExpectations(t)
+	bookRepo.AssertExpectations(t)
+}
+
+func TestDumpImporter_LoadEditions_Deadlock(t *testing.T) {
+	ctx := context.Background()
+	edition := "/type/edition\t/books/OL1M\t1\t2024-01-01T00:00:00\t{\"title\": \"Dune\", \"isbn_13\": [\"9780441172719\"]}"
+	load := func(t *testing.T, bookRepo *mockBookRepo) DumpStats {
+		catalogRepo := new(mockCatalogRepo)
+		catalogRepo.On("Works", ctx, []string(nil)).Return(map[string]catalog.Work{}, nil).Once()
+		catalogRepo.On("UpsertBooks", ctx, mock.Anything).Return(nil).Once()
+		catalogRepo.On("AuthorNames", ctx, []string(nil)).Return(map[string]string{}, nil).Once()
+
+		stats, err := NewDumpImporter(catalogRepo, bookRepo, DumpFilter{}, 10, 1).LoadEditions(ctx, dumpReader(edition), 0)
+		require.NoError(t, err)
+		catalogRepo.AssertExpectations(t)
+		return stats
+	}
+
+	t.Run("retries the book", func(t *testing.T) {
+		bookRepo := new(mockBookRepo)
+		bookRepo.On("UpsertFromIngest", ctx, mock.Anything).Return(book.ErrDeadlock).Once()
+		bookRepo.On("UpsertFromIngest", ctx, mock.Anything).Return(nil).Once()
+		bookRepo.On("RefreshLexicon", ctx).Return(nil).Once()
+
+		assert.Equal(t, DumpStats{Read: 1, Imported: 1}, load(t, bookRepo))
+		bookRepo.AssertExpectations(t)
+	})
+
+	t.Run("gives up after a few attempts", func(t *testing.T) {
+		bookRepo := new(mockBookRepo)
+		bookRepo.On("UpsertFromIngest", ctx, mock.Anything).Return(book.ErrDeadlock).Times(upsertAttempts)
+
+		assert.Equal(t, DumpStats{Read: 1, Failed: 1}, load(t, bookRepo))
+		bookRepo.AssertExpectations(t)
+	})
+}
+
+func TestDumpImporter_LoadEditions_WholeSubjects(t *testing.T) {
+	ctx := context.Background()
+	catalogRepo := new(mockCatalogRepo)
+	importer := NewDumpImporter(catalogRepo, new(mockBookRepo), DumpFilter{Subjects: []string{"Art"}}, 10, 1)
+
+	catalogRepo.On("Works", ctx, []string(nil)).Return(map[string]catalog.Work{}, nil).Once()
+
+	stats, err := importer.LoadEditions(ctx, dumpReader(
+		"/type/edition\t/books/OL1M\t1\t2024-01-01T00:00:00\t{\"title\": \"Karate\", \"isbn_13\": [\"9780441172719\"], \"subjects\": [\"Martial arts\"]}",
+		"/type/edition\t/books/OL2M\t1\t2024-01-01T00:00:00\t{\"title\": \"Stuttgart\", \"isbn_13\": [\"9780141439587\"], \"subjects\": [\"Stuttgart (Germany)\"]}",
+	), 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, DumpStats{Read: 2, SkippedSubject: 2}, stats)
+	catalogRepo.AssertExpectations(t)
+}
@@ -1,12 +1,15 @@
 package ingest
 
 import (
-	"context"
+	"errors"
 	"log"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 
 	"bookapi/internal/httpx"
+
+	"github.com/google/uuid"
 )
 
 type HTTPHandler struct {
@@ -20,29 +23,221 @@ func NewHTTPHandler(svc *Service, secret string) *HTTPHandler {
 
 // Ingest handles POST /internal/jobs/ingest
 // @Summary Trigger catalog ingestion
-// @Description Trigger ingestion job to populate catalog from Open Library
+// @Description Trigger ingestion job to populate catalog from Open Library. Only one run executes at a time; while one is in progress the request is rejected with the active run ID.
 // @Tags internal
 // @Accept json
 // @Produce json
 // @Param X-Internal-Secret header string true "Internal secret for authentication"
 // @Success 202 {object} httpx.SuccessResponse
 // @Failure 401 {object} httpx.ErrorResponse
+// @Failure 409 {object} httpx.ErrorResponse
 // @Failure 500 {object} httpx.ErrorResponse
 // @Router /internal/jobs/ingest [post]
 func (h *HTTPHandler) Ingest(w http.ResponseWriter, r *http.Request) {
-	secret := r.Header.Get("X-Internal-Secret")
-	if h.secret != "" && secret != h.secret {
-		httpx.JSONError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid internal secret", nil)
+	if !h.authorized(w, r) {
+		return
+	}
+
+	runID, err := h.svc.Start(r.Context(), RunTimeout)
+	if err != nil {
+		if errors.Is(err, ErrRunInProgress) {
+			var details []httpx.ErrorDetail
+			if runID != "" {
+				details = []httpx.ErrorDetail{{Field: "run_id", Message: runID}}
+			}
+			httpx.JSONError(w, r, http.StatusConflict, "CONFLICT", "Ingestion already in progress", details)
+			return
+		}
+		log.Printf("ingest job failed to start: %v", err)
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccessAccepted(w, r, map[string]string{"message": "ingestion started", "run_id": runID}, nil)
+}
+
+// CancelRun handles POST /internal/jobs/ingest/runs/{id}/cancel
+// @Summary Cancel ingest run
+// @Description Cancel an ingestion run in progress. The run stops after its current request and is recorded as CANCELLED.
+// @Tags internal
+// @Produce json
+// @Param X-Internal-Secret header string true "Internal secret for authentication"
+// @Param id path string true "Run ID"
+// @Success 202 {object} httpx.SuccessResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 409 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /internal/jobs/ingest/runs/{id}/cancel [post]
+func (h *HTTPHandler) CancelRun(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-	go func() {
-		defer cancel()
-		if err := h.svc.Run(ctx); err != nil {
-			log.Printf("ingest job failed: %v", err)
+	id := r.PathValue("id")
+	if !h.validRunIDs(w, r, id) {
+		return
+	}
+
+	err := h.svc.Cancel(r.Context(), id)
+	switch {
+	case err == nil:
+		httpx.JSONSuccessAccepted(w, r, map[string]string{"message": "cancellation requested"}, nil)
+	case errors.Is(err, ErrRunNotRunning):
+		httpx.JSONError(w, r, http.StatusConflict, "CONFLICT", "Ingest run is not running", nil)
+	case errors.Is(err, ErrRunNotLocal):
+		httpx.JSONError(w, r, http.StatusConflict, "CONFLICT", "Ingest run is executing in another instance", nil)
+	default:
+		h.runError(w, r, err)
+	}
+}
+
+// authorized reports whether the request carries the internal secret,
+// writing the error response when it does not.
+func (h *HTTPHandler) authorized(w http.ResponseWriter, r *http.Request) bool {
+	return httpx.AuthorizeInternal(w, r, h.secret)
+}
+
+// validRunIDs reports whether every id is a UUID, writing a 404 response
+// when one is not, as no run can have it.
+func (h *HTTPHandler) validRunIDs(w http.ResponseWriter, r *http.Request, ids ...string) bool {
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			h.runError(w, r, ErrRunNotFound)
+			return false
 		}
-	}()
+	}
+	return true
+}
+
+// ListRuns handles GET /internal/jobs/ingest/runs
+// @Summary List ingest runs
+// @Description List ingestion runs, newest first, optionally filtered by status
+// @Tags internal
+// @Produce json
+// @Param X-Internal-Secret header string true "Internal secret for authentication"
+// @Param status query string false "Run status" Enums(RUNNING, COMPLETED, FAILED, CANCELLED, ABORTED)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Items per page" default(20)
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /internal/jobs/ingest/runs [get]
+func (h *HTTPHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	status := strings.ToUpper(query.Get("status"))
+	if status != "" && !ValidateStatus(status) {
+		httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid status", []httpx.ErrorDetail{
+			{Field: "status", Message: "must be one of RUNNING, COMPLETED, FAILED, CANCELLED, ABORTED"},
+		})
+		return
+	}
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	runs, total, err := h.svc.ListRuns(r.Context(), status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, runs, map[string]any{
+		"page":        page,
+		"page_size":   pageSize,
+		"total":       total,
+		"total_pages": (total + pageSize - 1) / pageSize,
+	})
+}
+
+// GetRun handles GET /internal/jobs/ingest/runs/{id}
+// @Summary Get ingest run
+// @Description Get an ingestion run with its counters, error, duration and the ISBNs and author keys it upserted
+// @Tags internal
+// @Produce json
+// @Param X-Internal-Secret header string true "Internal secret for authentication"
+// @Param id path string true "Run ID"
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /internal/jobs/ingest/runs/{id} [get]
+func (h *HTTPHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	if !h.validRunIDs(w, r, id) {
+		return
+	}
+
+	run, err := h.svc.GetRun(r.Context(), id)
+	if err != nil {
+		h.runError(w, r, err)
+		return
+	}
+	httpx.JSONSuccess(w, r, run, nil)
+}
+
+// CompareRuns handles GET /internal/jobs/ingest/runs/compare
+// @Summary Compare ingest runs
+// @Description Diff two ingestion runs: counter and duration deltas, changed configuration, and the books and authors only one of them upserted
+// @Tags internal
+// @Produce json
+// @Param X-Internal-Secret header string true "Internal secret for authentication"
+// @Param from query string true "Run ID to compare from"
+// @Param to query string true "Run ID to compare to"
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /internal/jobs/ingest/runs/compare [get]
+func (h *HTTPHandler) CompareRuns(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	from, to := query.Get("from"), query.Get("to")
+	var details []httpx.ErrorDetail
+	if from == "" {
+		details = append(details, httpx.ErrorDetail{Field: "from", Message: "is required"})
+	}
+	if to == "" {
+		details = append(details, httpx.ErrorDetail{Field: "to", Message: "is required"})
+	}
+	if len(details) > 0 {
+		httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Two run IDs are required", details)
+		return
+	}
+	if !h.validRunIDs(w, r, from, to) {
+		return
+	}
+
+	cmp, err := h.svc.CompareRuns(r.Context(), from, to)
+	if err != nil {
+		h.runError(w, r, err)
+		return
+	}
+	httpx.JSONSuccess(w, r, cmp, nil)
+}
 
-	httpx.JSONSuccessAccepted(w, r, map[string]string{"message": "ingestion started"}, nil)
+func (h *HTTPHandler) runError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrRunNotFound) {
+		httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Ingest run not found", nil)
+		return
+	}
+	httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
 }
@@ -0,0 +1,157 @@
+package ingest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Run IDs used by the tests below.
+const (
+	missingRun = "0f5d1c36-2d2a-4c43-9a43-6b0f0f8b6a11"
+	failedRun  = "5b7f5f0e-8d0e-4a9b-a0a4-3f1f8f6f3c22"
+)
+
+func TestHTTPHandler_ListRuns(t *testing.T) {
+	t.Run("invalid secret", func(t *testing.T) {
+		handler := NewHTTPHandler(NewService(nil, nil, nil, new(mockIngestRepo), Config{}), "secret")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/internal/jobs/ingest/runs", nil)
+		r.Header.Set("X-Internal-Secret", "wrong")
+
+		handler.ListRuns(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("unset secret fails closed", func(t *testing.T) {
+		handler := NewHTTPHandler(NewService(nil, nil, nil, new(mockIngestRepo), Config{}), "")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/internal/jobs/ingest/runs", nil)
+
+		handler.ListRuns(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		handler := NewHTTPHandler(NewService(nil, nil, nil, new(mockIngestRepo), Config{}), "secret")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/internal/jobs/ingest/runs?status=PAUSED", nil)
+		r.Header.Set("X-Internal-Secret", "secret")
+
+		handler.ListRuns(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("filters by status", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		mIngest.On("ListRuns", mock.Anything, StatusFailed, 10, 10).Return([]Run{{ID: "run-1", Status: StatusFailed}}, 11, nil)
+		handler := NewHTTPHandler(NewService(nil, nil, nil, mIngest, Config{}), "secret")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/internal/jobs/ingest/runs?status=failed&page=2&page_size=10", nil)
+		r.Header.Set("X-Internal-Secret", "secret")
+
+		handler.ListRuns(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Meta map[string]int `json:"meta"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, 2, body.Meta["total_pages"])
+		mIngest.AssertExpectations(t)
+	})
+}
+
+func TestHTTPHandler_GetRun(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		mIngest.On("GetRun", mock.Anything, missingRun).Return(Run{}, ErrRunNotFound)
+		handler := NewHTTPHandler(NewService(nil, nil, nil, mIngest, Config{}), "secret")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/internal/jobs/ingest/runs/"+missingRun, nil)
+		r.Header.Set("X-Internal-Secret", "secret")
+		r.SetPathValue("id", missingRun)
+
+		handler.GetRun(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("id is not a uuid", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		handler := NewHTTPHandler(NewService(nil, nil, nil, mIngest, Config{}), "secret")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/internal/jobs/ingest/runs/missing", nil)
+		r.Header.Set("X-Internal-Secret", "secret")
+		r.SetPathValue("id", "missing")
+
+		handler.GetRun(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mIngest.AssertNotCalled(t, "GetRun", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHTTPHandler_CompareRuns(t *testing.T) {
+	t.Run("missing run IDs", func(t *testing.T) {
+		handler := NewHTTPHandler(NewService(nil, nil, nil, new(mockIngestRepo), Config{}), "secret")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/internal/jobs/ingest/runs/compare?from=run-1", nil)
+		r.Header.Set("X-Internal-Secret", "secret")
+
+		handler.CompareRuns(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("run ID is not a uuid", func(t *testing.T) {
+		handler := NewHTTPHandler(NewService(nil, nil, nil, new(mockIngestRepo), Config{}), "secret")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/internal/jobs/ingest/runs/compare?from=run-1&to="+failedRun, nil)
+		r.Header.Set("X-Internal-Secret", "secret")
+
+		handler.CompareRuns(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHTTPHandler_Ingest(t *testing.T) {
+	t.Run("run already in progress", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		mIngest.On("TryLock", mock.Anything).Return(nil, false, nil)
+		mIngest.On("ActiveRun", mock.Anything).Return(Run{ID: "run-1", Status: StatusRunning}, nil)
+		handler := NewHTTPHandler(NewService(nil, nil, nil, mIngest, Config{}), "secret")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/internal/jobs/ingest", nil)
+		r.Header.Set("X-Internal-Secret", "secret")
+
+		handler.Ingest(w, r)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "run-1")
+	})
+}
+
+func TestHTTPHandler_CancelRun(t *testing.T) {
+	t.Run("run not running", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		mIngest.On("GetRun", mock.Anything, failedRun).Return(Run{ID: failedRun, Status: StatusFailed}, nil)
+		handler := NewHTTPHandler(NewService(nil, nil, nil, mIngest, Config{}), "secret")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/internal/jobs/ingest/runs/"+failedRun+"/cancel", nil)
+		r.Header.Set("X-Internal-Secret", "secret")
+		r.SetPathValue("id", failedRun)
+
+		handler.CancelRun(w, r)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
@@ -1,20 +1,110 @@
 package ingest
 
 import (
+	"errors"
 	"time"
 )
 
+var (
+	// ErrRunNotFound is returned when no ingest run has the given ID.
+	ErrRunNotFound = errors.New("ingest run not found")
+	// ErrRunInProgress is returned when a run is started while another one,
+	// in this or another instance, holds the ingest lock.
+	ErrRunInProgress = errors.New("ingest run already in progress")
+	// ErrRunNotRunning is returned when cancelling a run that has finished.
+	ErrRunNotRunning = errors.New("ingest run is not running")
+	// ErrRunNotLocal is returned when cancelling a run that another
+	// instance is executing.
+	ErrRunNotLocal = errors.New("ingest run is executing in another instance")
+	// ErrSlotTaken is returned when a scheduled run for the same schedule
+	// and time has already been started, usually by another replica.
+	ErrSlotTaken = errors.New("scheduled ingest run already started")
+)
+
+// RunTimeout bounds how long a run started over HTTP or by a schedule may
+// take.
+const RunTimeout = 30 * time.Minute
+
+// Run statuses.
+const (
+	StatusRunning   = "RUNNING"
+	StatusCompleted = "COMPLETED"
+	StatusFailed    = "FAILED"
+	// StatusCancelled marks a run stopped through the cancel endpoint.
+	StatusCancelled = "CANCELLED"
+	// StatusAborted marks a run whose process died before it finished.
+	StatusAborted = "ABORTED"
+)
+
+// ValidateStatus reports whether status is a known run status.
+func ValidateStatus(status string) bool {
+	switch status {
+	case StatusRunning, StatusCompleted, StatusFailed, StatusCancelled, StatusAborted:
+		return true
+	default:
+		return false
+	}
+}
+
 type Run struct {
-	ID               string
-	StartedAt        time.Time
-	FinishedAt       *time.Time
-	Status           string // RUNNING, COMPLETED, FAILED
-	ConfigBooksMax   int
-	ConfigAuthorsMax int
-	ConfigSubjects   string
-	BooksFetched     int
-	BooksUpserted    int
-	AuthorsFetched   int
-	AuthorsUpserted  int
-	Error            string
+	ID               string     `json:"id"`
+	StartedAt        time.Time  `json:"started_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	Status           string     `json:"status"` // RUNNING, COMPLETED, FAILED, CANCELLED, ABORTED
+	ConfigBooksMax   int        `json:"config_books_max"`
+	ConfigAuthorsMax int        `json:"config_authors_max"`
+	ConfigSubjects   string     `json:"config_subjects"`
+	BooksFetched     int        `json:"books_fetched"`
+	BooksUpserted    int        `json:"books_upserted"`
+	AuthorsFetched   int        `json:"authors_fetched"`
+	AuthorsUpserted  int        `json:"authors_upserted"`
+	Error            string     `json:"error,omitempty"`
+	// Schedule names the schedule that started the run, and ScheduledFor
+	// the time it was due; both are empty for runs triggered over HTTP.
+	Schedule     string     `json:"schedule,omitempty"`
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	// DurationSeconds is how long the run took, or has been running for.
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// Trigger describes what started a run.
+type Trigger struct {
+	// Schedule is the name of the schedule that fired, or empty.
+	Schedule     string
+	ScheduledFor time.Time
+	// Subjects replaces Config.Subjects for this run when not empty.
+	Subjects []string
+}
+
+// RunDetail is a run with the books and authors it upserted.
+type RunDetail struct {
+	Run
+	ISBNs      []string `json:"isbns"`
+	AuthorKeys []string `json:"author_keys"`
+}
+
+// RunComparison is the difference between two runs, To minus From.
+type RunComparison struct {
+	From          Run         `json:"from"`
+	To            Run         `json:"to"`
+	Counters      RunCounters `json:"counter_deltas"`
+	DurationDelta float64     `json:"duration_delta_seconds"`
+	ConfigChanged []string    `json:"config_changed"`
+	Books         KeysDiff    `json:"books"`
+	Authors       KeysDiff    `json:"authors"`
+}
+
+// RunCounters are the counters a run keeps.
+type RunCounters struct {
+	BooksFetched    int `json:"books_fetched"`
+	BooksUpserted   int `json:"books_upserted"`
+	AuthorsFetched  int `json:"authors_fetched"`
+	AuthorsUpserted int `json:"authors_upserted"`
+}
+
+// KeysDiff compares the ISBNs or author keys touched by two runs.
+type KeysDiff struct {
+	OnlyInFrom []string `json:"only_in_from"`
+	OnlyInTo   []string `json:"only_in_to"`
+	InBoth     int      `json:"in_both"`
 }
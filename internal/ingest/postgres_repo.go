@@ -2,8 +2,12 @@ package ingest
 
 import (
 	"context"
+	"errors"
+	"log"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,8 +16,28 @@ type Repository interface {
 	UpdateRun(ctx context.Context, run *Run) error
 	LinkBookToRun(ctx context.Context, runID string, isbn13 string) error
 	LinkAuthorToRun(ctx context.Context, runID string, authorKey string) error
+	ListRuns(ctx context.Context, status string, limit, offset int) ([]Run, int, error)
+	GetRun(ctx context.Context, id string) (Run, error)
+	RunBooks(ctx context.Context, runID string) ([]string, error)
+	RunAuthors(ctx context.Context, runID string) ([]string, error)
+	// TryLock takes the ingest lock without waiting. When it is acquired,
+	// unlock releases it; when another holder has it, acquired is false.
+	TryLock(ctx context.Context) (unlock func(), acquired bool, err error)
+	ActiveRun(ctx context.Context) (Run, error)
+	SaveProgress(ctx context.Context, run *Run) error
+	AbortRunning(ctx context.Context) (int, error)
+	// LastScheduled returns when the latest run of a schedule was due, or
+	// the zero time if it never ran.
+	LastScheduled(ctx context.Context, schedule string) (time.Time, error)
+	// GetCheckpoint returns the subject's checkpoint, or a fresh one if it
+	// was never read.
+	GetCheckpoint(ctx context.Context, subject string) (Checkpoint, error)
+	SaveCheckpoint(ctx context.Context, cp Checkpoint) error
 }
 
+// lockName identifies the advisory lock held while a run executes.
+const lockName = "bookapi.ingest"
+
 type PostgresRepo struct {
 	db      *pgxpool.Pool
 	timeout time.Duration
@@ -29,15 +53,22 @@ func (r *PostgresRepo) withTimeout(ctx context.Context) (context.Context, contex
 
 func (r *PostgresRepo) CreateRun(ctx context.Context, run *Run) (string, error) {
 	const sql = `
-		INSERT INTO ingest_runs (config_books_max, config_authors_max, config_subjects, status)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO ingest_runs (config_books_max, config_authors_max, config_subjects, status, schedule, scheduled_for)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
 		RETURNING id`
 
 	var id string
 	timeoutCtx, cancel := r.withTimeout(ctx)
 	defer cancel()
-	err := r.db.QueryRow(timeoutCtx, sql, run.ConfigBooksMax, run.ConfigAuthorsMax, run.ConfigSubjects, run.Status).Scan(&id)
-	return id, err
+	err := r.db.QueryRow(timeoutCtx, sql, run.ConfigBooksMax, run.ConfigAuthorsMax, run.ConfigSubjects, run.Status, run.Schedule, run.ScheduledFor).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return "", ErrSlotTaken
+		}
+		return "", err
+	}
+	return id, nil
 }
 
 func (r *PostgresRepo) UpdateRun(ctx context.Context, run *Run) error {
@@ -79,3 +110,227 @@ func (r *PostgresRepo) LinkAuthorToRun(ctx context.Context, runID string, author
 	_, err := r.db.Exec(timeoutCtx, sql, runID, authorKey)
 	return err
 }
+
+// runColumnsSQL selects the columns scanned by scanRun. Runs still going
+// report how long they have been running for.
+const runColumnsSQL = `
+		SELECT id, started_at, finished_at, status, config_books_max, config_authors_max,
+		       config_subjects, books_fetched, books_upserted, authors_fetched, authors_upserted,
+		       COALESCE(error, ''), COALESCE(schedule, ''), scheduled_for,
+		       EXTRACT(EPOCH FROM COALESCE(finished_at, now()) - started_at)::float8
+		FROM ingest_runs`
+
+func scanRun(row pgx.Row) (Run, error) {
+	var run Run
+	err := row.Scan(
+		&run.ID, &run.StartedAt, &run.FinishedAt, &run.Status, &run.ConfigBooksMax, &run.ConfigAuthorsMax,
+		&run.ConfigSubjects, &run.BooksFetched, &run.BooksUpserted, &run.AuthorsFetched, &run.AuthorsUpserted,
+		&run.Error, &run.Schedule, &run.ScheduledFor, &run.DurationSeconds,
+	)
+	return run, err
+}
+
+// ListRuns returns runs newest first, optionally only those with the given
+// status, and the total number matching.
+func (r *PostgresRepo) ListRuns(ctx context.Context, status string, limit, offset int) ([]Run, int, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var total int
+	err := r.db.QueryRow(timeoutCtx, `SELECT COUNT(*) FROM ingest_runs WHERE ($1 = '' OR status = $1)`, status).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(timeoutCtx, runColumnsSQL+`
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY started_at DESC, id
+		LIMIT $2 OFFSET $3`, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	runs := []Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, total, rows.Err()
+}
+
+func (r *PostgresRepo) GetRun(ctx context.Context, id string) (Run, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	run, err := scanRun(r.db.QueryRow(timeoutCtx, runColumnsSQL+` WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Run{}, ErrRunNotFound
+	}
+	return run, err
+}
+
+// RunBooks returns the ISBN-13s a run upserted, in order.
+func (r *PostgresRepo) RunBooks(ctx context.Context, runID string) ([]string, error) {
+	return r.runKeys(ctx, `SELECT isbn13 FROM ingest_run_books WHERE run_id = $1 ORDER BY isbn13`, runID)
+}
+
+// RunAuthors returns the Open Library keys of the authors a run upserted,
+// in order.
+func (r *PostgresRepo) RunAuthors(ctx context.Context, runID string) ([]string, error) {
+	return r.runKeys(ctx, `SELECT author_key FROM ingest_run_authors WHERE run_id = $1 ORDER BY author_key`, runID)
+}
+
+func (r *PostgresRepo) runKeys(ctx context.Context, query, runID string) ([]string, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.Query(timeoutCtx, query, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// TryLock takes a session-level advisory lock on a connection set aside
+// from the pool, so it is held for as long as the run lasts and released by
+// Postgres if the process dies.
+func (r *PostgresRepo) TryLock(ctx context.Context) (func(), bool, error) {
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, lockName).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, false, err
+	}
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		defer cancel()
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, lockName); err != nil {
+			// Closing the connection ends the session, releasing the lock.
+			log.Printf("Failed to release ingest lock: %v", err)
+			_ = conn.Hijack().Close(ctx)
+			return
+		}
+		conn.Release()
+	}
+	return unlock, true, nil
+}
+
+// ActiveRun returns the most recently started RUNNING run.
+func (r *PostgresRepo) ActiveRun(ctx context.Context) (Run, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	run, err := scanRun(r.db.QueryRow(timeoutCtx, runColumnsSQL+`
+		WHERE status = 'RUNNING'
+		ORDER BY started_at DESC
+		LIMIT 1`))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Run{}, ErrRunNotFound
+	}
+	return run, err
+}
+
+// SaveProgress writes the counters of a run still in progress.
+func (r *PostgresRepo) SaveProgress(ctx context.Context, run *Run) error {
+	const sql = `
+		UPDATE ingest_runs SET
+			books_fetched = $1,
+			books_upserted = $2,
+			authors_fetched = $3,
+			authors_upserted = $4
+		WHERE id = $5 AND status = 'RUNNING'`
+
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.Exec(timeoutCtx, sql, run.BooksFetched, run.BooksUpserted, run.AuthorsFetched, run.AuthorsUpserted, run.ID)
+	return err
+}
+
+// AbortRunning marks every RUNNING run as ABORTED. It must only be called
+// while holding the ingest lock, when no run can really be in progress.
+func (r *PostgresRepo) AbortRunning(ctx context.Context) (int, error) {
+	const sql = `
+		UPDATE ingest_runs SET
+			status = 'ABORTED',
+			finished_at = now(),
+			error = 'process stopped before the run finished'
+		WHERE status = 'RUNNING'`
+
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tag, err := r.db.Exec(timeoutCtx, sql)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *PostgresRepo) LastScheduled(ctx context.Context, schedule string) (time.Time, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var last *time.Time
+	err := r.db.QueryRow(timeoutCtx, `SELECT MAX(scheduled_for) FROM ingest_runs WHERE schedule = $1`, schedule).Scan(&last)
+	if err != nil || last == nil {
+		return time.Time{}, err
+	}
+	return *last, nil
+}
+
+func (r *PostgresRepo) GetCheckpoint(ctx context.Context, subject string) (Checkpoint, error) {
+	const sql = `
+		SELECT next_offset, page, num_found, passes, exhausted_at
+		FROM ingest_subject_checkpoints
+		WHERE subject = $1`
+
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	cp := Checkpoint{Subject: subject}
+	err := r.db.QueryRow(timeoutCtx, sql, subject).Scan(&cp.Offset, &cp.Page, &cp.NumFound, &cp.Passes, &cp.ExhaustedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return Checkpoint{}, err
+	}
+	return cp, nil
+}
+
+func (r *PostgresRepo) SaveCheckpoint(ctx context.Context, cp Checkpoint) error {
+	const sql = `
+		INSERT INTO ingest_subject_checkpoints (subject, next_offset, page, num_found, passes, exhausted_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, now())
+		ON CONFLICT (subject) DO UPDATE SET
+			next_offset = EXCLUDED.next_offset,
+			page = EXCLUDED.page,
+			num_found = EXCLUDED.num_found,
+			passes = EXCLUDED.passes,
+			exhausted_at = EXCLUDED.exhausted_at,
+			updated_at = now()`
+
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.Exec(timeoutCtx, sql, cp.Subject, cp.Offset, cp.Page, cp.NumFound, cp.Passes, cp.ExhaustedAt)
+	return err
+}
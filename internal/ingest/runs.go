@@ -0,0 +1,101 @@
+package ingest
+
+import (
+	"context"
+	"sort"
+)
+
+// ListRuns returns a page of runs, newest first, optionally only those with
+// the given status, and the total number matching.
+func (s *Service) ListRuns(ctx context.Context, status string, limit, offset int) ([]Run, int, error) {
+	return s.ingestRepo.ListRuns(ctx, status, limit, offset)
+}
+
+// GetRun returns a run with the ISBNs and author keys it upserted.
+func (s *Service) GetRun(ctx context.Context, id string) (RunDetail, error) {
+	run, err := s.ingestRepo.GetRun(ctx, id)
+	if err != nil {
+		return RunDetail{}, err
+	}
+	isbns, err := s.ingestRepo.RunBooks(ctx, run.ID)
+	if err != nil {
+		return RunDetail{}, err
+	}
+	authors, err := s.ingestRepo.RunAuthors(ctx, run.ID)
+	if err != nil {
+		return RunDetail{}, err
+	}
+	return RunDetail{Run: run, ISBNs: isbns, AuthorKeys: authors}, nil
+}
+
+// CompareRuns diffs two runs: how their counters, duration and
+// configuration changed, and which books and authors only one of them
+// touched.
+func (s *Service) CompareRuns(ctx context.Context, fromID, toID string) (RunComparison, error) {
+	from, err := s.GetRun(ctx, fromID)
+	if err != nil {
+		return RunComparison{}, err
+	}
+	to, err := s.GetRun(ctx, toID)
+	if err != nil {
+		return RunComparison{}, err
+	}
+	return compareRuns(from, to), nil
+}
+
+func compareRuns(from, to RunDetail) RunComparison {
+	cmp := RunComparison{
+		From: from.Run,
+		To:   to.Run,
+		Counters: RunCounters{
+			BooksFetched:    to.BooksFetched - from.BooksFetched,
+			BooksUpserted:   to.BooksUpserted - from.BooksUpserted,
+			AuthorsFetched:  to.AuthorsFetched - from.AuthorsFetched,
+			AuthorsUpserted: to.AuthorsUpserted - from.AuthorsUpserted,
+		},
+		DurationDelta: to.DurationSeconds - from.DurationSeconds,
+		ConfigChanged: []string{},
+		Books:         diffKeys(from.ISBNs, to.ISBNs),
+		Authors:       diffKeys(from.AuthorKeys, to.AuthorKeys),
+	}
+	if from.ConfigBooksMax != to.ConfigBooksMax {
+		cmp.ConfigChanged = append(cmp.ConfigChanged, "config_books_max")
+	}
+	if from.ConfigAuthorsMax != to.ConfigAuthorsMax {
+		cmp.ConfigChanged = append(cmp.ConfigChanged, "config_authors_max")
+	}
+	if from.ConfigSubjects != to.ConfigSubjects {
+		cmp.ConfigChanged = append(cmp.ConfigChanged, "config_subjects")
+	}
+	return cmp
+}
+
+// diffKeys splits two key sets into those only in one of them, sorted, and
+// counts those in both.
+func diffKeys(from, to []string) KeysDiff {
+	inFrom := make(map[string]bool, len(from))
+	for _, k := range from {
+		inFrom[k] = true
+	}
+	inTo := make(map[string]bool, len(to))
+	for _, k := range to {
+		inTo[k] = true
+	}
+
+	diff := KeysDiff{OnlyInFrom: []string{}, OnlyInTo: []string{}}
+	for k := range inFrom {
+		if inTo[k] {
+			diff.InBoth++
+		} else {
+			diff.OnlyInFrom = append(diff.OnlyInFrom, k)
+		}
+	}
+	for k := range inTo {
+		if !inFrom[k] {
+			diff.OnlyInTo = append(diff.OnlyInTo, k)
+		}
+	}
+	sort.Strings(diff.OnlyInFrom)
+	sort.Strings(diff.OnlyInTo)
+	return diff
+}
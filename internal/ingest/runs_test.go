@@ -0,0 +1,34 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareRuns(t *testing.T) {
+	from := RunDetail{
+		Run: Run{
+			ID: "run-1", ConfigBooksMax: 100, ConfigAuthorsMax: 50, ConfigSubjects: "fiction",
+			BooksFetched: 40, BooksUpserted: 30, AuthorsFetched: 20, AuthorsUpserted: 10, DurationSeconds: 60,
+		},
+		ISBNs:      []string{"9780000000001", "9780000000002"},
+		AuthorKeys: []string{"OL1A"},
+	}
+	to := RunDetail{
+		Run: Run{
+			ID: "run-2", ConfigBooksMax: 200, ConfigAuthorsMax: 50, ConfigSubjects: "fiction,history",
+			BooksFetched: 50, BooksUpserted: 25, AuthorsFetched: 20, AuthorsUpserted: 12, DurationSeconds: 45.5,
+		},
+		ISBNs:      []string{"9780000000003", "9780000000002"},
+		AuthorKeys: []string{"OL1A"},
+	}
+
+	cmp := compareRuns(from, to)
+
+	assert.Equal(t, RunCounters{BooksFetched: 10, BooksUpserted: -5, AuthorsFetched: 0, AuthorsUpserted: 2}, cmp.Counters)
+	assert.Equal(t, -14.5, cmp.DurationDelta)
+	assert.Equal(t, []string{"config_books_max", "config_subjects"}, cmp.ConfigChanged)
+	assert.Equal(t, KeysDiff{OnlyInFrom: []string{"9780000000001"}, OnlyInTo: []string{"9780000000003"}, InBoth: 1}, cmp.Books)
+	assert.Equal(t, KeysDiff{OnlyInFrom: []string{}, OnlyInTo: []string{}, InBoth: 1}, cmp.Authors)
+}
@@ -0,0 +1,188 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"bookapi/internal/platform/cron"
+)
+
+// Catch-up policies for schedule slots missed while no replica was up.
+const (
+	// CatchUpSkip ignores missed slots and waits for the next one.
+	CatchUpSkip = "skip"
+	// CatchUpOnce runs once at startup for the latest missed slot of each
+	// schedule, however many were missed.
+	CatchUpOnce = "once"
+)
+
+// ValidateCatchUp reports whether policy is a known catch-up policy.
+func ValidateCatchUp(policy string) bool {
+	return policy == CatchUpSkip || policy == CatchUpOnce
+}
+
+// Schedule is a named cron expression with the subjects its runs ingest.
+type Schedule struct {
+	Name string
+	Expr string
+	// Subjects replaces Config.Subjects for the schedule's runs when not
+	// empty.
+	Subjects []string
+
+	cron cron.Schedule
+}
+
+// ParseSchedules parses schedules written as "name=cron expression", each
+// optionally followed by "|" and comma-separated subjects, and separated by
+// ";". For example:
+//
+//	weekly=0 0 * * 0;history=0 3 * * 3|history,biography
+func ParseSchedules(spec string) ([]Schedule, error) {
+	var out []Schedule
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("schedule %q: want name=cron expression", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("schedule %q: defined twice", name)
+		}
+		seen[name] = true
+
+		expr, subjectList, _ := strings.Cut(rest, "|")
+		expr = strings.TrimSpace(expr)
+		c, err := cron.Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", name, err)
+		}
+
+		var subjects []string
+		for _, subject := range strings.Split(subjectList, ",") {
+			if subject = strings.TrimSpace(subject); subject != "" {
+				subjects = append(subjects, subject)
+			}
+		}
+		out = append(out, Schedule{Name: name, Expr: expr, Subjects: subjects, cron: c})
+	}
+	return out, nil
+}
+
+// Scheduler starts ingest runs on cron schedules, evaluated in UTC. Every
+// replica may run one: the ingest lock and the one run per schedule slot
+// kept by ingest_runs make sure only one of them fires. A slot due while a
+// run is in progress is skipped, not queued.
+type Scheduler struct {
+	svc       *Service
+	schedules []Schedule
+	catchUp   string
+	timeout   time.Duration
+	now       func() time.Time
+}
+
+func NewScheduler(svc *Service, schedules []Schedule, catchUp string, timeout time.Duration) *Scheduler {
+	return &Scheduler{
+		svc:       svc,
+		schedules: schedules,
+		catchUp:   catchUp,
+		timeout:   timeout,
+		now:       time.Now,
+	}
+}
+
+// Run fires the schedules until ctx is done.
+func (sc *Scheduler) Run(ctx context.Context) {
+	if sc.catchUp == CatchUpOnce {
+		sc.catchUpMissed(ctx)
+	}
+
+	next := make([]time.Time, len(sc.schedules))
+	now := sc.now().UTC()
+	for i, sch := range sc.schedules {
+		next[i] = sch.cron.Next(now)
+	}
+
+	for {
+		var due time.Time
+		for _, t := range next {
+			if !t.IsZero() && (due.IsZero() || t.Before(due)) {
+				due = t
+			}
+		}
+		if due.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(due.Sub(sc.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for i, sch := range sc.schedules {
+			if !next[i].IsZero() && !next[i].After(due) {
+				sc.fire(ctx, sch, next[i])
+				next[i] = sch.cron.Next(due)
+			}
+		}
+	}
+}
+
+// catchUpMissed fires each schedule whose last slot passed without a run.
+func (sc *Scheduler) catchUpMissed(ctx context.Context) {
+	now := sc.now().UTC()
+	for _, sch := range sc.schedules {
+		last, err := sc.svc.ingestRepo.LastScheduled(ctx, sch.Name)
+		if err != nil {
+			log.Printf("ingest schedule %s: failed to look up last run: %v", sch.Name, err)
+			continue
+		}
+		if slot := missedSlot(sch.cron, last, now); !slot.IsZero() {
+			log.Printf("ingest schedule %s: catching up on run due %s", sch.Name, slot.Format(time.RFC3339))
+			sc.fire(ctx, sch, slot)
+		}
+	}
+}
+
+func (sc *Scheduler) fire(ctx context.Context, sch Schedule, slot time.Time) {
+	runID, err := sc.svc.StartTriggered(ctx, sc.timeout, Trigger{
+		Schedule:     sch.Name,
+		ScheduledFor: slot,
+		Subjects:     sch.Subjects,
+	})
+	switch {
+	case err == nil:
+		log.Printf("ingest schedule %s: started run %s", sch.Name, runID)
+	case errors.Is(err, ErrSlotTaken):
+		// Another replica fired this slot.
+	case errors.Is(err, ErrRunInProgress):
+		log.Printf("ingest schedule %s: skipped, run %s in progress", sch.Name, runID)
+	default:
+		log.Printf("ingest schedule %s: failed to start run: %v", sch.Name, err)
+	}
+}
+
+// missedSlot returns the latest slot of c after last and no later than now,
+// or the zero time if none passed. A schedule that never ran has nothing to
+// catch up on.
+func missedSlot(c cron.Schedule, last, now time.Time) time.Time {
+	if last.IsZero() {
+		return time.Time{}
+	}
+	var missed time.Time
+	for t := c.Next(last.UTC()); !t.IsZero() && !t.After(now); t = c.Next(t) {
+		missed = t
+	}
+	return missed
+}
@@ -0,0 +1,79 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bookapi/internal/platform/cron"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedules(t *testing.T) {
+	schedules, err := ParseSchedules(" weekly=0 0 * * 0 ; history = @daily | history, biography ;")
+	require.NoError(t, err)
+	require.Len(t, schedules, 2)
+	assert.Equal(t, "weekly", schedules[0].Name)
+	assert.Equal(t, "0 0 * * 0", schedules[0].Expr)
+	assert.Empty(t, schedules[0].Subjects)
+	assert.Equal(t, "history", schedules[1].Name)
+	assert.Equal(t, []string{"history", "biography"}, schedules[1].Subjects)
+
+	for _, spec := range []string{"0 0 * * 0", "=@daily", "a=@daily;a=@weekly", "a=0 0 * *"} {
+		_, err := ParseSchedules(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestMissedSlot(t *testing.T) {
+	weekly, err := cron.Parse("0 0 * * 0")
+	require.NoError(t, err)
+	// A Wednesday.
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	assert.True(t, missedSlot(weekly, time.Time{}, now).IsZero(), "never ran")
+	assert.True(t, missedSlot(weekly, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), now).IsZero(), "ran last Sunday")
+	assert.Equal(t,
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		missedSlot(weekly, time.Date(2026, 2, 8, 0, 0, 0, 0, time.UTC), now),
+		"latest of three missed Sundays")
+}
+
+func TestScheduler_CatchUp(t *testing.T) {
+	ctx := context.Background()
+	schedules, err := ParseSchedules("weekly=0 0 * * 0|history")
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	slot := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("slot claimed by another replica", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		sc := NewScheduler(NewService(nil, nil, nil, mIngest, Config{}), schedules, CatchUpOnce, time.Minute)
+		sc.now = func() time.Time { return now }
+
+		mIngest.On("LastScheduled", ctx, "weekly").Return(time.Date(2026, 2, 22, 0, 0, 0, 0, time.UTC), nil)
+		mIngest.On("TryLock", ctx).Return(func() {}, true, nil)
+		mIngest.On("CreateRun", ctx, mock.MatchedBy(func(run *Run) bool {
+			return run.Schedule == "weekly" && run.ScheduledFor.Equal(slot) && run.ConfigSubjects == "history"
+		})).Return("", ErrSlotTaken)
+
+		sc.catchUpMissed(ctx)
+
+		mIngest.AssertExpectations(t)
+	})
+
+	t.Run("nothing missed", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		sc := NewScheduler(NewService(nil, nil, nil, mIngest, Config{}), schedules, CatchUpOnce, time.Minute)
+		sc.now = func() time.Time { return now }
+
+		mIngest.On("LastScheduled", ctx, "weekly").Return(slot, nil)
+
+		sc.catchUpMissed(ctx)
+
+		mIngest.AssertNotCalled(t, "TryLock", mock.Anything)
+	})
+}
@@ -3,15 +3,21 @@ package ingest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"bookapi/internal/book"
 	"bookapi/internal/catalog"
+	"bookapi/internal/platform/isbn"
 	"bookapi/internal/platform/openlibrary"
+	"bookapi/internal/platform/textsearch"
 )
 
 type Config struct {
@@ -20,23 +26,48 @@ type Config struct {
 	Subjects      []string
 	BatchSize     int
 	FreshnessDays int
+
+	// EditionsPerWork caps how many ISBNs are ingested from each search
+	// result. Editions of the same work are grouped under one works row.
+	EditionsPerWork int
+
+	// ProgressInterval is how often the counters of a run in progress are
+	// saved. Zero saves them only when the run finishes.
+	ProgressInterval time.Duration
+
+	// PagesPerSubject caps how many search result pages a run reads per
+	// subject. Each run continues from where the last one stopped.
+	PagesPerSubject int
+	// SubjectReset is what happens to a subject whose search results have
+	// all been read: one of ResetRestart (the default), ResetCooldown or
+	// ResetNever. With ResetCooldown it starts over SubjectResetAfter after
+	// it ran out.
+	SubjectReset      string
+	SubjectResetAfter time.Duration
 }
 
 type OpenLibraryClient interface {
-	SearchBooks(ctx context.Context, subject string, limit int) (*openlibrary.SearchResponse, error)
+	SearchBooks(ctx context.Context, subject string, limit, offset int) (*openlibrary.SearchResponse, error)
 	GetBooksByISBN(ctx context.Context, isbns []string) (map[string]openlibrary.BookDetails, error)
 	GetAuthor(ctx context.Context, authorKey string) (*openlibrary.AuthorDetails, error)
 }
 
 type Service struct {
-	olClient       OpenLibraryClient
-	catalogRepo    catalog.Repository
-	bookRepo       book.Repository
-	ingestRepo     Repository
-	cfg            Config
-	currentSubject string
+	olClient    OpenLibraryClient
+	catalogRepo catalog.Repository
+	bookRepo    book.Repository
+	ingestRepo  Repository
+	cfg         Config
+
+	mu sync.Mutex
+	// cancels holds the cancel function of each run executing in this
+	// process, by run ID.
+	cancels map[string]context.CancelCauseFunc
 }
 
+// errRunCancelled is the cause of a run context cancelled through Cancel.
+var errRunCancelled = errors.New("run cancelled")
+
 func NewService(olClient OpenLibraryClient, catalogRepo catalog.Repository, bookRepo book.Repository, ingestRepo Repository, cfg Config) *Service {
 	return &Service{
 		olClient:    olClient,
@@ -44,39 +75,173 @@ func NewService(olClient OpenLibraryClient, catalogRepo catalog.Repository, book
 		bookRepo:    bookRepo,
 		ingestRepo:  ingestRepo,
 		cfg:         cfg,
+		cancels:     make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// Start begins a run in the background and returns its ID. Only one run
+// executes at a time across all instances: while one holds the ingest lock,
+// Start returns the ID of the active run, if known, with ErrRunInProgress.
+// The run is stopped after timeout or when cancelled with Cancel.
+func (s *Service) Start(ctx context.Context, timeout time.Duration) (string, error) {
+	return s.StartTriggered(ctx, timeout, Trigger{})
+}
+
+// StartTriggered is Start for a run started by t, such as a schedule. A
+// scheduled run whose slot was already claimed returns ErrSlotTaken.
+func (s *Service) StartTriggered(ctx context.Context, timeout time.Duration, t Trigger) (string, error) {
+	unlock, acquired, err := s.ingestRepo.TryLock(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !acquired {
+		active, err := s.ingestRepo.ActiveRun(ctx)
+		if err != nil && !errors.Is(err, ErrRunNotFound) {
+			return "", err
+		}
+		return active.ID, ErrRunInProgress
+	}
+
+	subjects := s.subjects(t)
+	run, err := s.createRun(ctx, t, subjects)
+	if err != nil {
+		unlock()
+		return "", err
+	}
+
+	runCtx, cancelTimeout := context.WithTimeout(context.Background(), timeout)
+	runCtx, cancel := context.WithCancelCause(runCtx)
+	s.mu.Lock()
+	s.cancels[run.ID] = cancel
+	s.mu.Unlock()
+
+	go func() {
+		defer cancelTimeout()
+		defer unlock()
+		defer func() {
+			s.mu.Lock()
+			delete(s.cancels, run.ID)
+			s.mu.Unlock()
+			cancel(nil)
+		}()
+		if err := s.execute(runCtx, run, subjects); err != nil {
+			log.Printf("ingest run %s failed: %v", run.ID, err)
+		}
+	}()
+	return run.ID, nil
+}
+
+// Cancel stops a run executing in this process. The run finishes its
+// current request and is recorded as CANCELLED.
+func (s *Service) Cancel(ctx context.Context, id string) error {
+	s.mu.Lock()
+	cancel, ok := s.cancels[id]
+	s.mu.Unlock()
+	if ok {
+		cancel(errRunCancelled)
+		return nil
+	}
+
+	run, err := s.ingestRepo.GetRun(ctx, id)
+	if err != nil {
+		return err
+	}
+	if run.Status != StatusRunning {
+		return ErrRunNotRunning
+	}
+	return ErrRunNotLocal
+}
+
+// AbortStaleRuns marks runs left RUNNING by a process that died as ABORTED.
+// It does nothing while another instance holds the ingest lock, since its
+// run is genuinely in progress.
+func (s *Service) AbortStaleRuns(ctx context.Context) (int, error) {
+	unlock, acquired, err := s.ingestRepo.TryLock(ctx)
+	if err != nil || !acquired {
+		return 0, err
+	}
+	defer unlock()
+	return s.ingestRepo.AbortRunning(ctx)
+}
+
+// Run performs a run in the foreground, returning ErrRunInProgress if
+// another holds the ingest lock.
+func (s *Service) Run(ctx context.Context) error {
+	unlock, acquired, err := s.ingestRepo.TryLock(ctx)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return ErrRunInProgress
+	}
+	defer unlock()
+
+	run, err := s.createRun(ctx, Trigger{}, s.cfg.Subjects)
+	if err != nil {
+		return err
 	}
+	return s.execute(ctx, run, s.cfg.Subjects)
 }
 
-func (s *Service) Run(ctx context.Context) (err error) {
+// subjects returns the subjects a run started by t ingests.
+func (s *Service) subjects(t Trigger) []string {
+	if len(t.Subjects) > 0 {
+		return t.Subjects
+	}
+	return s.cfg.Subjects
+}
+
+func (s *Service) createRun(ctx context.Context, t Trigger, subjects []string) (*Run, error) {
 	run := &Run{
-		Status:           "RUNNING",
+		Status:           StatusRunning,
 		ConfigBooksMax:   s.cfg.BooksMax,
 		ConfigAuthorsMax: s.cfg.AuthorsMax,
-		ConfigSubjects:   strings.Join(s.cfg.Subjects, ","),
+		ConfigSubjects:   strings.Join(subjects, ","),
 		StartedAt:        time.Now(),
+		Schedule:         t.Schedule,
+	}
+	if t.Schedule != "" {
+		run.ScheduledFor = &t.ScheduledFor
 	}
-	runID, rErr := s.ingestRepo.CreateRun(ctx, run)
-	if rErr != nil {
-		return rErr
+	runID, err := s.ingestRepo.CreateRun(ctx, run)
+	if err != nil {
+		return nil, err
 	}
 	run.ID = runID
+	return run, nil
+}
 
+func (s *Service) execute(ctx context.Context, run *Run, subjects []string) (err error) {
 	defer func() {
 		now := time.Now()
 		run.FinishedAt = &now
-		if err != nil && run.Error == "" {
+		cancelled := errors.Is(context.Cause(ctx), errRunCancelled)
+		if cancelled {
+			err = nil
+			run.Error = "cancelled"
+		} else if err != nil && run.Error == "" {
 			run.Error = err.Error()
 		}
 
-		if run.Error != "" {
-			run.Status = "FAILED"
-		} else {
-			run.Status = "COMPLETED"
+		switch {
+		case cancelled:
+			run.Status = StatusCancelled
+		case run.Error != "":
+			run.Status = StatusFailed
+		default:
+			run.Status = StatusCompleted
 		}
-		if updateErr := s.ingestRepo.UpdateRun(ctx, run); updateErr != nil {
+
+		// A cancelled or timed out run must still record how it ended.
+		updateCtx := ctx
+		if ctx.Err() != nil {
+			updateCtx = context.WithoutCancel(ctx)
+		}
+		if updateErr := s.ingestRepo.UpdateRun(updateCtx, run); updateErr != nil {
 			log.Printf("Failed to update ingest run %s: %v", run.ID, updateErr)
 		}
 	}()
+	progress := progressSaver{svc: s, run: run, savedAt: time.Now()}
 
 	currentBooks, err := s.catalogRepo.GetTotalBooks(ctx)
 	if err != nil {
@@ -97,13 +262,26 @@ func (s *Service) Run(ctx context.Context) (err error) {
 
 	authorKeysToFetch := make(map[string]bool)
 	processedISBNs := make(map[string]bool)
+	// editions maps each discovered ISBN to the Open Library work it came from.
+	editions := make(map[string]edition)
 
-	for _, subject := range s.cfg.Subjects {
+	for _, subject := range subjects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if run.BooksUpserted >= neededBooks && run.AuthorsUpserted >= neededAuthors {
 			break
 		}
 
-		s.currentSubject = subject
+		cp, err := s.ingestRepo.GetCheckpoint(ctx, subject)
+		if err != nil {
+			run.Error = fmt.Sprintf("checkpoint lookup failed for %s: %v", subject, err)
+			return err
+		}
+		cp, ok := cp.resume(s.cfg.SubjectReset, s.cfg.SubjectResetAfter, time.Now())
+		if !ok {
+			continue
+		}
 
 		// Discovery
 		searchLimit := 100
@@ -111,56 +289,92 @@ func (s *Service) Run(ctx context.Context) (err error) {
 			searchLimit = neededBooks * 2
 		}
 
-		searchRes, err := s.olClient.SearchBooks(ctx, subject, searchLimit)
-		if err != nil {
-			run.Error = fmt.Sprintf("search failed for %s: %v", subject, err)
-			return err
-		}
+		// Later pages are only read while books are still needed.
+		for page := 0; page < max(s.cfg.PagesPerSubject, 1); page++ {
+			if page > 0 && (neededBooks <= 0 || run.BooksUpserted >= neededBooks) {
+				break
+			}
 
-		var isbnsToHydrate []string
-		for _, doc := range searchRes.Docs {
-			if len(doc.ISBN) == 0 {
-				continue
+			searchRes, err := s.olClient.SearchBooks(ctx, subject, searchLimit, cp.Offset)
+			if err != nil {
+				run.Error = fmt.Sprintf("search failed for %s: %v", subject, err)
+				return err
 			}
-			isbn := doc.ISBN[0]
-			// Open Library can return 10 or 13 digit ISBNs. We prefer 13.
-			for _, i := range doc.ISBN {
-				if len(i) == 13 {
-					isbn = i
-					break
+
+			// consumed counts the results handled, so a page left early is
+			// picked up where it stopped.
+			consumed := 0
+			var isbnsToHydrate []string
+		docs:
+			for i, doc := range searchRes.Docs {
+				consumed = i + 1
+				for _, isbn := range editionISBNs(doc.ISBN, s.cfg.EditionsPerWork) {
+					if processedISBNs[isbn] {
+						continue
+					}
+
+					// Freshness check
+					updatedAt, err := s.catalogRepo.GetBookUpdatedAt(ctx, isbn)
+					if err == nil && !updatedAt.IsZero() && time.Since(updatedAt) < time.Duration(s.cfg.FreshnessDays)*24*time.Hour {
+						continue
+					}
+
+					isbnsToHydrate = append(isbnsToHydrate, isbn)
+					processedISBNs[isbn] = true
+					editions[isbn] = edition{workKey: doc.Key, language: workLanguage(doc.Language)}
+					if len(isbnsToHydrate) >= s.cfg.BatchSize {
+						if err := s.hydrateBatch(ctx, run, isbnsToHydrate, editions, authorKeysToFetch); err != nil {
+							run.Error = fmt.Sprintf("hydrate failed for %s: %v", subject, err)
+							return err
+						}
+						progress.maybeSave(ctx)
+						isbnsToHydrate = nil
+						if err := ctx.Err(); err != nil {
+							return err
+						}
+						if neededBooks > 0 && run.BooksUpserted >= neededBooks {
+							break docs
+						}
+					}
 				}
 			}
-
-			if processedISBNs[isbn] {
-				continue
+			if len(isbnsToHydrate) > 0 {
+				if err := s.hydrateBatch(ctx, run, isbnsToHydrate, editions, authorKeysToFetch); err != nil {
+					run.Error = fmt.Sprintf("hydrate failed for %s: %v", subject, err)
+					return err
+				}
+				progress.maybeSave(ctx)
 			}
 
-			// Freshness check
-			updatedAt, err := s.catalogRepo.GetBookUpdatedAt(ctx, isbn)
-			if err == nil && !updatedAt.IsZero() && time.Since(updatedAt) < time.Duration(s.cfg.FreshnessDays)*24*time.Hour {
-				continue
-			}
+			// The checkpoint only moves past a page once all of it is
+			// hydrated. A run that fails on a page leaves it to be read again;
+			// the books it did upsert are then skipped as fresh.
 
-			isbnsToHydrate = append(isbnsToHydrate, isbn)
-			processedISBNs[isbn] = true
-			if len(isbnsToHydrate) >= s.cfg.BatchSize {
-				s.hydrateBatch(ctx, run, isbnsToHydrate, authorKeysToFetch)
-				isbnsToHydrate = nil
-				if neededBooks > 0 && run.BooksUpserted >= neededBooks {
-					break
-				}
+			cp.advance(consumed, len(searchRes.Docs), searchRes.NumFound, time.Now())
+			if err := s.ingestRepo.SaveCheckpoint(ctx, cp); err != nil {
+				log.Printf("Failed to save checkpoint for subject %s: %v", subject, err)
+			}
+			if cp.ExhaustedAt != nil {
+				break
 			}
 		}
-		if len(isbnsToHydrate) > 0 {
-			s.hydrateBatch(ctx, run, isbnsToHydrate, authorKeysToFetch)
+	}
+
+	if run.BooksUpserted > 0 {
+		if err := s.bookRepo.RefreshLexicon(ctx); err != nil {
+			log.Printf("Failed to refresh search lexicon: %v", err)
 		}
 	}
 
 	// Hydrate Authors
 	for authorKey := range authorKeysToFetch {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if neededAuthors > 0 && run.AuthorsUpserted >= neededAuthors {
 			break
 		}
+		progress.maybeSave(ctx)
 
 		// Freshness check
 		updatedAt, err := s.catalogRepo.GetAuthorUpdatedAt(ctx, authorKey)
@@ -194,14 +408,55 @@ func (s *Service) Run(ctx context.Context) (err error) {
 	return nil
 }
 
-func (s *Service) hydrateBatch(ctx context.Context, run *Run, isbns []string, authorKeys map[string]bool) {
+// progressSaver saves the counters of a run in progress at most once per
+// ProgressInterval.
+type progressSaver struct {
+	svc     *Service
+	run     *Run
+	savedAt time.Time
+}
+
+func (p *progressSaver) maybeSave(ctx context.Context) {
+	interval := p.svc.cfg.ProgressInterval
+	if interval <= 0 || time.Since(p.savedAt) < interval {
+		return
+	}
+	p.savedAt = time.Now()
+	if err := p.svc.ingestRepo.SaveProgress(ctx, p.run); err != nil {
+		log.Printf("Failed to save progress of ingest run %s: %v", p.run.ID, err)
+	}
+}
+
+// edition is what a search result tells about one of a work's ISBNs.
+type edition struct {
+	workKey  string
+	language string
+}
+
+// workLanguage returns the ISO 639-1 code of a work published in a single
+// language. A work in several languages says nothing about which edition
+// is in which, so its editions are left without one.
+func workLanguage(codes []string) string {
+	if len(codes) != 1 {
+		return ""
+	}
+	return textsearch.LanguageCode(codes[0])
+}
+
+// hydrateBatch fetches a batch of ISBNs from Open Library and upserts them.
+// It returns an error if the batch could not be fetched or any of its books
+// could not be upserted.
+func (s *Service) hydrateBatch(ctx context.Context, run *Run, isbns []string, editions map[string]edition, authorKeys map[string]bool) error {
 	batch, err := s.olClient.GetBooksByISBN(ctx, isbns)
 	if err != nil {
-		log.Printf("Failed to hydrate batch: %v", err)
-		return
+		return fmt.Errorf("fetch books: %w", err)
 	}
 	run.BooksFetched += len(batch)
 
+	// A book that fails is logged and the rest of the batch still upserted.
+	failed := 0
+	var firstErr error
+
 	for bibkey, details := range batch {
 		isbn := strings.TrimPrefix(bibkey, "ISBN:")
 
@@ -213,81 +468,218 @@ func (s *Service) hydrateBatch(ctx context.Context, run *Run, isbns []string, au
 			CoverURL:      details.Cover.Large,
 			PublishedDate: details.PublishDate,
 			Publisher:     formatPublishers(details.Publishers),
-			Language:      "",
+			Language:      editions[isbn].language,
 			PageCount:     details.NumberOfPages,
 		}
 
 		rawJSON, _ := json.Marshal(details)
 		if err := s.catalogRepo.UpsertBook(ctx, catalogBook, rawJSON); err != nil {
 			log.Printf("Failed to upsert book %s to catalog: %v", isbn, err)
+			if failed++; firstErr == nil {
+				firstErr = err
+			}
 			continue
 		}
 
 		// Materialize into books table
-		publisher := catalogBook.Publisher
-		if publisher == "" {
-			publisher = "Unknown"
+		appBook := materialize(catalogBook, editions[isbn].workKey)
+		for _, subject := range details.Subjects {
+			if name := strings.TrimSpace(subject.Name); name != "" {
+				appBook.Subjects = append(appBook.Subjects, name)
+			}
 		}
-		genre := s.currentSubject
-		if genre == "" {
-			genre = "Unknown"
+		for _, author := range details.Authors {
+			key := authorKey(author.URL)
+			if key == "" || author.Name == "" {
+				continue
+			}
+			appBook.Authors = append(appBook.Authors, book.AuthorRef{
+				Key:  key,
+				Name: author.Name,
+				Role: creditRole(author.Name, details.ByStatement),
+			})
+		}
+		for _, raw := range details.Series {
+			if ref, ok := parseSeries(raw); ok {
+				appBook.SeriesMemberships = append(appBook.SeriesMemberships, ref)
+			}
 		}
 
-		var publicationYear *int
-		if catalogBook.PublishedDate != "" {
-			yearStr := extractYear(catalogBook.PublishedDate)
-			if yearStr != "" {
-				if year, err := strconv.Atoi(yearStr); err == nil {
-					publicationYear = &year
-				}
+		if err := upsertBook(ctx, s.bookRepo, appBook); err != nil {
+			log.Printf("Failed to materialize book %s to books table: %v", isbn, err)
+			if failed++; firstErr == nil {
+				firstErr = err
 			}
+			continue
 		}
 
-		var pageCount *int
-		if catalogBook.PageCount > 0 {
-			pageCount = &catalogBook.PageCount
+		run.BooksUpserted++
+		_ = s.ingestRepo.LinkBookToRun(ctx, run.ID, isbn)
+
+		for _, a := range appBook.Authors {
+			authorKeys[a.Key] = true
 		}
+	}
+	if firstErr != nil {
+		return fmt.Errorf("upsert %d of %d books: %w", failed, len(batch), firstErr)
+	}
+	return nil
+}
 
-		var coverURL *string
-		if catalogBook.CoverURL != "" {
-			coverURL = &catalogBook.CoverURL
+// upsertAttempts bounds how often a book is written when its upsert keeps
+// deadlocking with another one.
+const upsertAttempts = 3
+
+// upsertBook writes b to the books table, retrying a deadlock: Postgres has
+// rolled the upsert back, so running it again is safe.
+func upsertBook(ctx context.Context, repo book.Repository, b *book.Book) error {
+	for attempt := 1; ; attempt++ {
+		err := repo.UpsertFromIngest(ctx, b)
+		if !errors.Is(err, book.ErrDeadlock) || attempt == upsertAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * 50 * time.Millisecond):
 		}
+	}
+}
 
-		appBook := &book.Book{
-			ISBN:            isbn,
-			Title:           catalogBook.Title,
-			Subtitle:        catalogBook.Subtitle,
-			Genre:           genre,
-			Publisher:       publisher,
-			Description:     catalogBook.Description,
-			PublishedDate:   catalogBook.PublishedDate,
-			PublicationYear: publicationYear,
-			PageCount:       pageCount,
-			Language:        catalogBook.Language,
-			CoverURL:        coverURL,
+// materialize maps a catalog book onto the books table row it is
+// published as. Subjects, authors and series are left to the caller.
+func materialize(cb *catalog.Book, workKey string) *book.Book {
+	publisher := cb.Publisher
+	if publisher == "" {
+		publisher = "Unknown"
+	}
+
+	var publicationYear *int
+	if cb.PublishedDate != "" {
+		yearStr := extractYear(cb.PublishedDate)
+		if yearStr != "" {
+			if year, err := strconv.Atoi(yearStr); err == nil {
+				publicationYear = &year
+			}
 		}
+	}
 
-		if err := s.bookRepo.UpsertFromIngest(ctx, appBook); err != nil {
-			log.Printf("Failed to materialize book %s to books table: %v", isbn, err)
+	var pageCount *int
+	if cb.PageCount > 0 {
+		pageCount = &cb.PageCount
+	}
+
+	var coverURL *string
+	if cb.CoverURL != "" {
+		coverURL = &cb.CoverURL
+	}
+
+	return &book.Book{
+		ISBN:     cb.ISBN13,
+		Title:    cb.Title,
+		Subtitle: cb.Subtitle,
+		// Replaced by the genre of the first mapped subject, if any.
+		Genre:           "Unknown",
+		Publisher:       publisher,
+		Description:     cb.Description,
+		PublishedDate:   cb.PublishedDate,
+		PublicationYear: publicationYear,
+		PageCount:       pageCount,
+		Language:        cb.Language,
+		CoverURL:        coverURL,
+		WorkKey:         workKey,
+	}
+}
+
+// editionISBNs returns the canonical ISBN-13s of up to max distinct editions.
+// Open Library lists both 10 and 13 digit forms of an edition, and some
+// entries carry bad check digits, so invalid ones are skipped.
+func editionISBNs(candidates []string, max int) []string {
+	if max <= 0 {
+		max = 1
+	}
+	var out []string
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		key, err := isbn.Canonical(c)
+		if err != nil || seen[key] {
 			continue
 		}
+		seen[key] = true
+		out = append(out, key)
+		if len(out) == max {
+			break
+		}
+	}
+	return out
+}
+
+// authorKey extracts the author key from an Open Library author URL, which
+// can be like "/authors/OL123A" or "https://openlibrary.org/authors/OL123A/Name".
+func authorKey(url string) string {
+	parts := strings.Split(url, "/")
+	for i, p := range parts {
+		if p == "authors" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
 
-		run.BooksUpserted++
-		_ = s.ingestRepo.LinkBookToRun(ctx, run.ID, isbn)
+// creditRole works out from the by-statement whether name translated or
+// edited the book rather than wrote it. Within the ";"-separated clause that
+// names the person, the closest role word before the name wins, so
+// "Dostoevsky, translated by Pevear" credits only Pevear as translator.
+func creditRole(name, byStatement string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return book.RoleAuthor
+	}
+	for _, clause := range strings.Split(strings.ToLower(byStatement), ";") {
+		at := strings.Index(clause, name)
+		if at < 0 {
+			continue
+		}
+		before := clause[:at]
+		translated := strings.LastIndex(before, "translat")
+		edited := max(strings.LastIndex(before, "edited"), strings.LastIndex(before, "editor"))
+		switch {
+		case translated > edited:
+			return book.RoleTranslator
+		case edited > translated:
+			return book.RoleEditor
+		}
+		return book.RoleAuthor
+	}
+	return book.RoleAuthor
+}
 
-		for _, author := range details.Authors {
-			// author.URL can be like "/authors/OL123A" or "https://openlibrary.org/authors/OL123A/Name"
-			if author.URL != "" {
-				parts := strings.Split(author.URL, "/")
-				for i, p := range parts {
-					if p == "authors" && i+1 < len(parts) {
-						authorKeys[parts[i+1]] = true
-						break
-					}
-				}
+// seriesPosition matches a trailing position in Open Library series strings:
+// "Dune Chronicles ; 1", "Discworld (4)", "The Expanse, book 2.5", "Wheel of Time #3".
+var seriesPosition = regexp.MustCompile(`(?i)^(.*?)(?:\s*[,;:(]\s*|\s+)(?:(?:#|no\.?|vol\.?|volume|book|bk\.?)\s*)?(\d+(?:\.\d+)?)\)?\s*$`)
+
+// maxSeriesPosition is the largest position book_series.position, a
+// NUMERIC(6,2), can hold.
+const maxSeriesPosition = 9999.99
+
+// parseSeries splits a series entry into its name and optional position.
+// Numbers too large to be a position, such as "Foo ; 12345", are usually
+// catalogue numbers; the series is kept without a position.
+func parseSeries(raw string) (book.SeriesRef, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return book.SeriesRef{}, false
+	}
+	if m := seriesPosition.FindStringSubmatch(raw); m != nil {
+		name := strings.TrimSpace(m[1])
+		if pos, err := strconv.ParseFloat(m[2], 64); err == nil && name != "" {
+			if pos = math.Round(pos*100) / 100; pos > maxSeriesPosition {
+				return book.SeriesRef{Name: name}, true
 			}
+			return book.SeriesRef{Name: name, Position: &pos}, true
 		}
 	}
+	return book.SeriesRef{Name: raw}, true
 }
 
 func formatPublishers(p []openlibrary.Publisher) string {
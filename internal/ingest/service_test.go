@@ -3,6 +3,7 @@ package ingest
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,8 +19,8 @@ type mockOLClient struct {
 	mock.Mock
 }
 
-func (m *mockOLClient) SearchBooks(ctx context.Context, subject string, limit int) (*openlibrary.SearchResponse, error) {
-	args := m.Called(ctx, subject, limit)
+func (m *mockOLClient) SearchBooks(ctx context.Context, subject string, limit, offset int) (*openlibrary.SearchResponse, error) {
+	args := m.Called(ctx, subject, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -56,6 +57,37 @@ func (m *mockCatalogRepo) UpsertAuthor(ctx context.Context, author *catalog.Auth
 	return args.Error(0)
 }
 
+func (m *mockCatalogRepo) UpsertBooks(ctx context.Context, books []catalog.BookRecord) error {
+	args := m.Called(ctx, books)
+	return args.Error(0)
+}
+
+func (m *mockCatalogRepo) UpsertAuthors(ctx context.Context, authors []catalog.AuthorRecord) error {
+	args := m.Called(ctx, authors)
+	return args.Error(0)
+}
+
+func (m *mockCatalogRepo) UpsertWorks(ctx context.Context, works []catalog.WorkRecord) error {
+	args := m.Called(ctx, works)
+	return args.Error(0)
+}
+
+func (m *mockCatalogRepo) Works(ctx context.Context, keys []string) (map[string]catalog.Work, error) {
+	args := m.Called(ctx, keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]catalog.Work), args.Error(1)
+}
+
+func (m *mockCatalogRepo) AuthorNames(ctx context.Context, keys []string) (map[string]string, error) {
+	args := m.Called(ctx, keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 func (m *mockCatalogRepo) GetTotalBooks(ctx context.Context) (int, error) {
 	args := m.Called(ctx)
 	return args.Int(0), args.Error(1)
@@ -118,6 +150,73 @@ func (m *mockIngestRepo) LinkAuthorToRun(ctx context.Context, runID string, auth
 	return args.Error(0)
 }
 
+func (m *mockIngestRepo) ListRuns(ctx context.Context, status string, limit, offset int) ([]Run, int, error) {
+	args := m.Called(ctx, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]Run), args.Int(1), args.Error(2)
+}
+
+func (m *mockIngestRepo) GetRun(ctx context.Context, id string) (Run, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(Run), args.Error(1)
+}
+
+func (m *mockIngestRepo) RunBooks(ctx context.Context, runID string) ([]string, error) {
+	args := m.Called(ctx, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockIngestRepo) RunAuthors(ctx context.Context, runID string) ([]string, error) {
+	args := m.Called(ctx, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockIngestRepo) TryLock(ctx context.Context) (func(), bool, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(func()), args.Bool(1), args.Error(2)
+}
+
+func (m *mockIngestRepo) ActiveRun(ctx context.Context) (Run, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(Run), args.Error(1)
+}
+
+func (m *mockIngestRepo) SaveProgress(ctx context.Context, run *Run) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *mockIngestRepo) AbortRunning(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockIngestRepo) LastScheduled(ctx context.Context, schedule string) (time.Time, error) {
+	args := m.Called(ctx, schedule)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *mockIngestRepo) GetCheckpoint(ctx context.Context, subject string) (Checkpoint, error) {
+	args := m.Called(ctx, subject)
+	return args.Get(0).(Checkpoint), args.Error(1)
+}
+
+func (m *mockIngestRepo) SaveCheckpoint(ctx context.Context, cp Checkpoint) error {
+	args := m.Called(ctx, cp)
+	return args.Error(0)
+}
+
 type mockBookRepo struct {
 	mock.Mock
 }
@@ -131,6 +230,45 @@ func (m *mockBookRepo) List(ctx context.Context, q book.Query) ([]book.Book, int
 	return books, args.Int(1), args.Error(2)
 }
 
+func (m *mockBookRepo) Facets(ctx context.Context, q book.Query, facets []string) (map[string][]book.FacetBucket, error) {
+	args := m.Called(ctx, q, facets)
+	var out map[string][]book.FacetBucket
+	if args.Get(0) != nil {
+		out = args.Get(0).(map[string][]book.FacetBucket)
+	}
+	return out, args.Error(1)
+}
+
+func (m *mockBookRepo) Suggest(ctx context.Context, prefix string, limit int) ([]book.Suggestion, error) {
+	args := m.Called(ctx, prefix, limit)
+	return args.Get(0).([]book.Suggestion), args.Error(1)
+}
+
+func (m *mockBookRepo) Corrections(ctx context.Context, search string, limit int) ([]string, error) {
+	args := m.Called(ctx, search, limit)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockBookRepo) RefreshLexicon(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *mockBookRepo) TopRated(ctx context.Context, q book.RankQuery, cfg book.RankingConfig) ([]book.RankedBook, error) {
+	args := m.Called(ctx, q, cfg)
+	return args.Get(0).([]book.RankedBook), args.Error(1)
+}
+
+func (m *mockBookRepo) Trending(ctx context.Context, q book.RankQuery, cfg book.RankingConfig) ([]book.RankedBook, error) {
+	args := m.Called(ctx, q, cfg)
+	return args.Get(0).([]book.RankedBook), args.Error(1)
+}
+
+func (m *mockBookRepo) SimilarCandidates(ctx context.Context, isbn string) ([]book.SimilarCandidate, error) {
+	args := m.Called(ctx, isbn)
+	return args.Get(0).([]book.SimilarCandidate), args.Error(1)
+}
+
 func (m *mockBookRepo) GetByISBN(ctx context.Context, isbn string) (book.Book, error) {
 	args := m.Called(ctx, isbn)
 	var b book.Book
@@ -145,6 +283,21 @@ func (m *mockBookRepo) UpsertFromIngest(ctx context.Context, b *book.Book) error
 	return args.Error(0)
 }
 
+func (m *mockBookRepo) Create(ctx context.Context, b *book.Book) error {
+	args := m.Called(ctx, b)
+	return args.Error(0)
+}
+
+func (m *mockBookRepo) Update(ctx context.Context, isbn string, b *book.Book) error {
+	args := m.Called(ctx, isbn, b)
+	return args.Error(0)
+}
+
+func (m *mockBookRepo) Delete(ctx context.Context, isbn string) error {
+	args := m.Called(ctx, isbn)
+	return args.Error(0)
+}
+
 func TestService_Run(t *testing.T) {
 	ctx := context.Background()
 	cfg := Config{
@@ -163,6 +316,7 @@ func TestService_Run(t *testing.T) {
 
 		s := NewService(mOL, mCatalog, mBook, mIngest, cfg)
 
+		mIngest.On("TryLock", ctx).Return(func() {}, true, nil)
 		mIngest.On("CreateRun", ctx, mock.Anything).Return("run-0", nil)
 		mIngest.On("UpdateRun", ctx, mock.MatchedBy(func(run *Run) bool {
 			return run.Status == "COMPLETED"
@@ -173,7 +327,7 @@ func TestService_Run(t *testing.T) {
 
 		err := s.Run(ctx)
 		assert.NoError(t, err)
-		mOL.AssertNotCalled(t, "SearchBooks", mock.Anything, mock.Anything, mock.Anything)
+		mOL.AssertNotCalled(t, "SearchBooks", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	})
 
 	t.Run("fetches missing books and authors", func(t *testing.T) {
@@ -184,6 +338,7 @@ func TestService_Run(t *testing.T) {
 
 		s := NewService(mOL, mCatalog, mBook, mIngest, cfg)
 
+		mIngest.On("TryLock", ctx).Return(func() {}, true, nil)
 		mIngest.On("CreateRun", ctx, mock.Anything).Return("run-1", nil)
 		mIngest.On("UpdateRun", ctx, mock.MatchedBy(func(run *Run) bool {
 			return run.Status == "COMPLETED"
@@ -202,34 +357,52 @@ func TestService_Run(t *testing.T) {
 				FirstPublishYear int      `json:"first_publish_year"`
 				Language         []string `json:"language"`
 			}{
-				{ISBN: []string{"isbn1"}, AuthorKeys: []string{"auth1"}},
-				{ISBN: []string{"isbn2"}, AuthorKeys: []string{"auth2"}},
+				{ISBN: []string{"9780306406157"}, AuthorKeys: []string{"auth1"}},
+				{ISBN: []string{"9780441172719"}, AuthorKeys: []string{"auth2"}},
 			},
 		}
-		mOL.On("SearchBooks", ctx, "test", 4).Return(searchRes, nil)
+		mIngest.On("GetCheckpoint", ctx, "test").Return(Checkpoint{Subject: "test"}, nil)
+		mIngest.On("SaveCheckpoint", ctx, mock.Anything).Return(nil)
+		mOL.On("SearchBooks", ctx, "test", 4, 0).Return(searchRes, nil)
 
-		mCatalog.On("GetBookUpdatedAt", ctx, "isbn1").Return(time.Time{}, nil)
-		mCatalog.On("GetBookUpdatedAt", ctx, "isbn2").Return(time.Time{}, nil)
+		mCatalog.On("GetBookUpdatedAt", ctx, "9780306406157").Return(time.Time{}, nil)
+		mCatalog.On("GetBookUpdatedAt", ctx, "9780441172719").Return(time.Time{}, nil)
 
-		mOL.On("GetBooksByISBN", ctx, []string{"isbn1", "isbn2"}).Return(map[string]openlibrary.BookDetails{
-			"ISBN:isbn1": {Title: "Book 1", Authors: []struct {
+		mOL.On("GetBooksByISBN", ctx, []string{"9780306406157", "9780441172719"}).Return(map[string]openlibrary.BookDetails{
+			"ISBN:9780306406157": {Title: "Book 1", Authors: []struct {
 				URL  string `json:"url"`
 				Name string `json:"name"`
-			}{{URL: "/authors/auth1", Name: "Author 1"}}},
-			"ISBN:isbn2": {Title: "Book 2", Authors: []struct {
+			}{{URL: "/authors/auth1", Name: "Author 1"}}, Subjects: []struct {
+				Name string `json:"name"`
+				URL  string `json:"url"`
+			}{{Name: "Science fiction"}, {Name: " "}, {Name: "Dune (Imaginary place)"}}},
+			"ISBN:9780441172719": {Title: "Book 2", Authors: []struct {
 				URL  string `json:"url"`
 				Name string `json:"name"`
 			}{{URL: "/authors/auth2", Name: "Author 2"}}},
 		}, nil)
 
 		mCatalog.On("UpsertBook", ctx, mock.Anything, mock.Anything).Return(nil).Twice()
-		mBook.On("UpsertFromIngest", ctx, mock.Anything).Return(nil).Twice()
+		mBook.On("UpsertFromIngest", ctx, mock.MatchedBy(func(b *book.Book) bool {
+			if b.Title == "Book 1" && !assert.ObjectsAreEqual([]string{"Science fiction", "Dune (Imaginary place)"}, b.Subjects) {
+				return false
+			}
+			// The genre comes from the book's own subjects, never the
+			// subject it was discovered under.
+			return b.Genre == "Unknown" &&
+				len(b.Authors) == 1 && b.Authors[0].Role == book.RoleAuthor &&
+				b.Authors[0].Key == map[string]string{"Book 1": "auth1", "Book 2": "auth2"}[b.Title]
+		})).Return(nil).Twice()
+		mBook.On("RefreshLexicon", ctx).Return(nil).Once()
 		mIngest.On("LinkBookToRun", ctx, "run-1", mock.Anything).Return(nil).Twice()
 
-		mCatalog.On("GetAuthorUpdatedAt", ctx, "auth1").Return(time.Time{}, nil)
-		mOL.On("GetAuthor", ctx, "auth1").Return(&openlibrary.AuthorDetails{Name: "Author 1"}, nil)
-		mCatalog.On("UpsertAuthor", ctx, mock.Anything, mock.Anything).Return(nil)
-		mIngest.On("LinkAuthorToRun", ctx, "run-1", "auth1").Return(nil)
+		// Only one author is needed; which of the two discovered keys is
+		// fetched first depends on map iteration order.
+		author := mock.MatchedBy(func(key string) bool { return key == "auth1" || key == "auth2" })
+		mCatalog.On("GetAuthorUpdatedAt", ctx, author).Return(time.Time{}, nil).Once()
+		mOL.On("GetAuthor", ctx, author).Return(&openlibrary.AuthorDetails{Name: "Author 1"}, nil).Once()
+		mCatalog.On("UpsertAuthor", ctx, mock.Anything, mock.Anything).Return(nil).Once()
+		mIngest.On("LinkAuthorToRun", ctx, "run-1", author).Return(nil).Once()
 
 		err := s.Run(ctx)
 		assert.NoError(t, err)
@@ -239,6 +412,60 @@ func TestService_Run(t *testing.T) {
 		mIngest.AssertExpectations(t)
 	})
 
+	t.Run("groups editions under their work", func(t *testing.T) {
+		mOL := new(mockOLClient)
+		mCatalog := new(mockCatalogRepo)
+		mBook := new(mockBookRepo)
+		mIngest := new(mockIngestRepo)
+
+		editionsCfg := cfg
+		editionsCfg.EditionsPerWork = 2
+		s := NewService(mOL, mCatalog, mBook, mIngest, editionsCfg)
+
+		mIngest.On("TryLock", ctx).Return(func() {}, true, nil)
+		mIngest.On("CreateRun", ctx, mock.Anything).Return("run-6", nil)
+		mIngest.On("UpdateRun", ctx, mock.Anything).Return(nil)
+
+		mCatalog.On("GetTotalBooks", ctx).Return(8, nil)
+		mCatalog.On("GetTotalAuthors", ctx).Return(5, nil)
+
+		searchRes := &openlibrary.SearchResponse{
+			Docs: []struct {
+				Key              string   `json:"key"`
+				Title            string   `json:"title"`
+				AuthorNames      []string `json:"author_name"`
+				AuthorKeys       []string `json:"author_key"`
+				ISBN             []string `json:"isbn"`
+				FirstPublishYear int      `json:"first_publish_year"`
+				Language         []string `json:"language"`
+			}{
+				// The ISBN-10 is the same edition as the first ISBN-13.
+				{Key: "/works/OL1W", ISBN: []string{"9780306406157", "0306406152", "9780441172719", "9780804429573"}},
+			},
+		}
+		mIngest.On("GetCheckpoint", ctx, "test").Return(Checkpoint{Subject: "test"}, nil)
+		mIngest.On("SaveCheckpoint", ctx, mock.Anything).Return(nil)
+		mOL.On("SearchBooks", ctx, "test", 4, 0).Return(searchRes, nil)
+
+		mCatalog.On("GetBookUpdatedAt", ctx, mock.Anything).Return(time.Time{}, nil)
+		mOL.On("GetBooksByISBN", ctx, []string{"9780306406157", "9780441172719"}).Return(map[string]openlibrary.BookDetails{
+			"ISBN:9780306406157": {Title: "Hardcover"},
+			"ISBN:9780441172719": {Title: "Paperback"},
+		}, nil)
+		mCatalog.On("UpsertBook", ctx, mock.Anything, mock.Anything).Return(nil)
+		mBook.On("UpsertFromIngest", ctx, mock.MatchedBy(func(b *book.Book) bool {
+			return b.WorkKey == "/works/OL1W"
+		})).Return(nil).Twice()
+		mBook.On("RefreshLexicon", ctx).Return(nil)
+		mIngest.On("LinkBookToRun", ctx, "run-6", mock.Anything).Return(nil)
+
+		err := s.Run(ctx)
+		assert.NoError(t, err)
+
+		mOL.AssertExpectations(t)
+		mBook.AssertExpectations(t)
+	})
+
 	t.Run("skips recently updated books", func(t *testing.T) {
 		mOL := new(mockOLClient)
 		mCatalog := new(mockCatalogRepo)
@@ -247,6 +474,7 @@ func TestService_Run(t *testing.T) {
 
 		s := NewService(mOL, mCatalog, mBook, mIngest, cfg)
 
+		mIngest.On("TryLock", ctx).Return(func() {}, true, nil)
 		mIngest.On("CreateRun", ctx, mock.Anything).Return("run-2", nil)
 		mIngest.On("UpdateRun", ctx, mock.MatchedBy(func(run *Run) bool {
 			return run.Status == "COMPLETED"
@@ -265,12 +493,14 @@ func TestService_Run(t *testing.T) {
 				FirstPublishYear int      `json:"first_publish_year"`
 				Language         []string `json:"language"`
 			}{
-				{ISBN: []string{"isbn_recent"}},
+				{ISBN: []string{"9780140449136"}},
 			},
 		}
-		mOL.On("SearchBooks", ctx, "test", 2).Return(searchRes, nil)
+		mIngest.On("GetCheckpoint", ctx, "test").Return(Checkpoint{Subject: "test"}, nil)
+		mIngest.On("SaveCheckpoint", ctx, mock.Anything).Return(nil)
+		mOL.On("SearchBooks", ctx, "test", 2, 0).Return(searchRes, nil)
 
-		mCatalog.On("GetBookUpdatedAt", ctx, "isbn_recent").Return(time.Now(), nil) // Recently updated
+		mCatalog.On("GetBookUpdatedAt", ctx, "9780140449136").Return(time.Now(), nil) // Recently updated
 
 		err := s.Run(ctx)
 		assert.NoError(t, err)
@@ -286,6 +516,7 @@ func TestService_Run(t *testing.T) {
 
 		s := NewService(mOL, mCatalog, mBook, mIngest, cfg)
 
+		mIngest.On("TryLock", ctx).Return(func() {}, true, nil)
 		mIngest.On("CreateRun", ctx, mock.Anything).Return("run-3", nil)
 		mIngest.On("UpdateRun", ctx, mock.MatchedBy(func(run *Run) bool {
 			return run.Status == "COMPLETED"
@@ -304,21 +535,24 @@ func TestService_Run(t *testing.T) {
 				FirstPublishYear int      `json:"first_publish_year"`
 				Language         []string `json:"language"`
 			}{
-				{ISBN: []string{"isbn_dup"}},
-				{ISBN: []string{"isbn_dup"}}, // Duplicate ISBN in search results
+				{ISBN: []string{"9780804429573"}},
+				{ISBN: []string{"080442957X"}}, // Same edition in ISBN-10 form
 			},
 		}
-		mOL.On("SearchBooks", ctx, "test", 4).Return(searchRes, nil)
+		mIngest.On("GetCheckpoint", ctx, "test").Return(Checkpoint{Subject: "test"}, nil)
+		mIngest.On("SaveCheckpoint", ctx, mock.Anything).Return(nil)
+		mOL.On("SearchBooks", ctx, "test", 4, 0).Return(searchRes, nil)
 
-		mCatalog.On("GetBookUpdatedAt", ctx, "isbn_dup").Return(time.Time{}, nil)
+		mCatalog.On("GetBookUpdatedAt", ctx, "9780804429573").Return(time.Time{}, nil)
 
-		mOL.On("GetBooksByISBN", ctx, []string{"isbn_dup"}).Return(map[string]openlibrary.BookDetails{
-			"ISBN:isbn_dup": {Title: "Dup Book"},
+		mOL.On("GetBooksByISBN", ctx, []string{"9780804429573"}).Return(map[string]openlibrary.BookDetails{
+			"ISBN:9780804429573": {Title: "Dup Book"},
 		}, nil)
 
 		mCatalog.On("UpsertBook", ctx, mock.Anything, mock.Anything).Return(nil)
 		mBook.On("UpsertFromIngest", ctx, mock.Anything).Return(nil)
-		mIngest.On("LinkBookToRun", ctx, "run-3", "isbn_dup").Return(nil)
+		mBook.On("RefreshLexicon", ctx).Return(nil).Once()
+		mIngest.On("LinkBookToRun", ctx, "run-3", "9780804429573").Return(nil)
 
 		err := s.Run(ctx)
 		assert.NoError(t, err)
@@ -326,6 +560,50 @@ func TestService_Run(t *testing.T) {
 		mOL.AssertNumberOfCalls(t, "GetBooksByISBN", 1)
 	})
 
+	t.Run("resumes from the checkpoint and pages deeper", func(t *testing.T) {
+		mOL := new(mockOLClient)
+		mCatalog := new(mockCatalogRepo)
+		mBook := new(mockBookRepo)
+		mIngest := new(mockIngestRepo)
+
+		pagingCfg := cfg
+		pagingCfg.PagesPerSubject = 3
+		s := NewService(mOL, mCatalog, mBook, mIngest, pagingCfg)
+
+		mIngest.On("TryLock", ctx).Return(func() {}, true, nil)
+		mIngest.On("CreateRun", ctx, mock.Anything).Return("run-7", nil)
+		mIngest.On("UpdateRun", ctx, mock.Anything).Return(nil)
+
+		mCatalog.On("GetTotalBooks", ctx).Return(8, nil)
+		mCatalog.On("GetTotalAuthors", ctx).Return(5, nil)
+
+		mIngest.On("GetCheckpoint", ctx, "test").Return(Checkpoint{Subject: "test", Offset: 10, Page: 3}, nil)
+		page := &openlibrary.SearchResponse{NumFound: 11}
+		page.Docs = append(page.Docs, struct {
+			Key              string   `json:"key"`
+			Title            string   `json:"title"`
+			AuthorNames      []string `json:"author_name"`
+			AuthorKeys       []string `json:"author_key"`
+			ISBN             []string `json:"isbn"`
+			FirstPublishYear int      `json:"first_publish_year"`
+			Language         []string `json:"language"`
+		}{ISBN: []string{"9780140449136"}})
+		mOL.On("SearchBooks", ctx, "test", 4, 10).Return(page, nil)
+		mCatalog.On("GetBookUpdatedAt", ctx, "9780140449136").Return(time.Now(), nil)
+
+		// The last result was on that page, so the subject is exhausted and
+		// no further page is requested.
+		mIngest.On("SaveCheckpoint", ctx, mock.MatchedBy(func(cp Checkpoint) bool {
+			return cp.Offset == 11 && cp.Page == 4 && cp.NumFound == 11 && cp.ExhaustedAt != nil
+		})).Return(nil).Once()
+
+		err := s.Run(ctx)
+		assert.NoError(t, err)
+
+		mOL.AssertNumberOfCalls(t, "SearchBooks", 1)
+		mIngest.AssertExpectations(t)
+	})
+
 	t.Run("records failure if SearchBooks fails", func(t *testing.T) {
 		mOL := new(mockOLClient)
 		mCatalog := new(mockCatalogRepo)
@@ -334,6 +612,7 @@ func TestService_Run(t *testing.T) {
 
 		s := NewService(mOL, mCatalog, mBook, mIngest, cfg)
 
+		mIngest.On("TryLock", ctx).Return(func() {}, true, nil)
 		mIngest.On("CreateRun", ctx, mock.Anything).Return("run-4", nil)
 		mIngest.On("UpdateRun", ctx, mock.MatchedBy(func(run *Run) bool {
 			return run.Status == "FAILED" && run.Error != ""
@@ -342,13 +621,52 @@ func TestService_Run(t *testing.T) {
 		mCatalog.On("GetTotalBooks", ctx).Return(8, nil)
 		mCatalog.On("GetTotalAuthors", ctx).Return(5, nil)
 
-		mOL.On("SearchBooks", ctx, "test", 4).Return(nil, fmt.Errorf("search error"))
+		mIngest.On("GetCheckpoint", ctx, "test").Return(Checkpoint{Subject: "test"}, nil)
+		mOL.On("SearchBooks", ctx, "test", 4, 0).Return(nil, fmt.Errorf("search error"))
 
 		err := s.Run(ctx)
 		assert.Error(t, err)
 		mIngest.AssertExpectations(t)
 	})
 
+	t.Run("keeps the checkpoint when a page fails to hydrate", func(t *testing.T) {
+		mOL := new(mockOLClient)
+		mCatalog := new(mockCatalogRepo)
+		mBook := new(mockBookRepo)
+		mIngest := new(mockIngestRepo)
+
+		s := NewService(mOL, mCatalog, mBook, mIngest, cfg)
+
+		mIngest.On("TryLock", ctx).Return(func() {}, true, nil)
+		mIngest.On("CreateRun", ctx, mock.Anything).Return("run-5", nil)
+		mIngest.On("UpdateRun", ctx, mock.MatchedBy(func(run *Run) bool {
+			return run.Status == "FAILED" && strings.Contains(run.Error, "hydrate failed")
+		})).Return(nil)
+
+		mCatalog.On("GetTotalBooks", ctx).Return(8, nil)
+		mCatalog.On("GetTotalAuthors", ctx).Return(5, nil)
+
+		page := &openlibrary.SearchResponse{NumFound: 50}
+		page.Docs = append(page.Docs, struct {
+			Key              string   `json:"key"`
+			Title            string   `json:"title"`
+			AuthorNames      []string `json:"author_name"`
+			AuthorKeys       []string `json:"author_key"`
+			ISBN             []string `json:"isbn"`
+			FirstPublishYear int      `json:"first_publish_year"`
+			Language         []string `json:"language"`
+		}{ISBN: []string{"9780306406157"}})
+		mIngest.On("GetCheckpoint", ctx, "test").Return(Checkpoint{Subject: "test", Offset: 20, Page: 2}, nil)
+		mOL.On("SearchBooks", ctx, "test", 4, 20).Return(page, nil)
+		mCatalog.On("GetBookUpdatedAt", ctx, "9780306406157").Return(time.Time{}, nil)
+		mOL.On("GetBooksByISBN", ctx, []string{"9780306406157"}).Return(nil, fmt.Errorf("service unavailable"))
+
+		err := s.Run(ctx)
+		assert.Error(t, err)
+		mIngest.AssertNotCalled(t, "SaveCheckpoint", mock.Anything, mock.Anything)
+		mIngest.AssertExpectations(t)
+	})
+
 	t.Run("records failure if GetTotalBooks fails", func(t *testing.T) {
 		mOL := new(mockOLClient)
 		mCatalog := new(mockCatalogRepo)
@@ -357,6 +675,7 @@ func TestService_Run(t *testing.T) {
 
 		s := NewService(mOL, mCatalog, mBook, mIngest, cfg)
 
+		mIngest.On("TryLock", ctx).Return(func() {}, true, nil)
 		mIngest.On("CreateRun", ctx, mock.Anything).Return("run-5", nil)
 		mIngest.On("UpdateRun", ctx, mock.MatchedBy(func(run *Run) bool {
 			return run.Status == "FAILED" && run.Error != ""
@@ -368,4 +687,170 @@ func TestService_Run(t *testing.T) {
 		assert.Error(t, err)
 		mIngest.AssertExpectations(t)
 	})
+
+	t.Run("run already in progress", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		s := NewService(new(mockOLClient), new(mockCatalogRepo), new(mockBookRepo), mIngest, cfg)
+
+		mIngest.On("TryLock", ctx).Return(nil, false, nil)
+
+		err := s.Run(ctx)
+		assert.ErrorIs(t, err, ErrRunInProgress)
+		mIngest.AssertNotCalled(t, "CreateRun", mock.Anything, mock.Anything)
+	})
+}
+
+func TestParseSeries(t *testing.T) {
+	tests := []struct {
+		raw      string
+		name     string
+		position float64
+	}{
+		{"Dune Chronicles ; 1", "Dune Chronicles", 1},
+		{"Discworld (4)", "Discworld", 4},
+		{"The Expanse, book 2.5", "The Expanse", 2.5},
+		{"Wheel of Time #3", "Wheel of Time", 3},
+		{"Harry Potter, no. 7", "Harry Potter", 7},
+	}
+	for _, tt := range tests {
+		ref, ok := parseSeries(tt.raw)
+		assert.True(t, ok, tt.raw)
+		assert.Equal(t, tt.name, ref.Name, tt.raw)
+		if assert.NotNil(t, ref.Position, tt.raw) {
+			assert.Equal(t, tt.position, *ref.Position, tt.raw)
+		}
+	}
+
+	ref, ok := parseSeries("Catch-22")
+	assert.True(t, ok)
+	assert.Equal(t, "Catch-22", ref.Name)
+	assert.Nil(t, ref.Position)
+
+	ref, ok = parseSeries("Foundation series")
+	assert.True(t, ok)
+	assert.Equal(t, "Foundation series", ref.Name)
+	assert.Nil(t, ref.Position)
+
+	// Too large for book_series.position.
+	ref, ok = parseSeries("Foo ; 12345")
+	assert.True(t, ok)
+	assert.Equal(t, "Foo", ref.Name)
+	assert.Nil(t, ref.Position)
+
+	ref, ok = parseSeries("Foo ; 9999.99")
+	assert.True(t, ok)
+	if assert.NotNil(t, ref.Position) {
+		assert.Equal(t, 9999.99, *ref.Position)
+	}
+
+	_, ok = parseSeries("  ")
+	assert.False(t, ok)
+}
+
+func TestCreditRole(t *testing.T) {
+	const stmt = "Fyodor Dostoevsky, translated by Richard Pevear and Larissa Volokhonsky; edited by Jane Doe"
+	assert.Equal(t, book.RoleAuthor, creditRole("Fyodor Dostoevsky", stmt))
+	assert.Equal(t, book.RoleTranslator, creditRole("Richard Pevear", stmt))
+	assert.Equal(t, book.RoleTranslator, creditRole("Larissa Volokhonsky", stmt))
+	assert.Equal(t, book.RoleEditor, creditRole("Jane Doe", stmt))
+	assert.Equal(t, book.RoleAuthor, creditRole("Frank Herbert", ""))
+}
+
+func TestAuthorKey(t *testing.T) {
+	assert.Equal(t, "OL123A", authorKey("/authors/OL123A"))
+	assert.Equal(t, "OL123A", authorKey("https://openlibrary.org/authors/OL123A/Frank_Herbert"))
+	assert.Equal(t, "", authorKey("https://openlibrary.org/works/OL1W"))
+}
+
+func TestWorkLanguage(t *testing.T) {
+	assert.Equal(t, "fr", workLanguage([]string{"fre"}))
+	assert.Equal(t, "", workLanguage([]string{"eng", "fre"}))
+	assert.Equal(t, "", workLanguage(nil))
+}
+
+func TestService_Start(t *testing.T) {
+	ctx := context.Background()
+	cfg := Config{BooksMax: 10, AuthorsMax: 5, Subjects: []string{"test"}, BatchSize: 5}
+
+	t.Run("run already in progress", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		s := NewService(new(mockOLClient), new(mockCatalogRepo), new(mockBookRepo), mIngest, cfg)
+
+		mIngest.On("TryLock", ctx).Return(nil, false, nil)
+		mIngest.On("ActiveRun", ctx).Return(Run{ID: "run-7", Status: StatusRunning}, nil)
+
+		runID, err := s.Start(ctx, time.Minute)
+		assert.ErrorIs(t, err, ErrRunInProgress)
+		assert.Equal(t, "run-7", runID)
+		mIngest.AssertNotCalled(t, "CreateRun", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cancel stops the run and releases the lock", func(t *testing.T) {
+		mOL := new(mockOLClient)
+		mCatalog := new(mockCatalogRepo)
+		mIngest := new(mockIngestRepo)
+		s := NewService(mOL, mCatalog, new(mockBookRepo), mIngest, cfg)
+
+		unlocked := make(chan struct{})
+		finished := make(chan struct{})
+		mIngest.On("TryLock", ctx).Return(func() { close(unlocked) }, true, nil)
+		mIngest.On("CreateRun", ctx, mock.Anything).Return("run-8", nil)
+		mIngest.On("UpdateRun", mock.Anything, mock.MatchedBy(func(run *Run) bool {
+			return run.Status == StatusCancelled
+		})).Run(func(mock.Arguments) { close(finished) }).Return(nil)
+		mCatalog.On("GetTotalBooks", mock.Anything).Return(0, nil)
+		mCatalog.On("GetTotalAuthors", mock.Anything).Return(0, nil)
+		mIngest.On("GetCheckpoint", mock.Anything, "test").Return(Checkpoint{Subject: "test"}, nil).Maybe()
+		mOL.On("SearchBooks", mock.Anything, "test", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).Return(nil, context.Canceled).Maybe()
+
+		runID, err := s.Start(ctx, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "run-8", runID)
+		assert.NoError(t, s.Cancel(ctx, runID))
+
+		<-finished
+		<-unlocked
+		mIngest.AssertExpectations(t)
+	})
+
+	t.Run("cancel a finished run", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		s := NewService(new(mockOLClient), new(mockCatalogRepo), new(mockBookRepo), mIngest, cfg)
+
+		mIngest.On("GetRun", ctx, "run-9").Return(Run{ID: "run-9", Status: StatusCompleted}, nil)
+
+		assert.ErrorIs(t, s.Cancel(ctx, "run-9"), ErrRunNotRunning)
+	})
+}
+
+func TestService_AbortStaleRuns(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("aborts runs when no instance holds the lock", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		s := NewService(nil, nil, nil, mIngest, Config{})
+
+		unlocked := false
+		mIngest.On("TryLock", ctx).Return(func() { unlocked = true }, true, nil)
+		mIngest.On("AbortRunning", ctx).Return(2, nil)
+
+		n, err := s.AbortStaleRuns(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.True(t, unlocked)
+	})
+
+	t.Run("leaves runs alone while another instance holds the lock", func(t *testing.T) {
+		mIngest := new(mockIngestRepo)
+		s := NewService(nil, nil, nil, mIngest, Config{})
+
+		mIngest.On("TryLock", ctx).Return(nil, false, nil)
+
+		n, err := s.AbortStaleRuns(ctx)
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+		mIngest.AssertNotCalled(t, "AbortRunning", mock.Anything)
+	})
 }
@@ -0,0 +1,135 @@
+// Package isbn normalizes and validates International Standard Book Numbers.
+//
+// Books are stored under their canonical form: the 13-digit ISBN without
+// separators. ISBN-10 input is converted by prefixing 978 and recomputing the
+// check digit.
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid ISBN")
+
+// Normalize strips separators (hyphens and spaces) and upper-cases a trailing
+// X check digit. It does not validate the result.
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == 'x' || r == 'X':
+			b.WriteByte('X')
+		case r == '-' || r == ' ':
+		default:
+			// Keep other characters so the result fails validation.
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Valid reports whether s is an ISBN-10 or ISBN-13 with a correct check digit.
+func Valid(s string) bool {
+	_, err := Canonical(s)
+	return err == nil
+}
+
+// Canonical returns the ISBN-13 form of s, or ErrInvalid if s is not a valid
+// ISBN-10 or ISBN-13.
+func Canonical(s string) (string, error) {
+	n := Normalize(s)
+	switch len(n) {
+	case 10:
+		if !valid10(n) {
+			return "", ErrInvalid
+		}
+		body := "978" + n[:9]
+		return body + string(check13(body)), nil
+	case 13:
+		if !allDigits(n) || check13(n[:12]) != n[12] {
+			return "", ErrInvalid
+		}
+		return n, nil
+	default:
+		return "", ErrInvalid
+	}
+}
+
+// Key returns the key to look a book up by: the canonical form of s, or s
+// itself, trimmed, when it is not a valid ISBN. Rows the canonical-ISBN
+// migration could not normalize, such as the legacy "978-%08d" seed ISBNs,
+// are still stored under their original spelling.
+func Key(s string) string {
+	if key, err := Canonical(s); err == nil {
+		return key
+	}
+	return strings.TrimSpace(s)
+}
+
+// Complete appends the ISBN-13 check digit to a 12-digit body.
+func Complete(body string) (string, error) {
+	if len(body) != 12 || !allDigits(body) {
+		return "", ErrInvalid
+	}
+	return body + string(check13(body)), nil
+}
+
+// ToISBN10 returns the ISBN-10 form of a canonical ISBN-13. Only 978-prefixed
+// numbers have one.
+func ToISBN10(isbn13 string) (string, bool) {
+	if len(isbn13) != 13 || !strings.HasPrefix(isbn13, "978") || !allDigits(isbn13) {
+		return "", false
+	}
+	body := isbn13[3:12]
+	return body + string(check10(body)), true
+}
+
+func valid10(n string) bool {
+	if !allDigits(n[:9]) {
+		return false
+	}
+	c := n[9]
+	if c != 'X' && (c < '0' || c > '9') {
+		return false
+	}
+	return check10(n[:9]) == c
+}
+
+// check10 computes the ISBN-10 check digit for a 9-digit body.
+func check10(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	c := (11 - sum%11) % 11
+	if c == 10 {
+		return 'X'
+	}
+	return byte('0' + c)
+}
+
+// check13 computes the ISBN-13 check digit for a 12-digit body.
+func check13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(body[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
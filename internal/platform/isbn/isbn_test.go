@@ -0,0 +1,74 @@
+package isbn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"isbn-13", "9780306406157", "9780306406157"},
+		{"hyphenated isbn-13", "978-0-306-40615-7", "9780306406157"},
+		{"isbn-10", "0306406152", "9780306406157"},
+		{"hyphenated isbn-10", "0-306-40615-2", "9780306406157"},
+		{"isbn-10 with X check digit", "080442957x", "9780804429573"},
+		{"spaces", "0 8044 2957 X", "9780804429573"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonical(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCanonical_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"123",
+		"9780306406158", // wrong check digit
+		"0306406153",    // wrong check digit
+		"978-00000001",  // legacy seed format
+		"97803064061X7",
+		"abcdefghij",
+	} {
+		_, err := Canonical(input)
+		assert.ErrorIs(t, err, ErrInvalid, input)
+		assert.False(t, Valid(input), input)
+	}
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "9780306406157", Key("0-306-40615-2"))
+	assert.Equal(t, "978-00000001", Key(" 978-00000001 "))
+}
+
+func TestToISBN10(t *testing.T) {
+	got, ok := ToISBN10("9780306406157")
+	assert.True(t, ok)
+	assert.Equal(t, "0306406152", got)
+
+	got, ok = ToISBN10("9780804429573")
+	assert.True(t, ok)
+	assert.Equal(t, "080442957X", got)
+
+	_, ok = ToISBN10("9791034304678")
+	assert.False(t, ok, "979 prefix has no ISBN-10 form")
+}
+
+func TestComplete(t *testing.T) {
+	got, err := Complete("978030640615")
+	require.NoError(t, err)
+	assert.Equal(t, "9780306406157", got)
+	assert.True(t, Valid(got))
+
+	_, err = Complete("97803064061")
+	assert.ErrorIs(t, err, ErrInvalid)
+}
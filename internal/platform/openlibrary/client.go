@@ -70,6 +70,11 @@ type BookDetails struct {
 	} `json:"subjects"`
 	NumberOfPages int    `json:"number_of_pages"`
 	Notes         string `json:"notes"`
+	// ByStatement is the title page credit line, e.g.
+	// "Fyodor Dostoevsky ; translated by Richard Pevear".
+	ByStatement string `json:"by_statement"`
+	// Series holds free-form entries such as "Dune Chronicles ; 1".
+	Series []string `json:"series"`
 }
 
 // AuthorDetails matches authors/{key}.json
@@ -81,9 +86,12 @@ type AuthorDetails struct {
 	Photos       []int       `json:"photos"`
 }
 
-func (c *Client) SearchBooks(ctx context.Context, subject string, limit int) (*SearchResponse, error) {
-	u := fmt.Sprintf("%s/search.json?q=subject:%s&fields=key,title,author_name,author_key,isbn,first_publish_year,language&limit=%d",
-		c.baseURL, url.QueryEscape(subject), limit)
+// SearchBooks returns up to limit works about subject, skipping the first
+// offset results. NumFound is the total for the subject, so callers can
+// page until offset reaches it.
+func (c *Client) SearchBooks(ctx context.Context, subject string, limit, offset int) (*SearchResponse, error) {
+	u := fmt.Sprintf("%s/search.json?q=subject:%s&fields=key,title,author_name,author_key,isbn,first_publish_year,language&limit=%d&offset=%d",
+		c.baseURL, url.QueryEscape(subject), limit, offset)
 
 	var res SearchResponse
 	if err := c.get(ctx, u, &res); err != nil {
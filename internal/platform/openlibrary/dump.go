@@ -0,0 +1,137 @@
+package openlibrary
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ErrMalformedDump is returned for a dump line that does not have the five
+// tab-separated columns.
+var ErrMalformedDump = errors.New("malformed dump line")
+
+// DumpRecord is one line of an Open Library bulk data dump
+// (https://openlibrary.org/developers/dumps): the record type, key,
+// revision, last modified time and the record itself as JSON.
+type DumpRecord struct {
+	Type         string
+	Key          string
+	Revision     int
+	LastModified string
+	JSON         []byte
+}
+
+// DumpReader streams the records of an uncompressed dump.
+type DumpReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func NewDumpReader(r io.Reader) *DumpReader {
+	return &DumpReader{r: bufio.NewReaderSize(r, 1<<20)}
+}
+
+// Next returns the next record, or io.EOF after the last one. A malformed
+// line returns an error wrapping ErrMalformedDump; reading can continue
+// with the following line.
+func (d *DumpReader) Next() (DumpRecord, error) {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return DumpRecord{}, err
+		}
+		if err != nil && err != io.EOF {
+			return DumpRecord{}, err
+		}
+		d.line++
+
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			continue
+		}
+		cols := bytes.SplitN(line, []byte{'\t'}, 5)
+		if len(cols) != 5 {
+			return DumpRecord{}, fmt.Errorf("%w: line %d has %d columns", ErrMalformedDump, d.line, len(cols))
+		}
+		rev, _ := strconv.Atoi(string(cols[2]))
+		return DumpRecord{
+			Type:         string(cols[0]),
+			Key:          string(cols[1]),
+			Revision:     rev,
+			LastModified: string(cols[3]),
+			JSON:         cols[4],
+		}, nil
+	}
+}
+
+// Record types found in the dumps.
+const (
+	DumpTypeEdition = "/type/edition"
+	DumpTypeWork    = "/type/work"
+	DumpTypeAuthor  = "/type/author"
+)
+
+// DumpRef is a reference to another record, such as {"key": "/works/OL1W"}.
+type DumpRef struct {
+	Key string `json:"key"`
+}
+
+// DumpText is a text field, stored either as a string or as
+// {"type": "/type/text", "value": "..."}.
+type DumpText string
+
+func (t *DumpText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = DumpText(s)
+		return nil
+	}
+	var v struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = DumpText(v.Value)
+	return nil
+}
+
+// DumpEdition is the JSON of an edition record.
+type DumpEdition struct {
+	Key           string    `json:"key"`
+	Title         string    `json:"title"`
+	Subtitle      string    `json:"subtitle"`
+	ISBN13        []string  `json:"isbn_13"`
+	ISBN10        []string  `json:"isbn_10"`
+	Publishers    []string  `json:"publishers"`
+	PublishDate   string    `json:"publish_date"`
+	NumberOfPages int       `json:"number_of_pages"`
+	Languages     []DumpRef `json:"languages"`
+	Subjects      []string  `json:"subjects"`
+	Works         []DumpRef `json:"works"`
+	Authors       []DumpRef `json:"authors"`
+	ByStatement   string    `json:"by_statement"`
+	Series        []string  `json:"series"`
+	Covers        []int     `json:"covers"`
+	Description   DumpText  `json:"description"`
+}
+
+// DumpWork is the JSON of a work record.
+type DumpWork struct {
+	Key         string   `json:"key"`
+	Title       string   `json:"title"`
+	Subjects    []string `json:"subjects"`
+	Description DumpText `json:"description"`
+}
+
+// DumpAuthor is the JSON of an author record.
+type DumpAuthor struct {
+	Key       string   `json:"key"`
+	Name      string   `json:"name"`
+	BirthDate string   `json:"birth_date"`
+	Bio       DumpText `json:"bio"`
+}
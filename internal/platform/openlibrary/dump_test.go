@@ -0,0 +1,45 @@
+package openlibrary
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpReader(t *testing.T) {
+	dump := strings.Join([]string{
+		"/type/edition\t/books/OL1M\t3\t2024-01-02T03:04:05.000000\t{\"title\": \"Dune\", \"isbn_13\": [\"9780441172719\"]}",
+		"",
+		"not a record",
+		"/type/author\t/authors/OL1A\t1\t2024-01-02T03:04:05.000000\t{\"name\": \"Frank Herbert\", \"bio\": {\"type\": \"/type/text\", \"value\": \"Author of Dune.\"}}",
+	}, "\n")
+	r := NewDumpReader(strings.NewReader(dump))
+
+	rec, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, DumpTypeEdition, rec.Type)
+	assert.Equal(t, "/books/OL1M", rec.Key)
+	assert.Equal(t, 3, rec.Revision)
+	var edition DumpEdition
+	require.NoError(t, json.Unmarshal(rec.JSON, &edition))
+	assert.Equal(t, "Dune", edition.Title)
+	assert.Equal(t, []string{"9780441172719"}, edition.ISBN13)
+
+	_, err = r.Next()
+	assert.True(t, errors.Is(err, ErrMalformedDump))
+
+	rec, err = r.Next()
+	require.NoError(t, err)
+	var author DumpAuthor
+	require.NoError(t, json.Unmarshal(rec.JSON, &author))
+	assert.Equal(t, "Frank Herbert", author.Name)
+	assert.Equal(t, DumpText("Author of Dune."), author.Bio)
+
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,119 @@
+package textsearch
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is assumed for rows and requests that name no language;
+// it is also the default of the books.language column.
+const DefaultLanguage = "en"
+
+// SimpleConfig is the fallback config for languages without a stemmer. It
+// lower-cases and unaccents words but does not stem them.
+const SimpleConfig = "simple_unaccent"
+
+// configs maps ISO 639-1 codes to the text search configs created in
+// db/migrations/015_language_search_configs.sql. Keep the two in sync with
+// the search_config SQL function.
+var configs = map[string]string{
+	"en": "english_unaccent",
+	"fr": "french_unaccent",
+	"de": "german_unaccent",
+	"es": "spanish_unaccent",
+	"it": "italian_unaccent",
+	"pt": "portuguese_unaccent",
+	"nl": "dutch_unaccent",
+}
+
+// marcCodes maps the MARC language codes used by Open Library to ISO 639-1.
+var marcCodes = map[string]string{
+	"eng": "en",
+	"fre": "fr",
+	"fra": "fr",
+	"ger": "de",
+	"deu": "de",
+	"spa": "es",
+	"ita": "it",
+	"por": "pt",
+	"dut": "nl",
+	"nld": "nl",
+	"chi": "zh",
+	"zho": "zh",
+	"jpn": "ja",
+	"rus": "ru",
+}
+
+// LanguageCode normalizes a language tag ("en-US", "FR") or MARC code
+// ("ger") to its ISO 639-1 code. Unknown tags are returned lower-cased.
+func LanguageCode(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag, _, _ = strings.Cut(tag, "-")
+	tag, _, _ = strings.Cut(tag, "_")
+	if code, ok := marcCodes[tag]; ok {
+		return code
+	}
+	return tag
+}
+
+// Config returns the text search config for a language, SimpleConfig when
+// there is none, and the DefaultLanguage config when lang is empty. The
+// result is always one of the fixed config names, so it is safe to inline
+// into SQL.
+func Config(lang string) string {
+	code := LanguageCode(lang)
+	if code == "" {
+		code = DefaultLanguage
+	}
+	if cfg, ok := configs[code]; ok {
+		return cfg
+	}
+	return SimpleConfig
+}
+
+// QueryLanguage picks the language a search is parsed in: the language
+// filter when there is one, otherwise the most preferred language of an
+// Accept-Language header that has a config. It returns "" for neither,
+// which Config treats as DefaultLanguage.
+func QueryLanguage(language, acceptLanguage string) string {
+	if language != "" {
+		return language
+	}
+	return preferredLanguage(acceptLanguage)
+}
+
+// preferredLanguage returns the highest weighted tag of an Accept-Language
+// header that has a config, or "" if none does.
+func preferredLanguage(header string) string {
+	type pref struct {
+		tag string
+		q   float64
+	}
+	var prefs []pref
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			prefs = append(prefs, pref{tag, q})
+		}
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+
+	for _, p := range prefs {
+		if _, ok := configs[LanguageCode(p.tag)]; ok {
+			return p.tag
+		}
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package textsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageCode(t *testing.T) {
+	assert.Equal(t, "en", LanguageCode("EN"))
+	assert.Equal(t, "pt", LanguageCode("pt-BR"))
+	assert.Equal(t, "pt", LanguageCode("pt_BR"))
+	assert.Equal(t, "de", LanguageCode("ger"))
+	assert.Equal(t, "fr", LanguageCode("fre"))
+	assert.Equal(t, "xx", LanguageCode(" xx "))
+}
+
+func TestConfig(t *testing.T) {
+	assert.Equal(t, "french_unaccent", Config("fr"))
+	assert.Equal(t, "german_unaccent", Config("de-AT"))
+	assert.Equal(t, "english_unaccent", Config(""))
+	assert.Equal(t, SimpleConfig, Config("zh"))
+	assert.Equal(t, SimpleConfig, Config("'; DROP TABLE books; --"))
+}
+
+func TestQueryLanguage(t *testing.T) {
+	t.Run("language filter wins", func(t *testing.T) {
+		assert.Equal(t, "es", QueryLanguage("es", "fr-FR,fr;q=0.9"))
+	})
+
+	t.Run("accept-language by weight", func(t *testing.T) {
+		assert.Equal(t, "de", QueryLanguage("", "en;q=0.5, de, fr;q=0.8"))
+	})
+
+	t.Run("skips languages without a config", func(t *testing.T) {
+		assert.Equal(t, "fr-CA", QueryLanguage("", "ja, fr-CA;q=0.7, *;q=0.1"))
+	})
+
+	t.Run("nothing usable", func(t *testing.T) {
+		assert.Equal(t, "", QueryLanguage("", "ja, en;q=0, es;q=bad"))
+		assert.Equal(t, "", QueryLanguage("", ""))
+	})
+}
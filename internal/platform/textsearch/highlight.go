@@ -0,0 +1,66 @@
+// Package textsearch holds helpers shared by the Postgres full-text search
+// queries of the books and catalog repositories.
+package textsearch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Default highlight markers, used when the client sets none.
+const (
+	DefaultStartSel = "<mark>"
+	DefaultStopSel  = "</mark>"
+
+	maxMarkerLen = 32
+)
+
+// ErrInvalidMarker is returned for highlight markers ts_headline cannot take.
+var ErrInvalidMarker = errors.New("must be at most 32 characters and must not contain double quotes")
+
+// Highlights are ts_headline snippets of a search result, with matched words
+// wrapped in the highlight markers.
+type Highlights struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Highlight configures the markers wrapped around matched words. Empty
+// markers fall back to the defaults.
+type Highlight struct {
+	StartSel string
+	StopSel  string
+}
+
+// CheckMarker reports whether ts_headline can take m as a marker.
+func CheckMarker(m string) error {
+	if len(m) > maxMarkerLen || strings.Contains(m, `"`) {
+		return ErrInvalidMarker
+	}
+	return nil
+}
+
+// TitleOptions returns ts_headline options that keep the whole title.
+func (h Highlight) TitleOptions() string {
+	return h.options("HighlightAll=true")
+}
+
+// SnippetOptions returns ts_headline options that cut long text down to the
+// fragments around the matches.
+func (h Highlight) SnippetOptions() string {
+	return h.options(`MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`)
+}
+
+// options quotes the markers so that spaces, commas and "=" survive
+// ts_headline's option parser. The result is passed as a query parameter.
+func (h Highlight) options(extra string) string {
+	start, stop := h.StartSel, h.StopSel
+	if start == "" {
+		start = DefaultStartSel
+	}
+	if stop == "" {
+		stop = DefaultStopSel
+	}
+	return fmt.Sprintf(`StartSel="%s", StopSel="%s", %s`, start, stop, extra)
+}
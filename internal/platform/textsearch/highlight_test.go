@@ -0,0 +1,26 @@
+package textsearch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckMarker(t *testing.T) {
+	assert.NoError(t, CheckMarker("<em class=hit>"))
+	assert.NoError(t, CheckMarker(""))
+	assert.ErrorIs(t, CheckMarker(`<b">`), ErrInvalidMarker)
+	assert.ErrorIs(t, CheckMarker(strings.Repeat("x", 33)), ErrInvalidMarker)
+}
+
+func TestHighlight_TitleOptions(t *testing.T) {
+	h := Highlight{StartSel: "<em class=hit>", StopSel: "</em>"}
+	assert.Equal(t, `StartSel="<em class=hit>", StopSel="</em>", HighlightAll=true`, h.TitleOptions())
+}
+
+func TestHighlight_SnippetOptions(t *testing.T) {
+	opts := Highlight{}.SnippetOptions()
+	assert.Contains(t, opts, `StartSel="<mark>"`)
+	assert.Contains(t, opts, "MaxFragments=2")
+}
@@ -0,0 +1,242 @@
+package textsearch
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Field qualifiers understood by Parse.
+const (
+	FieldTitle     = "title"
+	FieldAuthor    = "author"
+	FieldPublisher = "publisher"
+	FieldGenre     = "genre"
+	FieldLanguage  = "language"
+	FieldYear      = "year"
+)
+
+// fieldAliases maps accepted spellings to their qualifier.
+var fieldAliases = map[string]string{
+	"title":     FieldTitle,
+	"author":    FieldAuthor,
+	"by":        FieldAuthor,
+	"publisher": FieldPublisher,
+	"pub":       FieldPublisher,
+	"genre":     FieldGenre,
+	"language":  FieldLanguage,
+	"lang":      FieldLanguage,
+	"year":      FieldYear,
+}
+
+// Parsed is an advanced search split into its free text and field filters.
+type Parsed struct {
+	// Text is what remains after the qualifiers are removed, in
+	// websearch_to_tsquery syntax: "exact phrase", -excluded, a OR b.
+	Text      string
+	Title     string
+	Author    string
+	Publisher string
+	Genre     string
+	Language  string
+	YearFrom  *int
+	YearTo    *int
+}
+
+// SyntaxError describes one problem in a search string. Pos is the byte
+// offset of the offending term.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s (at position %d)", e.Msg, e.Pos)
+}
+
+// SyntaxErrors collects every problem found by Parse.
+type SyntaxErrors []*SyntaxError
+
+func (e SyntaxErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Parse splits an advanced search such as
+//
+//	title:"dune" publisher:ace -sequel year:>1980
+//
+// into free text and field filters. Only the qualifiers in allowed are
+// recognized; a word like "re:zero" whose prefix is not a qualifier stays
+// free text. Malformed input returns SyntaxErrors.
+func Parse(input string, allowed ...string) (Parsed, error) {
+	var p Parsed
+	var text []string
+	var errs SyntaxErrors
+	seen := make(map[string]bool)
+
+	for pos := 0; pos < len(input); {
+		if isSpace(input[pos]) {
+			pos++
+			continue
+		}
+		start := pos
+
+		if input[pos] == '"' {
+			phrase, next, ok := readQuoted(input, pos)
+			if !ok {
+				errs = append(errs, &SyntaxError{start, "unterminated quote"})
+				break
+			}
+			if strings.TrimSpace(phrase) != "" {
+				text = append(text, `"`+phrase+`"`)
+			}
+			pos = next
+			continue
+		}
+
+		for pos < len(input) && !isSpace(input[pos]) && input[pos] != '"' {
+			pos++
+		}
+		word := input[start:pos]
+
+		if word == "-" && pos < len(input) && input[pos] == '"' {
+			phrase, next, ok := readQuoted(input, pos)
+			if !ok {
+				errs = append(errs, &SyntaxError{pos, "unterminated quote"})
+				break
+			}
+			text = append(text, `-"`+phrase+`"`)
+			pos = next
+			continue
+		}
+
+		name, rest, isField := strings.Cut(word, ":")
+		negated := strings.HasPrefix(name, "-")
+		field, known := fieldAliases[strings.ToLower(strings.TrimPrefix(name, "-"))]
+		if !isField || !known || !slices.Contains(allowed, field) {
+			if isField && known {
+				errs = append(errs, &SyntaxError{start, fmt.Sprintf("%s: is not supported here", field)})
+				continue
+			}
+			text = append(text, word)
+			continue
+		}
+
+		value := rest
+		if value == "" && pos < len(input) && input[pos] == '"' {
+			quoted, next, ok := readQuoted(input, pos)
+			if !ok {
+				errs = append(errs, &SyntaxError{pos, "unterminated quote"})
+				break
+			}
+			value, pos = quoted, next
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case negated:
+			errs = append(errs, &SyntaxError{start, fmt.Sprintf("%s: cannot be negated", field)})
+		case value == "":
+			errs = append(errs, &SyntaxError{start, fmt.Sprintf("%s: needs a value", field)})
+		case seen[field]:
+			errs = append(errs, &SyntaxError{start, fmt.Sprintf("%s: given more than once", field)})
+		default:
+			seen[field] = true
+			if err := p.set(field, value); err != nil {
+				errs = append(errs, &SyntaxError{start, err.Error()})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return Parsed{}, errs
+	}
+	p.Text = strings.Join(text, " ")
+	return p, nil
+}
+
+func (p *Parsed) set(field, value string) error {
+	switch field {
+	case FieldTitle:
+		p.Title = value
+	case FieldAuthor:
+		p.Author = value
+	case FieldPublisher:
+		p.Publisher = value
+	case FieldGenre:
+		p.Genre = value
+	case FieldLanguage:
+		p.Language = strings.ToLower(value)
+	case FieldYear:
+		from, to, err := parseYears(value)
+		if err != nil {
+			return err
+		}
+		p.YearFrom, p.YearTo = from, to
+	}
+	return nil
+}
+
+// parseYears reads 1980, >1980, >=1980, <1990, <=1990 or 1980..1990.
+func parseYears(v string) (from, to *int, err error) {
+	bad := fmt.Errorf("year: %q is not a year, comparison (>1980) or range (1980..1990)", v)
+	if lo, hi, ok := strings.Cut(v, ".."); ok {
+		a, errA := parseYear(lo)
+		b, errB := parseYear(hi)
+		if errA != nil || errB != nil {
+			return nil, nil, bad
+		}
+		if a > b {
+			return nil, nil, fmt.Errorf("year: range %q is reversed", v)
+		}
+		return &a, &b, nil
+	}
+
+	op := strings.TrimRightFunc(v, unicode.IsDigit)
+	y, yErr := parseYear(v[len(op):])
+	if yErr != nil {
+		return nil, nil, bad
+	}
+	switch op {
+	case "":
+		return &y, &y, nil
+	case ">":
+		y++
+		return &y, nil, nil
+	case ">=":
+		return &y, nil, nil
+	case "<":
+		y--
+		return nil, &y, nil
+	case "<=":
+		return nil, &y, nil
+	}
+	return nil, nil, bad
+}
+
+func parseYear(s string) (int, error) {
+	if len(s) == 0 || len(s) > 4 {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.Atoi(s)
+}
+
+// readQuoted reads the phrase opening at input[pos] == '"', returning it
+// without quotes and the offset after the closing quote.
+func readQuoted(input string, pos int) (string, int, bool) {
+	end := strings.IndexByte(input[pos+1:], '"')
+	if end < 0 {
+		return "", len(input), false
+	}
+	return input[pos+1 : pos+1+end], pos + end + 2, true
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,81 @@
+package textsearch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var allFields = []string{FieldTitle, FieldAuthor, FieldPublisher, FieldGenre, FieldLanguage, FieldYear}
+
+func TestParse(t *testing.T) {
+	p, err := Parse(`title:"dune" publisher:ace -sequel year:>1980`, allFields...)
+	require.NoError(t, err)
+	assert.Equal(t, "-sequel", p.Text)
+	assert.Equal(t, "dune", p.Title)
+	assert.Equal(t, "ace", p.Publisher)
+	require.NotNil(t, p.YearFrom)
+	assert.Equal(t, 1981, *p.YearFrom)
+	assert.Nil(t, p.YearTo)
+
+	p, err = Parse(`"desert planet" OR arrakis -"god emperor" by:"Frank Herbert" lang:EN`, allFields...)
+	require.NoError(t, err)
+	assert.Equal(t, `"desert planet" OR arrakis -"god emperor"`, p.Text)
+	assert.Equal(t, "Frank Herbert", p.Author)
+	assert.Equal(t, "en", p.Language)
+
+	p, err = Parse("re:zero Dune: messiah", allFields...)
+	require.NoError(t, err)
+	assert.Equal(t, "re:zero Dune: messiah", p.Text)
+}
+
+func TestParse_Years(t *testing.T) {
+	tests := []struct {
+		in       string
+		from, to *int
+	}{
+		{"year:1965", ptr(1965), ptr(1965)},
+		{"year:>=1965", ptr(1965), nil},
+		{"year:<1990", nil, ptr(1989)},
+		{"year:<=1990", nil, ptr(1990)},
+		{"year:1960..1970", ptr(1960), ptr(1970)},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.in, FieldYear)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.from, p.YearFrom, tt.in)
+		assert.Equal(t, tt.to, p.YearTo, tt.in)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		in  string
+		msg string
+	}{
+		{`title:"dune`, "unterminated quote (at position 6)"},
+		{`"desert planet`, "unterminated quote (at position 0)"},
+		{`title: dune`, "title: needs a value (at position 0)"},
+		{`dune -publisher:ace`, "publisher: cannot be negated (at position 5)"},
+		{`year:soon`, `year: "soon" is not a year, comparison (>1980) or range (1980..1990) (at position 0)`},
+		{`year:1990..1980`, `year: range "1990..1980" is reversed (at position 0)`},
+		{`title:a title:b`, "title: given more than once (at position 8)"},
+		{`author:herbert`, "author: is not supported here (at position 0)"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.in, FieldTitle, FieldPublisher, FieldYear)
+		var errs SyntaxErrors
+		require.True(t, errors.As(err, &errs), tt.in)
+		require.Len(t, errs, 1, tt.in)
+		assert.Equal(t, tt.msg, errs[0].Error(), tt.in)
+	}
+
+	_, err := Parse("year:x title:", FieldTitle, FieldYear)
+	var errs SyntaxErrors
+	require.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 2)
+}
+
+func ptr(i int) *int { return &i }
@@ -104,6 +104,15 @@ func (h *HTTPHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 
 	p, err := h.service.UpdateProfile(r.Context(), userID, cmd)
 	if err != nil {
+		var verrs ValidationErrors
+		if errors.As(err, &verrs) {
+			details := make([]httpx.ErrorDetail, len(verrs))
+			for i, fe := range verrs {
+				details[i] = httpx.ErrorDetail{Field: fe.Field, Message: fe.Message}
+			}
+			httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", details)
+			return
+		}
 		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", err.Error(), nil)
 		return
 	}
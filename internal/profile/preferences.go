@@ -0,0 +1,121 @@
+package profile
+
+import (
+	"bookapi/internal/book"
+	"bookapi/internal/platform/textsearch"
+	"bookapi/internal/user"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxFavoriteGenres  = 10
+	maxLanguages       = 10
+	maxAvoidContent    = 20
+	maxAvoidContentLen = 50
+	maxYearlyGoal      = 1000
+)
+
+// languagePattern matches the ISO 639 codes left after normalization.
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// FieldError is a problem with one field of an update.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationErrors lists every problem found in an update.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// validatePreferences checks reading preferences against the current
+// schema and returns them normalized: genre names become slugs, language
+// tags ISO codes and tags lower case, with repeats dropped.
+func (s *Service) validatePreferences(ctx context.Context, p user.ReadingPreferences) (user.ReadingPreferences, error) {
+	var errs ValidationErrors
+	field := func(name string) string { return "reading_preferences." + name }
+
+	if p.Version != 0 && p.Version != user.PreferencesVersion {
+		errs = append(errs, FieldError{field("version"), fmt.Sprintf("unsupported version; the current version is %d", user.PreferencesVersion)})
+	}
+	out := user.ReadingPreferences{Version: user.PreferencesVersion, YearlyGoal: p.YearlyGoal}
+
+	out.FavoriteGenres = dedupe(p.FavoriteGenres, book.GenreSlug)
+	if len(out.FavoriteGenres) > maxFavoriteGenres {
+		errs = append(errs, FieldError{field("favorite_genres"), fmt.Sprintf("at most %d genres", maxFavoriteGenres)})
+	} else {
+		for _, slug := range out.FavoriteGenres {
+			ok, err := s.genreService.Exists(ctx, slug)
+			if err != nil {
+				return user.ReadingPreferences{}, err
+			}
+			if !ok {
+				errs = append(errs, FieldError{field("favorite_genres"), fmt.Sprintf("unknown genre %q", slug)})
+			}
+		}
+	}
+
+	out.Languages = dedupe(p.Languages, textsearch.LanguageCode)
+	if len(out.Languages) > maxLanguages {
+		errs = append(errs, FieldError{field("languages"), fmt.Sprintf("at most %d languages", maxLanguages)})
+	}
+	for _, lang := range out.Languages {
+		if !languagePattern.MatchString(lang) {
+			errs = append(errs, FieldError{field("languages"), fmt.Sprintf("%q is not a language code", lang)})
+		}
+	}
+
+	out.PreferredFormats = dedupe(p.PreferredFormats, strings.ToLower)
+	for _, format := range out.PreferredFormats {
+		if !user.ValidateFormat(format) {
+			errs = append(errs, FieldError{field("preferred_formats"), fmt.Sprintf("%q must be one of hardcover, paperback, ebook, audiobook", format)})
+		}
+	}
+
+	out.AvoidContent = dedupe(p.AvoidContent, func(s string) string {
+		return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	})
+	if len(out.AvoidContent) > maxAvoidContent {
+		errs = append(errs, FieldError{field("avoid_content"), fmt.Sprintf("at most %d entries", maxAvoidContent)})
+	}
+	for _, tag := range out.AvoidContent {
+		if len([]rune(tag)) > maxAvoidContentLen {
+			errs = append(errs, FieldError{field("avoid_content"), fmt.Sprintf("entries must be at most %d characters", maxAvoidContentLen)})
+			break
+		}
+	}
+
+	if p.YearlyGoal != nil && (*p.YearlyGoal < 1 || *p.YearlyGoal > maxYearlyGoal) {
+		errs = append(errs, FieldError{field("yearly_goal"), fmt.Sprintf("must be between 1 and %d", maxYearlyGoal)})
+	}
+
+	if len(errs) > 0 {
+		return user.ReadingPreferences{}, errs
+	}
+	return out, nil
+}
+
+// dedupe normalizes values, dropping blanks and repeats but keeping order.
+func dedupe(values []string, norm func(string) string) []string {
+	out := []string{}
+	seen := make(map[string]bool)
+	for _, v := range values {
+		v = norm(strings.TrimSpace(v))
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
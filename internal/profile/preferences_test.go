@@ -0,0 +1,75 @@
+package profile
+
+import (
+	"context"
+	"testing"
+
+	"bookapi/internal/genre"
+	"bookapi/internal/user"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ValidatePreferences(t *testing.T) {
+	ctx := context.Background()
+	goal := func(n int) *int { return &n }
+
+	t.Run("normalizes valid preferences", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		genres := genre.NewMockRepository(ctrl)
+		genres.EXPECT().Exists(ctx, "science-fiction").Return(true, nil)
+		genres.EXPECT().Exists(ctx, "fantasy").Return(true, nil)
+		service := NewService(nil, nil, nil, genre.NewService(genres))
+
+		got, err := service.validatePreferences(ctx, user.ReadingPreferences{
+			FavoriteGenres:   []string{"Science Fiction", "fantasy", "science-fiction"},
+			Languages:        []string{"en-US", "ger", "en"},
+			PreferredFormats: []string{"Ebook"},
+			AvoidContent:     []string{"  Graphic   Violence "},
+			YearlyGoal:       goal(24),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, user.ReadingPreferences{
+			Version:          user.PreferencesVersion,
+			FavoriteGenres:   []string{"science-fiction", "fantasy"},
+			Languages:        []string{"en", "de"},
+			PreferredFormats: []string{"ebook"},
+			AvoidContent:     []string{"graphic violence"},
+			YearlyGoal:       goal(24),
+		}, got)
+	})
+
+	t.Run("reports every invalid field", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		genres := genre.NewMockRepository(ctrl)
+		genres.EXPECT().Exists(ctx, "vampires").Return(false, nil)
+		service := NewService(nil, nil, nil, genre.NewService(genres))
+
+		_, err := service.validatePreferences(ctx, user.ReadingPreferences{
+			Version:          2,
+			FavoriteGenres:   []string{"vampires"},
+			Languages:        []string{"english"},
+			PreferredFormats: []string{"scroll"},
+			YearlyGoal:       goal(0),
+		})
+
+		var verrs ValidationErrors
+		require.ErrorAs(t, err, &verrs)
+		fields := make([]string, len(verrs))
+		for i, fe := range verrs {
+			fields[i] = fe.Field
+		}
+		assert.Equal(t, []string{
+			"reading_preferences.version",
+			"reading_preferences.favorite_genres",
+			"reading_preferences.languages",
+			"reading_preferences.preferred_formats",
+			"reading_preferences.yearly_goal",
+		}, fields)
+	})
+}
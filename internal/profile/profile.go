@@ -15,13 +15,15 @@ type Profile struct {
 	Stats Stats     `json:"stats"`
 }
 
+// UpdateCommand is a partial profile update. Reading preferences, when
+// given, replace the stored ones as a whole.
 type UpdateCommand struct {
-	Username           *string `json:"username"`
-	Bio                *string `json:"bio"`
-	Location           *string `json:"location"`
-	Website            *string `json:"website"`
-	IsPublic           *bool   `json:"is_public"`
-	ReadingPreferences []byte  `json:"reading_preferences"`
+	Username           *string                  `json:"username"`
+	Bio                *string                  `json:"bio"`
+	Location           *string                  `json:"location"`
+	Website            *string                  `json:"website"`
+	IsPublic           *bool                    `json:"is_public"`
+	ReadingPreferences *user.ReadingPreferences `json:"reading_preferences"`
 }
 
 func (c *UpdateCommand) ToMap() map[string]any {
@@ -42,7 +44,7 @@ func (c *UpdateCommand) ToMap() map[string]any {
 		updates["is_public"] = *c.IsPublic
 	}
 	if c.ReadingPreferences != nil {
-		updates["reading_preferences"] = c.ReadingPreferences
+		updates["reading_preferences"] = *c.ReadingPreferences
 	}
 	return updates
 }
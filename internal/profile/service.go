@@ -1,6 +1,7 @@
 package profile
 
 import (
+	"bookapi/internal/genre"
 	"bookapi/internal/rating"
 	"bookapi/internal/readinglist"
 	"bookapi/internal/user"
@@ -14,13 +15,15 @@ type Service struct {
 	userService        *user.Service
 	ratingService      *rating.Service
 	readingListService *readinglist.Service
+	genreService       *genre.Service
 }
 
-func NewService(userService *user.Service, ratingService *rating.Service, readingListService *readinglist.Service) *Service {
+func NewService(userService *user.Service, ratingService *rating.Service, readingListService *readinglist.Service, genreService *genre.Service) *Service {
 	return &Service{
 		userService:        userService,
 		ratingService:      ratingService,
 		readingListService: readingListService,
+		genreService:       genreService,
 	}
 }
 
@@ -80,6 +83,14 @@ func (s *Service) UpdateProfile(ctx context.Context, userID string, cmd UpdateCo
 		}
 	}
 
+	if cmd.ReadingPreferences != nil {
+		prefs, err := s.validatePreferences(ctx, *cmd.ReadingPreferences)
+		if err != nil {
+			return Profile{}, err
+		}
+		updates["reading_preferences"] = prefs
+	}
+
 	if err := s.userService.UpdateProfile(ctx, userID, updates); err != nil {
 		return Profile{}, err
 	}
@@ -2,6 +2,7 @@ package rating
 
 import (
 	"bookapi/internal/httpx"
+	"bookapi/internal/platform/isbn"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -41,11 +42,7 @@ func (h *HTTPHandler) CreateRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isbn := r.PathValue("isbn")
-	if isbn == "" {
-		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid ISBN", nil)
-		return
-	}
+	bookISBN := isbn.Key(r.PathValue("isbn"))
 
 	var req createRatingReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -58,7 +55,7 @@ func (h *HTTPHandler) CreateRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.CreateOrUpdate(r.Context(), userID, isbn, req.Star); err != nil {
+	if err := h.service.CreateOrUpdate(r.Context(), userID, bookISBN, req.Star); err != nil {
 		if errors.Is(err, ErrInternalNotFound) {
 			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Book not found", nil)
 			return
@@ -72,30 +69,38 @@ func (h *HTTPHandler) CreateRating(w http.ResponseWriter, r *http.Request) {
 
 // GetRating handles GET /books/{isbn}/rating
 // @Summary Get book rating
-// @Description Get average rating and total count for a book
+// @Description Get average rating and total count for a book. When the book is one edition of a work, the rating across all editions is included as well.
 // @Tags ratings
 // @Accept json
 // @Produce json
 // @Param isbn path string true "Book ISBN"
 // @Success 200 {object} httpx.SuccessResponse
-// @Failure 400 {object} httpx.ErrorResponse
 // @Failure 500 {object} httpx.ErrorResponse
 // @Router /books/{isbn}/rating [get]
 func (h *HTTPHandler) GetRating(w http.ResponseWriter, r *http.Request) {
-	isbn := r.PathValue("isbn")
-	if isbn == "" {
-		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid ISBN", nil)
-		return
-	}
+	bookISBN := isbn.Key(r.PathValue("isbn"))
 
-	average, count, err := h.service.GetBookRating(r.Context(), isbn)
+	average, count, err := h.service.GetBookRating(r.Context(), bookISBN)
 	if err != nil {
 		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
 		return
 	}
 
-	httpx.JSONSuccess(w, r, map[string]any{
+	data := map[string]any{
 		"average_rating": average,
 		"ratings_count":  count,
-	}, nil)
+	}
+
+	// Editions of the same work share a combined rating.
+	workAverage, workCount, err := h.service.GetWorkRating(r.Context(), bookISBN)
+	switch {
+	case err == nil:
+		data["work_average_rating"] = workAverage
+		data["work_ratings_count"] = workCount
+	case !errors.Is(err, ErrInternalNotFound):
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, data, nil)
 }
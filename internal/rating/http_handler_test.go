@@ -0,0 +1,74 @@
+package rating
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func reconcileRequest(secret string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/internal/jobs/ratings/reconcile", nil)
+	if secret != "" {
+		r.Header.Set("X-Internal-Secret", secret)
+	}
+	return r
+}
+
+func TestJobHandler_Reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+
+	t.Run("invalid secret", func(t *testing.T) {
+		handler := NewJobHandler(NewService(mockRepo), "secret")
+		w := httptest.NewRecorder()
+
+		handler.Reconcile(w, reconcileRequest("wrong"))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("unset secret fails closed", func(t *testing.T) {
+		handler := NewJobHandler(NewService(mockRepo), "")
+		w := httptest.NewRecorder()
+
+		handler.Reconcile(w, reconcileRequest(""))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("starts one reconciliation at a time", func(t *testing.T) {
+		handler := NewJobHandler(NewService(mockRepo), "secret")
+		started := make(chan struct{})
+		release := make(chan struct{})
+		mockRepo.EXPECT().ReconcileAggregates(gomock.Any()).DoAndReturn(func(ctx context.Context) (int, error) {
+			close(started)
+			<-release
+			return 3, nil
+		})
+
+		w := httptest.NewRecorder()
+		handler.Reconcile(w, reconcileRequest("secret"))
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		<-started
+
+		w = httptest.NewRecorder()
+		handler.Reconcile(w, reconcileRequest("secret"))
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "CONFLICT")
+
+		close(release)
+		assert.Eventually(t, func() bool { return !handler.reconciling.Load() }, time.Second, time.Millisecond)
+
+		mockRepo.EXPECT().ReconcileAggregates(gomock.Any()).Return(0, nil)
+		w = httptest.NewRecorder()
+		handler.Reconcile(w, reconcileRequest("secret"))
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Eventually(t, func() bool { return !handler.reconciling.Load() }, time.Second, time.Millisecond)
+	})
+}
@@ -0,0 +1,59 @@
+package rating
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"bookapi/internal/httpx"
+)
+
+// JobHandler serves the internal rating maintenance jobs.
+type JobHandler struct {
+	service *Service
+	secret  string
+
+	// reconciling is set while a reconciliation started here is running.
+	reconciling atomic.Bool
+}
+
+func NewJobHandler(service *Service, secret string) *JobHandler {
+	return &JobHandler{service: service, secret: secret}
+}
+
+// Reconcile handles POST /internal/jobs/ratings/reconcile
+// @Summary Reconcile rating aggregates
+// @Description Recompute the rating average, count and histogram kept on every book from the ratings table
+// @Tags internal
+// @Accept json
+// @Produce json
+// @Param X-Internal-Secret header string true "Internal secret for authentication"
+// @Success 202 {object} httpx.SuccessResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 409 {object} httpx.ErrorResponse
+// @Router /internal/jobs/ratings/reconcile [post]
+func (h *JobHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	if !httpx.AuthorizeInternal(w, r, h.secret) {
+		return
+	}
+	if !h.reconciling.CompareAndSwap(false, true) {
+		httpx.JSONError(w, r, http.StatusConflict, "CONFLICT", "Rating reconciliation already in progress", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	go func() {
+		defer h.reconciling.Store(false)
+		defer cancel()
+		fixed, err := h.service.ReconcileAggregates(ctx)
+		if err != nil {
+			log.Printf("rating reconcile job failed: %v", err)
+			return
+		}
+		log.Printf("rating reconcile job: %d books corrected", fixed)
+	}()
+
+	httpx.JSONSuccessAccepted(w, r, map[string]string{"message": "rating reconciliation started"}, nil)
+}
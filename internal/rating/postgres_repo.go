@@ -6,6 +6,8 @@ import (
 	"errors"
 	"time"
 
+	"bookapi/internal/platform/isbn"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -25,15 +27,16 @@ func (r *PostgresRepo) withTimeout(ctx context.Context) (context.Context, contex
 	return context.WithTimeout(ctx, r.timeout)
 }
 
-func (repo *PostgresRepo) CreateOrUpdateRating(ctx context.Context, userID string, isbn string, star int) error {
+func (repo *PostgresRepo) CreateOrUpdateRating(ctx context.Context, userID string, bookISBN string, star int) error {
 	if star < 1 || star > 5 {
 		return errors.New("rating must be between 1 and 5")
 	}
+	bookISBN = isbn.Key(bookISBN)
 	var bookID string
-	findBookSQL := `SELECT id FROM books WHERE isbn = $1 LIMIT 1`
+	findBookSQL := `SELECT id FROM books WHERE id = book_id_for_isbn($1)`
 	timeoutCtx, cancel := repo.withTimeout(ctx)
 	defer cancel()
-	if err := repo.db.QueryRow(timeoutCtx, findBookSQL, isbn).Scan(&bookID); err != nil {
+	if err := repo.db.QueryRow(timeoutCtx, findBookSQL, bookISBN).Scan(&bookID); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrInternalNotFound
 		}
@@ -49,18 +52,18 @@ func (repo *PostgresRepo) CreateOrUpdateRating(ctx context.Context, userID strin
 	return err
 }
 
-func (repo *PostgresRepo) GetUserRating(ctx context.Context, userID, isbn string) (int, error) {
+func (repo *PostgresRepo) GetUserRating(ctx context.Context, userID, bookISBN string) (int, error) {
+	bookISBN = isbn.Key(bookISBN)
 	query := `
 		SELECT r.star
 		FROM ratings r
-		JOIN books b ON b.id = r.book_id
-		WHERE r.user_id = $1 AND b.isbn = $2
-		LIMIT 1
+		WHERE r.user_id = $1
+		  AND r.book_id = book_id_for_isbn($2)
 	`
 	var star int
 	timeoutCtx, cancel := repo.withTimeout(ctx)
 	defer cancel()
-	if err := repo.db.QueryRow(timeoutCtx, query, userID, isbn).Scan(&star); err != nil {
+	if err := repo.db.QueryRow(timeoutCtx, query, userID, bookISBN).Scan(&star); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return 0, ErrInternalNotFound
 		}
@@ -69,18 +72,39 @@ func (repo *PostgresRepo) GetUserRating(ctx context.Context, userID, isbn string
 	return star, nil
 }
 
-func (repo *PostgresRepo) GetBookRating(ctx context.Context, isbn string) (float64, int, error) {
+func (repo *PostgresRepo) GetBookRating(ctx context.Context, bookISBN string) (float64, int, error) {
+	bookISBN = isbn.Key(bookISBN)
+	query := `SELECT rating_avg, rating_count FROM books WHERE id = book_id_for_isbn($1)`
+	var average float64
+	var count int
+	timeoutCtx, cancel := repo.withTimeout(ctx)
+	defer cancel()
+	if err := repo.db.QueryRow(timeoutCtx, query, bookISBN).Scan(&average, &count); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+	return average, count, nil
+}
+
+func (repo *PostgresRepo) GetWorkRating(ctx context.Context, bookISBN string) (float64, int, error) {
+	bookISBN = isbn.Key(bookISBN)
 	query := `
-		SELECT AVG(r.star)::FLOAT, COUNT(r.star)
-		FROM ratings r
-		JOIN books b ON b.id = r.book_id
-		WHERE b.isbn = $1
+		SELECT SUM(e.rating_avg * e.rating_count) / NULLIF(SUM(e.rating_count), 0), SUM(e.rating_count)
+		FROM books b
+		JOIN books e ON e.work_id = b.work_id
+		WHERE b.id = book_id_for_isbn($1)
+		GROUP BY b.work_id
 	`
 	var average sql.NullFloat64
 	var count int
 	timeoutCtx, cancel := repo.withTimeout(ctx)
 	defer cancel()
-	if err := repo.db.QueryRow(timeoutCtx, query, isbn).Scan(&average, &count); err != nil {
+	if err := repo.db.QueryRow(timeoutCtx, query, bookISBN).Scan(&average, &count); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, ErrInternalNotFound
+		}
 		return 0, 0, err
 	}
 	if !average.Valid {
@@ -89,6 +113,97 @@ func (repo *PostgresRepo) GetBookRating(ctx context.Context, isbn string) (float
 	return average.Float64, count, nil
 }
 
+// reconcileBatchSize is how many books ReconcileAggregates locks at once.
+const reconcileBatchSize = 500
+
+// ReconcileAggregates recomputes the rating aggregates kept on books from the
+// ratings table and returns how many books were out of date. It scans every
+// book, so it is bounded by ctx rather than the query timeout. Rating writes
+// go on meanwhile: only the books found out of date are locked, a batch at a
+// time, while they are recounted.
+func (repo *PostgresRepo) ReconcileAggregates(ctx context.Context) (int, error) {
+	rows, err := repo.db.Query(ctx, `
+		SELECT b.id
+		FROM books b
+		LEFT JOIN (
+			SELECT book_id, `+histogramSQL+` AS histogram
+			FROM ratings
+			GROUP BY book_id
+		) r ON r.book_id = b.id
+		WHERE b.rating_histogram IS DISTINCT FROM COALESCE(r.histogram, '{0,0,0,0,0}')
+		ORDER BY b.id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	fixed := 0
+	for start := 0; start < len(ids); start += reconcileBatchSize {
+		n, err := repo.reconcileBooks(ctx, ids[start:min(start+reconcileBatchSize, len(ids))])
+		if err != nil {
+			return fixed, err
+		}
+		fixed += n
+	}
+	return fixed, nil
+}
+
+// histogramSQL counts the 1..5 star ratings of a group of ratings.
+const histogramSQL = `ARRAY[
+	COUNT(star) FILTER (WHERE star = 1),
+	COUNT(star) FILTER (WHERE star = 2),
+	COUNT(star) FILTER (WHERE star = 3),
+	COUNT(star) FILTER (WHERE star = 4),
+	COUNT(star) FILTER (WHERE star = 5)
+]::int[]`
+
+// reconcileBooks recounts the histograms of the given books. Their rows are
+// locked first, in id order; the rating trigger takes the same row lock, so
+// the recount, run in a snapshot taken after the locks are granted, sees
+// every rating committed before it and later writes wait for it.
+func (repo *PostgresRepo) reconcileBooks(ctx context.Context, ids []string) (int, error) {
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT id FROM books WHERE id = ANY($1::uuid[]) ORDER BY id FOR UPDATE`, ids); err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE books b SET rating_histogram = s.histogram
+		FROM (
+			SELECT b.id, `+histogramSQL+` AS histogram
+			FROM books b
+			LEFT JOIN ratings r ON r.book_id = b.id
+			WHERE b.id = ANY($1::uuid[])
+			GROUP BY b.id
+		) s
+		WHERE s.id = b.id AND b.rating_histogram IS DISTINCT FROM s.histogram
+	`, ids)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
 func (repo *PostgresRepo) GetUserRatingStats(ctx context.Context, userID string) (float64, int, error) {
 	query := `
 		SELECT AVG(star)::FLOAT, COUNT(star)
@@ -12,7 +12,9 @@ type Repository interface {
 	CreateOrUpdateRating(ctx context.Context, userID string, isbn string, star int) error
 	GetUserRating(ctx context.Context, userID string, isbn string) (int, error)
 	GetBookRating(ctx context.Context, isbn string) (average float64, count int, err error)
+	GetWorkRating(ctx context.Context, isbn string) (average float64, count int, err error)
 	GetUserRatingStats(ctx context.Context, userID string) (average float64, count int, err error)
+	ReconcileAggregates(ctx context.Context) (fixed int, err error)
 }
 
 type Service struct {
@@ -35,6 +37,18 @@ func (s *Service) GetBookRating(ctx context.Context, isbn string) (float64, int,
 	return s.repo.GetBookRating(ctx, isbn)
 }
 
+// GetWorkRating returns the rating across every edition of the book's work.
+// It returns ErrInternalNotFound when the book is not grouped under a work.
+func (s *Service) GetWorkRating(ctx context.Context, isbn string) (float64, int, error) {
+	return s.repo.GetWorkRating(ctx, isbn)
+}
+
 func (s *Service) GetUserRatingStats(ctx context.Context, userID string) (float64, int, error) {
 	return s.repo.GetUserRatingStats(ctx, userID)
 }
+
+// ReconcileAggregates repairs the rating aggregates kept on books, which can
+// only drift if ratings are written around the database trigger.
+func (s *Service) ReconcileAggregates(ctx context.Context) (int, error) {
+	return s.repo.ReconcileAggregates(ctx)
+}
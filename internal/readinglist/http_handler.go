@@ -2,6 +2,7 @@ package readinglist
 
 import (
 	"bookapi/internal/httpx"
+	"bookapi/internal/platform/isbn"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -49,7 +50,13 @@ func (h *HTTPHandler) AddOrUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Upsert(r.Context(), userID, req.ISBN, req.Status); err != nil {
+	if validationErrors := httpx.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", validationErrors)
+		return
+	}
+	bookISBN := isbn.Key(req.ISBN)
+
+	if err := h.service.Upsert(r.Context(), userID, bookISBN, req.Status); err != nil {
 		if errors.Is(err, ErrNotFound) {
 			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Book not found", nil)
 			return
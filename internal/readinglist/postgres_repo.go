@@ -6,6 +6,8 @@ import (
 	"errors"
 	"time"
 
+	"bookapi/internal/platform/isbn"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -24,18 +26,19 @@ func (r *PostgresRepo) withTimeout(ctx context.Context) (context.Context, contex
 	return context.WithTimeout(ctx, r.timeout)
 }
 
-func (r *PostgresRepo) UpsertReadingListItem(ctx context.Context, userID string, isbn string, status string) error {
+func (r *PostgresRepo) UpsertReadingListItem(ctx context.Context, userID string, bookISBN string, status string) error {
+	bookISBN = isbn.Key(bookISBN)
 	const upsertSQL = `
 		INSERT INTO user_books (user_id, book_id, status, created_at, updated_at)
 		SELECT $1, b.id, $3, NOW(), NOW()
 		FROM books b
-		WHERE b.isbn = $2
+		WHERE b.id = book_id_for_isbn($2)
 		ON CONFLICT (user_id, book_id)
 		DO UPDATE SET status = EXCLUDED.status, updated_at = NOW()
 	`
 	timeoutCtx, cancel := r.withTimeout(ctx)
 	defer cancel()
-	commandTag, err := r.db.Exec(timeoutCtx, upsertSQL, userID, isbn, status)
+	commandTag, err := r.db.Exec(timeoutCtx, upsertSQL, userID, bookISBN, status)
 	if err != nil {
 		return err
 	}
@@ -61,7 +64,8 @@ func (r *PostgresRepo) ListReadingListByStatus(ctx context.Context, userID strin
 
 	const dataSQL = `
 		SELECT b.id, b.isbn, b.title, b.subtitle, b.genre, b.publisher, COALESCE(b.description, '') as description, 
-		       b.published_date, b.publication_year, b.page_count, b.language, b.cover_url, b.created_at, b.updated_at
+		       b.published_date, b.publication_year, b.page_count, b.language, b.cover_url, b.work_id,
+		       b.rating_avg, b.rating_count, b.rating_histogram, b.created_at, b.updated_at
 		FROM user_books ub
 		JOIN books b ON b.id = ub.book_id
 		WHERE ub.user_id = $1 AND ub.status = $2
@@ -82,6 +86,7 @@ func (r *PostgresRepo) ListReadingListByStatus(ctx context.Context, userID strin
 		if err := rows.Scan(
 			&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
 			&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
+			&b.WorkID, &b.Rating.Average, &b.Rating.Count, &b.Rating.Histogram,
 			&b.CreatedAt, &b.UpdatedAt,
 		); err != nil {
 			return nil, 0, err
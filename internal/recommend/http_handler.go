@@ -0,0 +1,59 @@
+package recommend
+
+import (
+	"bookapi/internal/httpx"
+	"net/http"
+	"strconv"
+)
+
+type HTTPHandler struct {
+	service *Service
+}
+
+func NewHTTPHandler(service *Service) *HTTPHandler {
+	return &HTTPHandler{service: service}
+}
+
+// Recommendations handles GET /me/recommendations
+// @Summary Personalized recommendations
+// @Description Books recommended to the authenticated user. Users with enough ratings get books that readers of the books they rated or listed also liked; others get well rated books in the favourite genres and languages of their reading preferences. Books already on the user's lists or rated by them are left out. The strategy used is returned in meta.
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param limit query int false "Number of books (max 50)" default(20)
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /me/recommendations [get]
+func (h *HTTPHandler) Recommendations(w http.ResponseWriter, r *http.Request) {
+	userID := httpx.UserIDFrom(r)
+	if userID == "" {
+		httpx.JSONError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Unauthorized", nil)
+		return
+	}
+
+	limit := DefaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > MaxLimit {
+			httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", []httpx.ErrorDetail{
+				{Field: "limit", Message: "must be between 1 and 50"},
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	res, err := h.service.For(r.Context(), userID, limit)
+	if err != nil {
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, res.Books, map[string]any{
+		"strategy": res.Strategy,
+		"limit":    limit,
+	})
+}
@@ -0,0 +1,78 @@
+package recommend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHandler_Recommendations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	handler := NewHTTPHandler(NewService(NewMockRepository(ctrl), DefaultConfig()))
+
+	t.Run("unauthorized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/me/recommendations", nil)
+
+		handler.Recommendations(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestJobHandler_RebuildSimilarities(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	handler := NewJobHandler(NewService(mockRepo, DefaultConfig()), "secret")
+
+	request := func(secret string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/internal/jobs/recommendations/similarities", nil)
+		if secret != "" {
+			r.Header.Set("X-Internal-Secret", secret)
+		}
+		return r
+	}
+
+	t.Run("invalid secret", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		handler.RebuildSimilarities(w, request("wrong"))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("unset secret fails closed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		NewJobHandler(NewService(mockRepo, DefaultConfig()), "").RebuildSimilarities(w, request(""))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("starts a rebuild under the lock", func(t *testing.T) {
+		unlocked := make(chan struct{})
+		mockRepo.EXPECT().TryLockSimilarities(gomock.Any()).Return(func() { close(unlocked) }, true, nil)
+		mockRepo.EXPECT().RebuildSimilarities(gomock.Any(), DefaultConfig()).Return(12, nil)
+		w := httptest.NewRecorder()
+
+		handler.RebuildSimilarities(w, request("secret"))
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		<-unlocked
+	})
+
+	t.Run("rebuild in progress", func(t *testing.T) {
+		mockRepo.EXPECT().TryLockSimilarities(gomock.Any()).Return(nil, false, nil)
+		w := httptest.NewRecorder()
+
+		handler.RebuildSimilarities(w, request("secret"))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "CONFLICT")
+	})
+}
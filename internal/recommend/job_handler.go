@@ -0,0 +1,47 @@
+package recommend
+
+import (
+	"errors"
+	"net/http"
+
+	"bookapi/internal/httpx"
+)
+
+// JobHandler serves the internal recommendation maintenance jobs.
+type JobHandler struct {
+	service *Service
+	secret  string
+}
+
+func NewJobHandler(service *Service, secret string) *JobHandler {
+	return &JobHandler{service: service, secret: secret}
+}
+
+// RebuildSimilarities handles POST /internal/jobs/recommendations/similarities
+// @Summary Rebuild book similarities
+// @Description Recompute the book-to-book similarities personalized recommendations are served from
+// @Tags internal
+// @Accept json
+// @Produce json
+// @Param X-Internal-Secret header string true "Internal secret for authentication"
+// @Success 202 {object} httpx.SuccessResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 409 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /internal/jobs/recommendations/similarities [post]
+func (h *JobHandler) RebuildSimilarities(w http.ResponseWriter, r *http.Request) {
+	if !httpx.AuthorizeInternal(w, r, h.secret) {
+		return
+	}
+
+	if err := h.service.StartRebuild(r.Context()); err != nil {
+		if errors.Is(err, ErrRebuildInProgress) {
+			httpx.JSONError(w, r, http.StatusConflict, "CONFLICT", "Similarity rebuild already in progress", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccessAccepted(w, r, map[string]string{"message": "similarity rebuild started"}, nil)
+}
@@ -0,0 +1,271 @@
+package recommend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// popularPriorWeight is the number of phantom ratings at the mean rating
+// each book starts with when ranking popular books, as for top rated books.
+const popularPriorWeight = 10
+
+type PostgresRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+}
+
+func NewPostgresRepo(db *pgxpool.Pool, timeout time.Duration) *PostgresRepo {
+	return &PostgresRepo{db: db, timeout: timeout}
+}
+
+func (r *PostgresRepo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+// bookColumnsSQL selects the book columns scanned by queryRecommendations;
+// the score and reason are appended by the caller.
+const bookColumnsSQL = `
+		SELECT b.id, b.isbn, b.title, b.subtitle, b.genre, b.publisher, b.description,
+		       b.published_date, b.publication_year, b.page_count, b.language, b.cover_url,
+		       b.work_id, bauth.authors, b.rating_avg, b.rating_count, b.rating_histogram,
+		       b.created_at, b.updated_at`
+
+const authorsJoinSQL = `LEFT JOIN LATERAL (
+			SELECT json_agg(json_build_object('key', ba.author_key, 'name', ba.name, 'role', ba.role)
+			                ORDER BY ba.position, ba.role) AS authors
+			FROM book_authors ba
+			WHERE ba.book_id = b.id
+		) bauth ON true`
+
+// Preferences reads the user's reading preferences. Unknown users and
+// missing or unreadable preferences yield none.
+func (r *PostgresRepo) Preferences(ctx context.Context, userID string) (Preferences, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var raw []byte
+	err := r.db.QueryRow(timeoutCtx, `SELECT reading_preferences FROM users WHERE id = $1`, userID).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Preferences{}, nil
+		}
+		return Preferences{}, err
+	}
+
+	var p Preferences
+	if len(raw) > 0 && json.Unmarshal(raw, &p) != nil {
+		return Preferences{}, nil
+	}
+	return p, nil
+}
+
+func (r *PostgresRepo) CountRatings(ctx context.Context, userID string) (int, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var n int
+	err := r.db.QueryRow(timeoutCtx, `SELECT COUNT(*) FROM ratings WHERE user_id = $1`, userID).Scan(&n)
+	return n, err
+}
+
+// Collaborative scores every book similar to one the user rated or listed
+// by the sum of its similarities, each weighted by how much the user liked
+// the book it is similar to. Ratings are centred on 3 stars, so books like
+// those the user disliked are pushed down; a listed book counts as a 4.
+func (r *PostgresRepo) Collaborative(ctx context.Context, userID string, limit int) ([]Recommendation, error) {
+	query := `
+		WITH mine AS (
+			SELECT COALESCE(rt.book_id, ub.book_id) AS book_id,
+			       COALESCE(rt.star - 3.0, 1.0)::float8 AS weight,
+			       rt.book_id IS NOT NULL AS rated
+			FROM (SELECT book_id, star FROM ratings WHERE user_id = $1) rt
+			FULL JOIN (SELECT book_id FROM user_books WHERE user_id = $1) ub ON ub.book_id = rt.book_id
+		), scores AS (
+			SELECT s.similar_book_id AS book_id, SUM(s.score * m.weight) AS score,
+			       (array_agg(m.book_id ORDER BY s.score * m.weight DESC))[1] AS because_id
+			FROM mine m
+			JOIN book_similarities s ON s.book_id = m.book_id
+			WHERE s.similar_book_id NOT IN (SELECT book_id FROM mine)
+			GROUP BY s.similar_book_id
+			HAVING SUM(s.score * m.weight) > 0
+		)` + bookColumnsSQL + `, sc.score,
+		       CASE WHEN bm.rated THEN 'Because you liked ' || bb.title
+		            ELSE 'Because ' || bb.title || ' is on your list' END
+		FROM scores sc
+		JOIN books b ON b.id = sc.book_id
+		JOIN books bb ON bb.id = sc.because_id
+		JOIN mine bm ON bm.book_id = sc.because_id
+		` + authorsJoinSQL + `
+		ORDER BY sc.score DESC, b.id
+		LIMIT $2`
+
+	return r.queryRecommendations(ctx, query, userID, limit)
+}
+
+// Popular ranks the books in the preferred genres (and their descendants)
+// and languages by Bayesian average rating. Without preferences it ranks
+// every book.
+func (r *PostgresRepo) Popular(ctx context.Context, userID string, prefs Preferences, limit int) ([]Recommendation, error) {
+	query := bookColumnsSQL + `,
+		       ($4::float8 * m.mean + b.rating_count * b.rating_avg) / ($4::float8 + b.rating_count) AS score,
+		       CASE
+		           WHEN pg.name IS NOT NULL THEN 'Popular in ' || pg.name
+		           WHEN cardinality($3::text[]) > 0 THEN 'Popular in your languages'
+		           ELSE 'Popular with readers'
+		       END
+		FROM books b
+		CROSS JOIN (
+			SELECT COALESCE(SUM(rating_avg * rating_count) / NULLIF(SUM(rating_count), 0), 0) AS mean
+			FROM books
+		) m
+		LEFT JOIN LATERAL (
+			SELECT gn.name
+			FROM book_genres bg
+			JOIN genre_closure gc ON gc.descendant = bg.genre_slug
+			JOIN genres gn ON gn.slug = gc.ancestor
+			WHERE bg.book_id = b.id AND gc.ancestor = ANY($2::text[])
+			ORDER BY array_position($2::text[], gc.ancestor::text)
+			LIMIT 1
+		) pg ON true
+		` + authorsJoinSQL + `
+		WHERE (cardinality($2::text[]) = 0 OR pg.name IS NOT NULL)
+		  AND (cardinality($3::text[]) = 0 OR b.language = ANY($3::text[]))
+		  AND NOT EXISTS (SELECT 1 FROM user_books ub WHERE ub.user_id = $1 AND ub.book_id = b.id)
+		  AND NOT EXISTS (SELECT 1 FROM ratings rt WHERE rt.user_id = $1 AND rt.book_id = b.id)
+		ORDER BY score DESC, b.rating_count DESC, b.id
+		LIMIT $5`
+
+	genres, languages := prefs.Genres, prefs.Languages
+	if genres == nil {
+		genres = []string{}
+	}
+	if languages == nil {
+		languages = []string{}
+	}
+	return r.queryRecommendations(ctx, query, userID, genres, languages, float64(popularPriorWeight), limit)
+}
+
+func (r *PostgresRepo) queryRecommendations(ctx context.Context, query string, args ...any) ([]Recommendation, error) {
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Recommendation{}
+	for rows.Next() {
+		var rec Recommendation
+		b := &rec.Book
+		if err := rows.Scan(
+			&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
+			&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
+			&b.WorkID, &b.Authors, &b.Rating.Average, &b.Rating.Count, &b.Rating.Histogram,
+			&b.CreatedAt, &b.UpdatedAt, &rec.Score, &rec.Reason,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// similaritiesLockName identifies the advisory lock held while the
+// similarities are rebuilt.
+const similaritiesLockName = "bookapi.recommend.similarities"
+
+// TryLockSimilarities takes a session-level advisory lock on a connection
+// set aside from the pool, like the ingest lock, so that a rebuild in any
+// instance keeps the others from starting one.
+func (r *PostgresRepo) TryLockSimilarities(ctx context.Context) (func(), bool, error) {
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, similaritiesLockName).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, false, err
+	}
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		defer cancel()
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, similaritiesLockName); err != nil {
+			// Closing the connection ends the session, releasing the lock.
+			log.Printf("Failed to release similarity rebuild lock: %v", err)
+			_ = conn.Hijack().Close(ctx)
+			return
+		}
+		conn.Release()
+	}
+	return unlock, true, nil
+}
+
+// RebuildSimilarities replaces book_similarities with the cosine
+// similarity of every pair of books sharing at least MinCommonReaders
+// readers, keeping the Neighbours most similar books of each. A reader's
+// value for a book is their rating centred on 3 stars, or 1 for a book they
+// only listed. Readers keep being served the old similarities until the
+// rebuild commits. Like ReconcileAggregates it runs without the repository
+// timeout. Callers hold the TryLockSimilarities lock.
+func (r *PostgresRepo) RebuildSimilarities(ctx context.Context, cfg Config) (int, error) {
+	const query = `
+		WITH interactions AS (
+			SELECT COALESCE(rt.user_id, ub.user_id) AS user_id,
+			       COALESCE(rt.book_id, ub.book_id) AS book_id,
+			       COALESCE(rt.star - 3.0, 1.0)::float8 AS weight
+			FROM ratings rt
+			FULL JOIN user_books ub ON ub.user_id = rt.user_id AND ub.book_id = rt.book_id
+		), norms AS (
+			SELECT book_id, sqrt(SUM(weight * weight)) AS norm
+			FROM interactions
+			GROUP BY book_id
+			HAVING SUM(weight * weight) > 0
+		), pairs AS (
+			SELECT a.book_id, b.book_id AS similar_book_id, SUM(a.weight * b.weight) AS dot
+			FROM interactions a
+			JOIN interactions b ON b.user_id = a.user_id AND b.book_id <> a.book_id
+			GROUP BY a.book_id, b.book_id
+			HAVING COUNT(*) >= $1
+		), ranked AS (
+			SELECT p.book_id, p.similar_book_id, p.dot / (na.norm * nb.norm) AS score,
+			       row_number() OVER (PARTITION BY p.book_id ORDER BY p.dot / (na.norm * nb.norm) DESC, p.similar_book_id) AS rn
+			FROM pairs p
+			JOIN norms na ON na.book_id = p.book_id
+			JOIN norms nb ON nb.book_id = p.similar_book_id
+			WHERE p.dot > 0
+		)
+		INSERT INTO book_similarities (book_id, similar_book_id, score)
+		SELECT book_id, similar_book_id, score FROM ranked WHERE rn <= $2
+	`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM book_similarities`); err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, query, cfg.MinCommonReaders, cfg.Neighbours)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,82 @@
+package recommend
+
+import (
+	"context"
+
+	"bookapi/internal/book"
+)
+
+const (
+	// DefaultLimit is the number of recommendations returned when no limit
+	// is given.
+	DefaultLimit = 20
+	// MaxLimit caps the recommendations list.
+	MaxLimit = 50
+)
+
+// Strategies a recommendation list can be built with.
+const (
+	// StrategyCollaborative recommends books similar, by who reads them,
+	// to the books the user rated or listed.
+	StrategyCollaborative = "collaborative"
+	// StrategyPreferences recommends well rated books in the user's
+	// favourite genres and languages.
+	StrategyPreferences = "preferences"
+)
+
+// Config tunes personalized recommendations.
+type Config struct {
+	// MinRatings is the number of ratings a user needs before
+	// recommendations switch from preferences to collaborative filtering.
+	MinRatings int
+	// Neighbours is the number of similar books kept per book by the
+	// similarity job.
+	Neighbours int
+	// MinCommonReaders is the number of readers two books must share before
+	// their similarity is kept.
+	MinCommonReaders int
+}
+
+// DefaultConfig returns the recommendation settings used when none are
+// configured.
+func DefaultConfig() Config {
+	return Config{
+		MinRatings:       5,
+		Neighbours:       50,
+		MinCommonReaders: 2,
+	}
+}
+
+// Preferences are the parts of users.reading_preferences, as described by
+// user.ReadingPreferences, that recommendations use. Genres are genre slugs
+// or names; Languages are language codes.
+type Preferences struct {
+	Genres    []string `json:"favorite_genres"`
+	Languages []string `json:"languages"`
+}
+
+// Recommendation is a recommended book with its score and the reason it
+// was picked.
+type Recommendation struct {
+	book.Book
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// Result is a recommendation list and the strategy that produced it.
+type Result struct {
+	Books    []Recommendation
+	Strategy string
+}
+
+type Repository interface {
+	Preferences(ctx context.Context, userID string) (Preferences, error)
+	CountRatings(ctx context.Context, userID string) (int, error)
+	Collaborative(ctx context.Context, userID string, limit int) ([]Recommendation, error)
+	Popular(ctx context.Context, userID string, prefs Preferences, limit int) ([]Recommendation, error)
+	RebuildSimilarities(ctx context.Context, cfg Config) (int, error)
+	// TryLockSimilarities takes the similarity rebuild lock without waiting.
+	// When it is acquired, unlock releases it; when another holder has it,
+	// acquired is false.
+	TryLockSimilarities(ctx context.Context) (unlock func(), acquired bool, err error)
+}
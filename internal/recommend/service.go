@@ -0,0 +1,185 @@
+package recommend
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"bookapi/internal/book"
+	"bookapi/internal/platform/cron"
+	"bookapi/internal/platform/textsearch"
+)
+
+// ErrRebuildInProgress is returned when a similarity rebuild is started
+// while another one, in this or another instance, holds the lock.
+var ErrRebuildInProgress = errors.New("similarity rebuild already in progress")
+
+// RebuildTimeout bounds a similarity rebuild.
+const RebuildTimeout = 30 * time.Minute
+
+type Service struct {
+	repo Repository
+	cfg  Config
+}
+
+func NewService(repo Repository, cfg Config) *Service {
+	return &Service{repo: repo, cfg: cfg}
+}
+
+// For recommends books to a user. Users with at least MinRatings ratings
+// get books similar to those they rated or listed; everyone else, and any
+// shortfall, is served well rated books in their favourite genres and
+// languages. Books on the user's lists or rated by them are never returned.
+func (s *Service) For(ctx context.Context, userID string, limit int) (Result, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	res := Result{Books: []Recommendation{}, Strategy: StrategyPreferences}
+
+	rated, err := s.repo.CountRatings(ctx, userID)
+	if err != nil {
+		return Result{}, err
+	}
+	if rated >= s.cfg.MinRatings {
+		recs, err := s.repo.Collaborative(ctx, userID, limit)
+		if err != nil {
+			return Result{}, err
+		}
+		if len(recs) > 0 {
+			res.Books, res.Strategy = recs, StrategyCollaborative
+		}
+	}
+	if len(res.Books) >= limit {
+		return res, nil
+	}
+
+	prefs, err := s.repo.Preferences(ctx, userID)
+	if err != nil {
+		return Result{}, err
+	}
+	popular, err := s.repo.Popular(ctx, userID, normalizePreferences(prefs), limit)
+	if err != nil {
+		return Result{}, err
+	}
+	res.Books = fill(res.Books, popular, limit)
+	return res, nil
+}
+
+// RebuildSimilarities recomputes the book-to-book similarities
+// collaborative recommendations are served from. It returns the number of
+// pairs stored, or ErrRebuildInProgress if a rebuild is already running.
+func (s *Service) RebuildSimilarities(ctx context.Context) (int, error) {
+	unlock, acquired, err := s.repo.TryLockSimilarities(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !acquired {
+		return 0, ErrRebuildInProgress
+	}
+	defer unlock()
+	return s.repo.RebuildSimilarities(ctx, s.cfg)
+}
+
+// StartRebuild takes the rebuild lock and rebuilds the similarities in the
+// background, bounded by RebuildTimeout. It returns ErrRebuildInProgress
+// without starting one if a rebuild is already running.
+func (s *Service) StartRebuild(ctx context.Context) error {
+	unlock, acquired, err := s.repo.TryLockSimilarities(ctx)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return ErrRebuildInProgress
+	}
+	go func() {
+		defer unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), RebuildTimeout)
+		defer cancel()
+		logRebuild(s.repo.RebuildSimilarities(ctx, s.cfg))
+	}()
+	return nil
+}
+
+// ScheduleRebuilds rebuilds the similarities each time schedule fires, in
+// UTC, until ctx is cancelled. Every instance runs the schedule; the rebuild
+// lock lets only one of them rebuild at a time.
+func (s *Service) ScheduleRebuilds(ctx context.Context, schedule cron.Schedule) {
+	for {
+		next := schedule.Next(time.Now().UTC())
+		if next.IsZero() {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		rebuildCtx, cancel := context.WithTimeout(ctx, RebuildTimeout)
+		pairs, err := s.RebuildSimilarities(rebuildCtx)
+		cancel()
+		if errors.Is(err, ErrRebuildInProgress) {
+			log.Printf("similarity rebuild skipped: %v", err)
+			continue
+		}
+		logRebuild(pairs, err)
+	}
+}
+
+func logRebuild(pairs int, err error) {
+	if err != nil {
+		log.Printf("similarity rebuild job failed: %v", err)
+		return
+	}
+	log.Printf("similarity rebuild job: %d book pairs stored", pairs)
+}
+
+// normalizePreferences turns genre names into slugs and language tags into
+// the ISO 639-1 codes books are stored with, dropping blanks and repeats.
+func normalizePreferences(p Preferences) Preferences {
+	return Preferences{
+		Genres:    normalized(p.Genres, book.GenreSlug),
+		Languages: normalized(p.Languages, textsearch.LanguageCode),
+	}
+}
+
+func normalized(values []string, norm func(string) string) []string {
+	out := []string{}
+	seen := make(map[string]bool)
+	for _, v := range values {
+		v = norm(strings.TrimSpace(v))
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// fill appends the extra recommendations not already in recs until limit
+// is reached.
+func fill(recs, extra []Recommendation, limit int) []Recommendation {
+	seen := make(map[string]bool, len(recs))
+	for _, r := range recs {
+		seen[r.ID] = true
+	}
+	for _, r := range extra {
+		if len(recs) >= limit {
+			break
+		}
+		if !seen[r.ID] {
+			seen[r.ID] = true
+			recs = append(recs, r)
+		}
+	}
+	return recs
+}
@@ -0,0 +1,126 @@
+package recommend
+
+import (
+	"context"
+	"testing"
+
+	"bookapi/internal/book"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rec(id, reason string) Recommendation {
+	return Recommendation{Book: book.Book{ID: id}, Reason: reason}
+}
+
+func TestService_For(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cold start uses normalized preferences", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := NewMockRepository(ctrl)
+		service := NewService(repo, DefaultConfig())
+
+		repo.EXPECT().CountRatings(ctx, "u1").Return(2, nil)
+		repo.EXPECT().Preferences(ctx, "u1").Return(Preferences{
+			Genres:    []string{"Science Fiction", "science-fiction", " "},
+			Languages: []string{"en-US", "ger"},
+		}, nil)
+		repo.EXPECT().Popular(ctx, "u1", Preferences{
+			Genres:    []string{"science-fiction"},
+			Languages: []string{"en", "de"},
+		}, 10).Return([]Recommendation{rec("1", "Popular in Science Fiction")}, nil)
+
+		res, err := service.For(ctx, "u1", 10)
+		require.NoError(t, err)
+		assert.Equal(t, StrategyPreferences, res.Strategy)
+		assert.Len(t, res.Books, 1)
+	})
+
+	t.Run("enough ratings switch to collaborative", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := NewMockRepository(ctrl)
+		service := NewService(repo, DefaultConfig())
+
+		repo.EXPECT().CountRatings(ctx, "u1").Return(5, nil)
+		repo.EXPECT().Collaborative(ctx, "u1", 2).
+			Return([]Recommendation{rec("1", "Because you liked Dune"), rec("2", "Because you liked Dune")}, nil)
+
+		res, err := service.For(ctx, "u1", 2)
+		require.NoError(t, err)
+		assert.Equal(t, StrategyCollaborative, res.Strategy)
+		assert.Len(t, res.Books, 2)
+	})
+
+	t.Run("shortfall is filled from preferences", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := NewMockRepository(ctrl)
+		service := NewService(repo, DefaultConfig())
+
+		repo.EXPECT().CountRatings(ctx, "u1").Return(8, nil)
+		repo.EXPECT().Collaborative(ctx, "u1", 3).Return([]Recommendation{rec("1", "Because you liked Dune")}, nil)
+		repo.EXPECT().Preferences(ctx, "u1").Return(Preferences{}, nil)
+		repo.EXPECT().Popular(ctx, "u1", Preferences{Genres: []string{}, Languages: []string{}}, 3).
+			Return([]Recommendation{rec("1", "Popular with readers"), rec("2", "Popular with readers"), rec("3", "Popular with readers")}, nil)
+
+		res, err := service.For(ctx, "u1", 3)
+		require.NoError(t, err)
+		assert.Equal(t, StrategyCollaborative, res.Strategy)
+		require.Len(t, res.Books, 3)
+		assert.Equal(t, "Because you liked Dune", res.Books[0].Reason)
+		assert.Equal(t, "2", res.Books[1].ID)
+		assert.Equal(t, "3", res.Books[2].ID)
+	})
+
+	t.Run("limit is capped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := NewMockRepository(ctrl)
+		service := NewService(repo, DefaultConfig())
+
+		repo.EXPECT().CountRatings(ctx, "u1").Return(0, nil)
+		repo.EXPECT().Preferences(ctx, "u1").Return(Preferences{}, nil)
+		repo.EXPECT().Popular(ctx, "u1", gomock.Any(), MaxLimit).Return([]Recommendation{}, nil)
+
+		res, err := service.For(ctx, "u1", 500)
+		require.NoError(t, err)
+		assert.Empty(t, res.Books)
+	})
+}
+
+func TestService_RebuildSimilarities(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rebuilds under the lock", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := NewMockRepository(ctrl)
+		service := NewService(repo, DefaultConfig())
+
+		unlocked := false
+		lock := repo.EXPECT().TryLockSimilarities(ctx).Return(func() { unlocked = true }, true, nil)
+		repo.EXPECT().RebuildSimilarities(ctx, DefaultConfig()).Return(7, nil).After(lock)
+
+		pairs, err := service.RebuildSimilarities(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 7, pairs)
+		assert.True(t, unlocked)
+	})
+
+	t.Run("lock held elsewhere", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := NewMockRepository(ctrl)
+		service := NewService(repo, DefaultConfig())
+
+		repo.EXPECT().TryLockSimilarities(ctx).Return(nil, false, nil)
+
+		_, err := service.RebuildSimilarities(ctx)
+		assert.ErrorIs(t, err, ErrRebuildInProgress)
+	})
+}
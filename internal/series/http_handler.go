@@ -0,0 +1,76 @@
+package series
+
+import (
+	"bookapi/internal/httpx"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type HTTPHandler struct {
+	service *Service
+}
+
+func NewHTTPHandler(service *Service) *HTTPHandler {
+	return &HTTPHandler{service: service}
+}
+
+// GetByID handles GET /series/{id}
+// @Summary Get series
+// @Description Retrieve a series with its books in reading order
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path string true "Series ID"
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /series/{id} [get]
+func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid series ID", nil)
+		return
+	}
+
+	s, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Series not found", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, s, nil)
+}
+
+// NextForMe handles GET /me/series/next
+// @Summary Next in series
+// @Description For each series the current user has finished a book in, the next book to read
+// @Tags series
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /me/series/next [get]
+func (h *HTTPHandler) NextForMe(w http.ResponseWriter, r *http.Request) {
+	userID := httpx.UserIDFrom(r)
+	if userID == "" {
+		httpx.JSONError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Unauthorized", nil)
+		return
+	}
+
+	next, err := h.service.NextForUser(r.Context(), userID)
+	if err != nil {
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, next, nil)
+}
@@ -0,0 +1,77 @@
+package series
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bookapi/internal/book"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHandler_GetByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo)
+	handler := NewHTTPHandler(service)
+
+	const id = "5b1e8d3a-7c2f-4e9b-a1d0-3f6c2b8e9a41"
+	one, half := 1.0, 1.5
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), id).Return(Series{ID: id, Name: "Dune Chronicles"}, nil)
+		mockRepo.EXPECT().ListEntries(gomock.Any(), id).Return([]Entry{
+			{Position: &one, Book: book.Book{ISBN: "9780441172719", Title: "Dune"}},
+			{Position: &half, Book: book.Book{ISBN: "9780306406157", Title: "Interlude"}},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/series/"+id, nil)
+		r.SetPathValue("id", id)
+
+		handler.GetByID(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"position":1.5`)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), id).Return(Series{}, ErrNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/series/"+id, nil)
+		r.SetPathValue("id", id)
+
+		handler.GetByID(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/series/dune", nil)
+		r.SetPathValue("id", "dune")
+
+		handler.GetByID(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHTTPHandler_NextForMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	handler := NewHTTPHandler(NewService(NewMockRepository(ctrl)))
+
+	t.Run("unauthorized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/me/series/next", nil)
+
+		handler.NextForMe(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
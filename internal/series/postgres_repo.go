@@ -0,0 +1,138 @@
+package series
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+}
+
+func NewPostgresRepo(db *pgxpool.Pool, timeout time.Duration) *PostgresRepo {
+	return &PostgresRepo{db: db, timeout: timeout}
+}
+
+func (r *PostgresRepo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+func (r *PostgresRepo) GetByID(ctx context.Context, id string) (Series, error) {
+	const query = `
+		SELECT id, name, COALESCE(description, ''), created_at, updated_at
+		FROM series
+		WHERE id = $1
+	`
+	var s Series
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	err := r.db.QueryRow(timeoutCtx, query, id).Scan(&s.ID, &s.Name, &s.Description, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Series{}, ErrNotFound
+		}
+		return Series{}, err
+	}
+	return s, nil
+}
+
+func (r *PostgresRepo) ListEntries(ctx context.Context, seriesID string) ([]Entry, error) {
+	const query = `
+		SELECT bs.position::float8,
+		       b.id, b.isbn, b.title, b.subtitle, b.genre, b.publisher, b.description,
+		       b.published_date, b.publication_year, b.page_count, b.language, b.cover_url,
+		       b.work_id, b.rating_avg, b.rating_count, b.rating_histogram, b.created_at, b.updated_at
+		FROM book_series bs
+		JOIN books b ON b.id = bs.book_id
+		WHERE bs.series_id = $1
+		ORDER BY bs.position NULLS LAST, b.publication_year NULLS LAST, b.title
+	`
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, query, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var e Entry
+		b := &e.Book
+		if err := rows.Scan(
+			&e.Position,
+			&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
+			&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
+			&b.WorkID, &b.Rating.Average, &b.Rating.Count, &b.Rating.Histogram,
+			&b.CreatedAt, &b.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// NextForUser finds, per series, the lowest-positioned book above the
+// highest position the user has FINISHED. Books the user has already
+// finished are skipped, so reading out of order does not resurface them.
+func (r *PostgresRepo) NextForUser(ctx context.Context, userID string) ([]Next, error) {
+	const query = `
+		WITH finished AS (
+			SELECT bs.series_id, MAX(bs.position) AS position
+			FROM user_books ub
+			JOIN book_series bs ON bs.book_id = ub.book_id
+			WHERE ub.user_id = $1 AND ub.status = 'FINISHED' AND bs.position IS NOT NULL
+			GROUP BY bs.series_id
+		)
+		SELECT s.id, s.name, nxt.position::float8,
+		       b.id, b.isbn, b.title, b.subtitle, b.genre, b.publisher, b.description,
+		       b.published_date, b.publication_year, b.page_count, b.language, b.cover_url,
+		       b.work_id, b.rating_avg, b.rating_count, b.rating_histogram, b.created_at, b.updated_at
+		FROM finished f
+		JOIN series s ON s.id = f.series_id
+		JOIN LATERAL (
+			SELECT bs.book_id, bs.position
+			FROM book_series bs
+			WHERE bs.series_id = f.series_id
+			  AND bs.position > f.position
+			  AND NOT EXISTS (
+				SELECT 1 FROM user_books done
+				WHERE done.user_id = $1 AND done.book_id = bs.book_id AND done.status = 'FINISHED'
+			  )
+			ORDER BY bs.position
+			LIMIT 1
+		) nxt ON true
+		JOIN books b ON b.id = nxt.book_id
+		ORDER BY s.name
+	`
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Next{}
+	for rows.Next() {
+		var n Next
+		b := &n.Book
+		if err := rows.Scan(
+			&n.SeriesID, &n.SeriesName, &n.Position,
+			&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
+			&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
+			&b.WorkID, &b.Rating.Average, &b.Rating.Count, &b.Rating.Histogram,
+			&b.CreatedAt, &b.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
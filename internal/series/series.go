@@ -0,0 +1,44 @@
+package series
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bookapi/internal/book"
+)
+
+// ErrNotFound is returned when a series is not found.
+var ErrNotFound = errors.New("series not found")
+
+// Series is an ordered collection of books.
+type Series struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Entries     []Entry   `json:"entries"`
+}
+
+// Entry is a book's place in a series. Position may be fractional (2.5) and
+// is nil when unknown; such entries sort last.
+type Entry struct {
+	Position *float64  `json:"position"`
+	Book     book.Book `json:"book"`
+}
+
+// Next is the first unread book following the highest position a user has
+// finished in a series.
+type Next struct {
+	SeriesID   string    `json:"series_id"`
+	SeriesName string    `json:"series_name"`
+	Position   *float64  `json:"position"`
+	Book       book.Book `json:"book"`
+}
+
+type Repository interface {
+	GetByID(ctx context.Context, id string) (Series, error)
+	ListEntries(ctx context.Context, seriesID string) ([]Entry, error)
+	NextForUser(ctx context.Context, userID string) ([]Next, error)
+}
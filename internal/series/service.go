@@ -0,0 +1,32 @@
+package series
+
+import (
+	"context"
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Get returns a series with its books in reading order.
+func (s *Service) Get(ctx context.Context, id string) (Series, error) {
+	ser, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return Series{}, err
+	}
+	ser.Entries, err = s.repo.ListEntries(ctx, id)
+	if err != nil {
+		return Series{}, err
+	}
+	return ser, nil
+}
+
+// NextForUser returns, for every series in which the user has finished a
+// book, the next book they have not finished yet.
+func (s *Service) NextForUser(ctx context.Context, userID string) ([]Next, error) {
+	return s.repo.NextForUser(ctx, userID)
+}
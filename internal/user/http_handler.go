@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 type HTTPHandler struct {
@@ -104,3 +107,110 @@ func (h *HTTPHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 		"role":     user.Role,
 	}, nil)
 }
+
+// ListUsers handles GET /admin/users
+// @Summary List users
+// @Description List all users, optionally filtered by role (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param role query string false "Filter by role" Enums(USER, ADMIN)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Items per page" default(20)
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 403 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /admin/users [get]
+func (h *HTTPHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	role := strings.ToUpper(query.Get("role"))
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	users, total, err := h.service.List(r.Context(), role, pageSize, (page-1)*pageSize)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRole) {
+			httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid role", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, users, map[string]any{
+		"page":        page,
+		"page_size":   pageSize,
+		"total":       total,
+		"total_pages": (total + pageSize - 1) / pageSize,
+	})
+}
+
+type updateRoleReq struct {
+	Role string `json:"role" validate:"required,oneof=USER ADMIN"`
+}
+
+// UpdateRole handles PATCH /admin/users/{id}/role
+// @Summary Change user role
+// @Description Promote or demote a user (admin only). Admins cannot change their own role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "User ID"
+// @Param request body updateRoleReq true "New role"
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 401 {object} httpx.ErrorResponse
+// @Failure 403 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /admin/users/{id}/role [patch]
+func (h *HTTPHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid user ID", nil)
+		return
+	}
+
+	var req updateRoleReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid request body", nil)
+		return
+	}
+	req.Role = strings.ToUpper(strings.TrimSpace(req.Role))
+
+	if validationErrors := httpx.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.JSONError(w, r, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input", validationErrors)
+		return
+	}
+
+	u, err := h.service.ChangeRole(r.Context(), httpx.UserIDFrom(r), userID, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSelfRoleChange):
+			httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Cannot change your own role", nil)
+		case errors.Is(err, ErrNotFound):
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "User not found", nil)
+		default:
+			httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		}
+		return
+	}
+
+	httpx.JSONSuccess(w, r, map[string]any{
+		"id":       u.ID,
+		"email":    u.Email,
+		"username": u.Username,
+		"role":     u.Role,
+	}, nil)
+}
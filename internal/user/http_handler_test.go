@@ -0,0 +1,120 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bookapi/internal/httpx"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHandler_ListUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	handler := NewHTTPHandler(NewService(mockRepo))
+
+	t.Run("empty", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), "", 20, 0).Return([]User{}, 0, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+		handler.ListUsers(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"data":[]`)
+	})
+
+	t.Run("filters by role and pages", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), RoleAdmin, 10, 10).Return([]User{{ID: "u1", Role: RoleAdmin}}, 11, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/admin/users?role=admin&page=2&page_size=10", nil)
+		handler.ListUsers(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total_pages":2`)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/admin/users?role=owner", nil)
+		handler.ListUsers(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any(), "", 20, 0).Return(nil, 0, context.DeadlineExceeded)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+		handler.ListUsers(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestHTTPHandler_UpdateRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	handler := NewHTTPHandler(NewService(mockRepo))
+
+	const (
+		adminID  = "0b8a6f1e-6c1d-4f0e-9a51-0e2f7f4c9d10"
+		targetID = "6f1c1d5e-0d7a-4c1e-9d4f-2a3b4c5d6e7f"
+	)
+	request := func(id, body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPatch, "/admin/users/"+id+"/role", strings.NewReader(body))
+		r.SetPathValue("id", id)
+		return r.WithContext(httpx.ContextWithUser(r.Context(), adminID, RoleAdmin))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateRole(gomock.Any(), targetID, RoleAdmin).Return(nil)
+		mockRepo.EXPECT().GetByID(gomock.Any(), targetID).Return(User{ID: targetID, Role: RoleAdmin}, nil)
+
+		w := httptest.NewRecorder()
+		handler.UpdateRole(w, request(targetID, `{"role":"admin"}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"role":"ADMIN"`)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.UpdateRole(w, request("not-a-uuid", `{"role":"ADMIN"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.UpdateRole(w, request(targetID, `{"role":"OWNER"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"VALIDATION_ERROR"`)
+	})
+
+	t.Run("own role", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.UpdateRole(w, request(adminID, `{"role":"USER"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateRole(gomock.Any(), targetID, RoleUser).Return(ErrNotFound)
+
+		w := httptest.NewRecorder()
+		handler.UpdateRole(w, request(targetID, `{"role":"USER"}`))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
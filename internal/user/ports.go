@@ -10,4 +10,6 @@ type Repository interface {
 	GetByID(ctx context.Context, id string) (User, error)
 	UpdateProfile(ctx context.Context, userID string, updates map[string]interface{}) error
 	GetPublicProfile(ctx context.Context, userID string) (User, error)
+	List(ctx context.Context, role string, limit, offset int) ([]User, int, error)
+	UpdateRole(ctx context.Context, userID string, role string) error
 }
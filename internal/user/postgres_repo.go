@@ -57,9 +57,7 @@ func (r *PostgresRepo) GetByEmail(ctx context.Context, email string) (User, erro
 		}
 		return User{}, err
 	}
-	if len(readingPrefs) > 0 {
-		user.ReadingPreferences = readingPrefs
-	}
+	user.ReadingPreferences = decodePreferences(readingPrefs)
 	return user, nil
 }
 
@@ -78,9 +76,7 @@ func (r *PostgresRepo) GetByID(ctx context.Context, id string) (User, error) {
 		&readingPrefs, &user.LastLoginAt,
 		&user.CreatedAt, &user.UpdatedAt,
 	)
-	if len(readingPrefs) > 0 {
-		user.ReadingPreferences = readingPrefs
-	}
+	user.ReadingPreferences = decodePreferences(readingPrefs)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return User{}, ErrNotFound
@@ -103,9 +99,7 @@ func (r *PostgresRepo) GetPublicProfile(ctx context.Context, id string) (User, e
 		&user.ID, &user.Username, &user.Bio, &user.Location, &user.Website,
 		&user.IsPublic, &readingPrefs, &user.CreatedAt,
 	)
-	if len(readingPrefs) > 0 {
-		user.ReadingPreferences = readingPrefs
-	}
+	user.ReadingPreferences = decodePreferences(readingPrefs)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return User{}, ErrNotFound
@@ -142,3 +136,55 @@ func (r *PostgresRepo) UpdateProfile(ctx context.Context, userID string, updates
 	_, err := r.db.Exec(timeoutCtx, query, args...)
 	return err
 }
+
+func (r *PostgresRepo) List(ctx context.Context, role string, limit, offset int) ([]User, int, error) {
+	const countSQL = `
+	SELECT COUNT(*) FROM users WHERE ($1 = '' OR role = $1)
+	`
+	var total int
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	if err := r.db.QueryRow(timeoutCtx, countSQL, role).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	const dataSQL = `
+	SELECT id, email, username, role, is_public, last_login_at, created_at, updated_at
+	FROM users
+	WHERE ($1 = '' OR role = $1)
+	ORDER BY created_at ASC, id ASC
+	LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(timeoutCtx, dataSQL, role, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(
+			&u.ID, &u.Email, &u.Username, &u.Role, &u.IsPublic,
+			&u.LastLoginAt, &u.CreatedAt, &u.UpdatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, u)
+	}
+	return users, total, rows.Err()
+}
+
+func (r *PostgresRepo) UpdateRole(ctx context.Context, userID string, role string) error {
+	const query = `UPDATE users SET role = $1, updated_at = now() WHERE id = $2`
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tag, err := r.db.Exec(timeoutCtx, query, role, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package user
+
+import "encoding/json"
+
+// PreferencesVersion is the version of the reading preferences schema this
+// code reads and writes. Older stored values are rewritten by migrations.
+const PreferencesVersion = 1
+
+// Formats a reader can prefer.
+const (
+	FormatHardcover = "hardcover"
+	FormatPaperback = "paperback"
+	FormatEbook     = "ebook"
+	FormatAudiobook = "audiobook"
+)
+
+// ReadingPreferences is the schema of users.reading_preferences.
+type ReadingPreferences struct {
+	Version int `json:"version"`
+	// FavoriteGenres are genre slugs from the genre taxonomy.
+	FavoriteGenres []string `json:"favorite_genres"`
+	// Languages are ISO 639-1 codes, most preferred first.
+	Languages        []string `json:"languages"`
+	PreferredFormats []string `json:"preferred_formats"`
+	// AvoidContent are lower-case tags of content the reader does not want
+	// to be recommended, such as "graphic violence".
+	AvoidContent []string `json:"avoid_content"`
+	// YearlyGoal is the number of books the reader aims to finish this year.
+	YearlyGoal *int `json:"yearly_goal"`
+}
+
+// ValidateFormat reports whether format is one of the known formats.
+func ValidateFormat(format string) bool {
+	switch format {
+	case FormatHardcover, FormatPaperback, FormatEbook, FormatAudiobook:
+		return true
+	default:
+		return false
+	}
+}
+
+// decodePreferences reads a stored reading_preferences value. NULL and
+// values that do not fit the schema yield nil rather than failing the
+// whole user lookup.
+func decodePreferences(raw []byte) *ReadingPreferences {
+	if len(raw) == 0 {
+		return nil
+	}
+	var p ReadingPreferences
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil
+	}
+	for _, list := range []*[]string{&p.FavoriteGenres, &p.Languages, &p.PreferredFormats, &p.AvoidContent} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	return &p
+}
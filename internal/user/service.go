@@ -22,7 +22,7 @@ func (s *Service) Register(ctx context.Context, email, username, hashedPassword
 		Email:    email,
 		Username: username,
 		Password: hashedPassword,
-		Role:     "USER",
+		Role:     RoleUser,
 	}
 
 	if err := s.repo.Create(ctx, newUser); err != nil {
@@ -47,3 +47,28 @@ func (s *Service) GetPublicProfile(ctx context.Context, id string) (User, error)
 func (s *Service) UpdateProfile(ctx context.Context, userID string, updates map[string]any) error {
 	return s.repo.UpdateProfile(ctx, userID, updates)
 }
+
+func (s *Service) List(ctx context.Context, role string, limit, offset int) ([]User, int, error) {
+	if role != "" {
+		if err := ValidateRole(role); err != nil {
+			return nil, 0, err
+		}
+	}
+	return s.repo.List(ctx, role, limit, offset)
+}
+
+// ChangeRole promotes or demotes a user. Admins cannot change their own role,
+// which prevents the last admin from locking everyone out. The new role takes
+// effect once the user's current access token expires.
+func (s *Service) ChangeRole(ctx context.Context, actorID, userID, role string) (User, error) {
+	if err := ValidateRole(role); err != nil {
+		return User{}, err
+	}
+	if actorID == userID {
+		return User{}, ErrSelfRoleChange
+	}
+	if err := s.repo.UpdateRole(ctx, userID, role); err != nil {
+		return User{}, err
+	}
+	return s.repo.GetByID(ctx, userID)
+}
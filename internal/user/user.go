@@ -6,22 +6,39 @@ import (
 )
 
 var (
-	ErrNotFound      = errors.New("user not found")
-	ErrAlreadyExists = errors.New("user already exists")
+	ErrNotFound       = errors.New("user not found")
+	ErrAlreadyExists  = errors.New("user already exists")
+	ErrInvalidRole    = errors.New("invalid role")
+	ErrSelfRoleChange = errors.New("cannot change your own role")
 )
 
+const (
+	RoleUser  = "USER"
+	RoleAdmin = "ADMIN"
+)
+
+// ValidateRole reports whether role is one of the known roles.
+func ValidateRole(role string) error {
+	switch role {
+	case RoleUser, RoleAdmin:
+		return nil
+	default:
+		return ErrInvalidRole
+	}
+}
+
 type User struct {
-	ID                 string     `json:"id"`
-	Username           string     `json:"username"`
-	Role               string     `json:"role"` // USER, ADMIN
-	Email              string     `json:"email"`
-	Password           string     `json:"-"`
-	Bio                *string    `json:"bio,omitempty"`
-	Location           *string    `json:"location,omitempty"`
-	Website            *string    `json:"website,omitempty"`
-	IsPublic           bool       `json:"is_public"`
-	ReadingPreferences []byte     `json:"reading_preferences,omitempty"`
-	LastLoginAt        *time.Time `json:"last_login_at,omitempty"`
-	CreatedAt          time.Time  `json:"created_at"`
-	UpdatedAt          time.Time  `json:"updated_at"`
+	ID                 string              `json:"id"`
+	Username           string              `json:"username"`
+	Role               string              `json:"role"` // USER, ADMIN
+	Email              string              `json:"email"`
+	Password           string              `json:"-"`
+	Bio                *string             `json:"bio,omitempty"`
+	Location           *string             `json:"location,omitempty"`
+	Website            *string             `json:"website,omitempty"`
+	IsPublic           bool                `json:"is_public"`
+	ReadingPreferences *ReadingPreferences `json:"reading_preferences,omitempty"`
+	LastLoginAt        *time.Time          `json:"last_login_at,omitempty"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
 }
@@ -0,0 +1,49 @@
+package work
+
+import (
+	"bookapi/internal/httpx"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type HTTPHandler struct {
+	service *Service
+}
+
+func NewHTTPHandler(service *Service) *HTTPHandler {
+	return &HTTPHandler{service: service}
+}
+
+// GetByID handles GET /works/{id}
+// @Summary Get work
+// @Description Retrieve a work with all of its editions, plus ratings and reading-list counts rolled up across editions
+// @Tags works
+// @Accept json
+// @Produce json
+// @Param id path string true "Work ID"
+// @Success 200 {object} httpx.SuccessResponse
+// @Failure 400 {object} httpx.ErrorResponse
+// @Failure 404 {object} httpx.ErrorResponse
+// @Failure 500 {object} httpx.ErrorResponse
+// @Router /works/{id} [get]
+func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.JSONError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Invalid work ID", nil)
+		return
+	}
+
+	work, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			httpx.JSONError(w, r, http.StatusNotFound, "NOT_FOUND", "Work not found", nil)
+			return
+		}
+		httpx.JSONError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", nil)
+		return
+	}
+
+	httpx.JSONSuccess(w, r, work, nil)
+}
@@ -0,0 +1,79 @@
+package work
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bookapi/internal/book"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHandler_GetByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo)
+	handler := NewHTTPHandler(service)
+
+	const id = "8a4f3c8e-2f7a-4a55-9d0c-0f2a3b1c4d5e"
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), id).Return(Work{
+			ID:     id,
+			Title:  "Dune",
+			Rating: RatingSummary{Average: 4.5, Count: 2},
+		}, nil)
+		mockRepo.EXPECT().ListEditions(gomock.Any(), id).Return([]book.Book{
+			{ISBN: "9780441172719", Title: "Dune"},
+			{ISBN: "9780340960196", Title: "Dune"},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/works/"+id, nil)
+		r.SetPathValue("id", id)
+
+		handler.GetByID(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"ratings_count":2`)
+		assert.Contains(t, w.Body.String(), `"isbn":"9780340960196"`)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), id).Return(Work{}, ErrNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/works/"+id, nil)
+		r.SetPathValue("id", id)
+
+		handler.GetByID(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/works/abc", nil)
+		r.SetPathValue("id", "abc")
+
+		handler.GetByID(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(gomock.Any(), id).Return(Work{}, errors.New("db error"))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/works/"+id, nil)
+		r.SetPathValue("id", id)
+
+		handler.GetByID(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
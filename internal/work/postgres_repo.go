@@ -0,0 +1,103 @@
+package work
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bookapi/internal/book"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+}
+
+func NewPostgresRepo(db *pgxpool.Pool, timeout time.Duration) *PostgresRepo {
+	return &PostgresRepo{db: db, timeout: timeout}
+}
+
+func (r *PostgresRepo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+func (r *PostgresRepo) GetByID(ctx context.Context, id string) (Work, error) {
+	const query = `
+		SELECT w.id, w.ol_key, w.title, w.created_at, w.updated_at,
+		       COALESCE(r.avg_star, 0), COALESCE(r.cnt, 0),
+		       COALESCE(ub.wishlist, 0), COALESCE(ub.reading, 0), COALESCE(ub.finished, 0)
+		FROM works w
+		LEFT JOIN LATERAL (
+			SELECT SUM(b.rating_avg * b.rating_count) / NULLIF(SUM(b.rating_count), 0) AS avg_star,
+			       SUM(b.rating_count) AS cnt
+			FROM books b
+			WHERE b.work_id = w.id
+		) r ON true
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) FILTER (WHERE u.status = 'WISHLIST') AS wishlist,
+			       COUNT(*) FILTER (WHERE u.status = 'READING') AS reading,
+			       COUNT(*) FILTER (WHERE u.status = 'FINISHED') AS finished
+			FROM user_books u
+			JOIN books b ON b.id = u.book_id
+			WHERE b.work_id = w.id
+		) ub ON true
+		WHERE w.id = $1
+	`
+	var w Work
+	var wishlist, reading, finished int
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	err := r.db.QueryRow(timeoutCtx, query, id).Scan(
+		&w.ID, &w.Key, &w.Title, &w.CreatedAt, &w.UpdatedAt,
+		&w.Rating.Average, &w.Rating.Count,
+		&wishlist, &reading, &finished,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Work{}, ErrNotFound
+		}
+		return Work{}, err
+	}
+	w.ReadingList = map[string]int{
+		"WISHLIST": wishlist,
+		"READING":  reading,
+		"FINISHED": finished,
+	}
+	return w, nil
+}
+
+func (r *PostgresRepo) ListEditions(ctx context.Context, workID string) ([]book.Book, error) {
+	const query = `
+		SELECT id, isbn, title, subtitle, genre, publisher, description,
+		       published_date, publication_year, page_count, language, cover_url,
+		       work_id, rating_avg, rating_count, rating_histogram, created_at, updated_at
+		FROM books
+		WHERE work_id = $1
+		ORDER BY publication_year NULLS LAST, isbn
+	`
+	timeoutCtx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.Query(timeoutCtx, query, workID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	editions := []book.Book{}
+	for rows.Next() {
+		var b book.Book
+		if err := rows.Scan(
+			&b.ID, &b.ISBN, &b.Title, &b.Subtitle, &b.Genre, &b.Publisher, &b.Description,
+			&b.PublishedDate, &b.PublicationYear, &b.PageCount, &b.Language, &b.CoverURL,
+			&b.WorkID, &b.Rating.Average, &b.Rating.Count, &b.Rating.Histogram,
+			&b.CreatedAt, &b.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		editions = append(editions, b)
+	}
+	return editions, rows.Err()
+}
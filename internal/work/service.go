@@ -0,0 +1,26 @@
+package work
+
+import (
+	"context"
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Get returns a work with its editions and rolled-up rating and reading-list counts.
+func (s *Service) Get(ctx context.Context, id string) (Work, error) {
+	w, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return Work{}, err
+	}
+	w.Editions, err = s.repo.ListEditions(ctx, id)
+	if err != nil {
+		return Work{}, err
+	}
+	return w, nil
+}
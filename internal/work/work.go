@@ -0,0 +1,37 @@
+package work
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bookapi/internal/book"
+)
+
+// ErrNotFound is returned when a work is not found.
+var ErrNotFound = errors.New("work not found")
+
+// Work groups the editions (ISBNs) of the same book.
+type Work struct {
+	ID        string    `json:"id"`
+	Key       *string   `json:"key,omitempty"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Rating and ReadingList are rolled up across all editions.
+	Rating      RatingSummary  `json:"rating"`
+	ReadingList map[string]int `json:"reading_list"`
+	Editions    []book.Book    `json:"editions"`
+}
+
+// RatingSummary is the average star rating over a set of ratings.
+type RatingSummary struct {
+	Average float64 `json:"average_rating"`
+	Count   int     `json:"ratings_count"`
+}
+
+type Repository interface {
+	GetByID(ctx context.Context, id string) (Work, error)
+	ListEditions(ctx context.Context, workID string) ([]book.Book, error)
+}